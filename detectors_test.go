@@ -0,0 +1,451 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/skrashevich/aicommit/internal/fixture"
+)
+
+func TestDetectors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		args   []string
+	}{
+		{
+			name: "i18n",
+			script: `
+write locales/en.json
+{"greeting": "Hello"}
+.
+commit initial commit
+write locales/de.json
+{"greeting": "Hallo"}
+.
+write locales/fr.json
+{"greeting": "Bonjour"}
+.
+stage
+`,
+		},
+		{
+			name: "config-only",
+			script: `
+write config/app.yaml
+server:
+  port: 8080
+  timeout: 30s
+.
+commit initial commit
+write config/app.yaml
+server:
+  port: 9090
+  timeout: 30s
+  read_timeout: 5s
+.
+stage
+`,
+		},
+		{
+			name: "scope-map",
+			script: `
+write .aicommit.toml
+[scope_map]
+"services/payments/**" = "billing"
+.
+write services/payments/charge.go
+package payments
+
+func Charge() {}
+.
+commit initial commit
+write services/payments/charge.go
+package payments
+
+func Charge() {}
+
+func Refund() {}
+.
+stage
+`,
+		},
+		{
+			name: "type-map",
+			script: `
+write .aicommit.toml
+[type_map]
+"tools/**" = "build"
+.
+write tools/gen.go
+package tools
+.
+commit initial commit
+append tools/gen.go
+
+func Generate() {}
+.
+stage
+`,
+		},
+		{
+			name: "line-weighted",
+			script: `
+write README.md
+# demo
+.
+write app/server.go
+package app
+.
+commit initial commit
+append README.md
+Run the server with make run.
+.
+write app/handler.go
+package app
+
+import "net/http"
+
+func Health(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func Ready(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func Version(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("dev"))
+}
+.
+stage
+`,
+		},
+		{
+			name: "mixed",
+			script: `
+write docs/guide.md
+# guide
+.
+write api/users.go
+package api
+.
+commit initial commit
+append docs/guide.md
+
+## Install
+
+Install the CLI with go install and make sure the
+binary directory is on your PATH.
+
+## Configure
+
+Create a config file in your home directory and set
+the API endpoint, the token and the default region.
+.
+append api/users.go
+
+type User struct {
+	ID    int
+	Name  string
+	Email string
+}
+
+func List() []User {
+	return nil
+}
+
+func Find(id int) (User, bool) {
+	return User{}, false
+}
+.
+stage
+`,
+		},
+		{
+			name: "signature",
+			script: `
+write store/store.go
+package store
+
+func Get(key string) string {
+	return key
+}
+.
+commit initial commit
+write store/store.go
+package store
+
+func Get(key string, fallback string) string {
+	if key == "" {
+		return fallback
+	}
+	return key
+}
+.
+stage
+`,
+		},
+		{
+			name: "contract",
+			script: `
+write api/openapi.yaml
+openapi: 3.0.0
+paths:
+  /users:
+    get:
+      summary: list users
+  /users/{id}:
+    delete:
+      summary: delete a user
+.
+commit initial commit
+write api/openapi.yaml
+openapi: 3.0.0
+paths:
+  /users:
+    get:
+      summary: list users
+.
+stage
+`,
+		},
+		{
+			name: "contract-operation",
+			script: `
+write api/openapi.yaml
+openapi: 3.0.0
+paths:
+  /users:
+    get:
+      summary: list users
+    post:
+      summary: create a user
+      operationId: createUser
+.
+commit initial commit
+write api/openapi.yaml
+openapi: 3.0.0
+paths:
+  /users:
+    get:
+      summary: list users
+.
+stage
+`,
+		},
+		{
+			name: "workspace",
+			script: `
+write package.json
+{"private": true, "workspaces": ["packages/*"]}
+.
+write packages/web/package.json
+{"name": "@acme/web"}
+.
+write packages/web/src/index.js
+export const name = "web";
+.
+commit initial commit
+append packages/web/src/index.js
+export const port = 3000;
+.
+stage
+`,
+		},
+		{
+			name: "todo",
+			script: `
+write parse/parse.go
+package parse
+
+func Parse(s string) []string {
+	// TODO: handle empty input
+	return []string{s}
+}
+.
+commit initial commit
+write parse/parse.go
+package parse
+
+func Parse(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
+.
+stage
+`,
+		},
+		{
+			name: "ci",
+			script: `
+write README.md
+# demo
+.
+commit initial commit
+write .buildkite/pipeline.yml
+steps:
+  - command: make test
+.
+stage
+`,
+		},
+		{
+			name: "infra",
+			script: `
+write main.go
+package main
+.
+commit initial commit
+write deploy/k8s/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+.
+write Dockerfile
+FROM golang:1.25
+.
+stage
+`,
+		},
+		{
+			name: "comments",
+			script: `
+write calc/calc.go
+package calc
+
+func Add(a, b int) int {
+	return a + b
+}
+.
+commit initial commit
+write calc/calc.go
+package calc
+
+// Add returns the sum of a and b.
+func Add(a, b int) int {
+	return a + b
+}
+.
+stage
+`,
+		},
+		{
+			name: "benchmark",
+			script: `
+write sum/sum.go
+package sum
+
+func Sum(xs []int) int {
+	n := 0
+	for _, x := range xs {
+		n += x
+	}
+	return n
+}
+.
+commit initial commit
+write sum/sum_test.go
+package sum
+
+import "testing"
+
+func BenchmarkSum(b *testing.B) {
+	xs := make([]int, 1024)
+	for b.Loop() {
+		Sum(xs)
+	}
+}
+.
+stage
+`,
+		},
+		{
+			name: "assets",
+			script: `
+write README.md
+# demo
+.
+commit initial commit
+write assets/logo.png
+PNG placeholder
+.
+write assets/banner.jpg
+JPEG placeholder
+.
+stage
+`,
+		},
+		{
+			name: "rename",
+			script: `
+write internal/util/strings.go
+package util
+.
+write internal/util/numbers.go
+package util
+.
+commit initial commit
+git mv internal/util internal/helpers
+stage
+`,
+		},
+		{
+			name: "rules",
+			script: `
+write .aicommit-rules
+diff (?i)cve-[0-9]+ => type=fix scope=security reason="CVE reference"
+.
+write go.mod
+module example.com/demo
+.
+commit initial commit
+write go.mod
+module example.com/demo
+
+// CVE-2024-1234: pin the patched release
+require golang.org/x/net v0.33.0
+.
+stage
+`,
+			args: []string{"-rules", ".aicommit-rules"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := fixture.New(t).Apply(tt.script)
+			res := r.Run(tt.args...)
+			if res.Code != 0 {
+				t.Fatalf("aicommit exited with %d\n%s", res.Code, res.Stderr)
+			}
+			fixture.Golden(t, tt.name, describe(res))
+		})
+	}
+}
+
+func describe(res fixture.Result) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", res.Message)
+	fmt.Fprintf(&b, "type: %s (%.2f)\n", res.Explain.Type, res.Explain.TypeConfidence)
+	scope := res.Explain.Scope
+	if scope == "" {
+		scope = "-"
+	}
+	fmt.Fprintf(&b, "scope: %s (%.2f)\n", scope, res.Explain.ScopeConfidence)
+	if res.Explain.Breaking {
+		fmt.Fprintf(&b, "breaking: %s (%.2f)\n", res.Explain.BreakingNote, res.Explain.BreakingConfidence)
+	}
+	for _, reason := range res.Explain.Reasons {
+		fmt.Fprintf(&b, "reason: %s\n", reason)
+	}
+	for _, line := range strings.Split(res.Stderr, "\n") {
+		if strings.HasPrefix(line, "warning:") {
+			fmt.Fprintln(&b, line)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,245 @@
+package fixture
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type Repo struct {
+	t   testing.TB
+	Dir string
+	env []string
+}
+
+type Explain struct {
+	Mode               string   `json:"mode"`
+	Files              int      `json:"files"`
+	Type               string   `json:"type"`
+	TypeConfidence     float64  `json:"type_confidence"`
+	Reasons            []string `json:"reasons"`
+	Scope              string   `json:"scope"`
+	ScopeConfidence    float64  `json:"scope_confidence"`
+	Breaking           bool     `json:"breaking"`
+	BreakingNote       string   `json:"breaking_note"`
+	BreakingConfidence float64  `json:"breaking_confidence"`
+	LLM                bool     `json:"llm"`
+}
+
+type Result struct {
+	Message string
+	Explain Explain
+	Stderr  string
+	Code    int
+}
+
+var (
+	buildOnce sync.Once
+	binary    string
+	buildErr  error
+)
+
+func Binary(t testing.TB) string {
+	t.Helper()
+	if bin := os.Getenv("AICOMMIT_BIN"); bin != "" {
+		return bin
+	}
+	buildOnce.Do(func() {
+		var root []byte
+		root, buildErr = exec.Command("go", "list", "-m", "-f", "{{.Dir}}").Output()
+		if buildErr != nil {
+			return
+		}
+		dir, err := os.MkdirTemp("", "aicommit-fixture-")
+		if err != nil {
+			buildErr = err
+			return
+		}
+		binary = filepath.Join(dir, "aicommit")
+		cmd := exec.Command("go", "build", "-o", binary, ".")
+		cmd.Dir = strings.TrimSpace(string(root))
+		if out, err := cmd.CombinedOutput(); err != nil {
+			buildErr = errors.New(strings.TrimSpace(string(out)))
+		}
+	})
+	if buildErr != nil {
+		t.Fatalf("fixture: build aicommit: %v", buildErr)
+	}
+	return binary
+}
+
+func New(t testing.TB) *Repo {
+	t.Helper()
+	home := t.TempDir()
+	r := &Repo{t: t, Dir: t.TempDir()}
+	gitconfig := filepath.Join(home, ".gitconfig")
+	if err := os.WriteFile(gitconfig, []byte("[user]\n\tname = Fixture\n\temail = fixture@example.com\n[init]\n\tdefaultBranch = main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if strings.HasPrefix(name, "AICOMMIT_") || strings.HasPrefix(name, "AI_COMMIT_") || strings.HasPrefix(name, "GIT_") || name == "HOME" || strings.HasPrefix(name, "XDG_") {
+			continue
+		}
+		r.env = append(r.env, kv)
+	}
+	r.env = append(r.env,
+		"HOME="+home,
+		"XDG_CONFIG_HOME="+filepath.Join(home, ".config"),
+		"XDG_STATE_HOME="+filepath.Join(home, ".local", "state"),
+		"GIT_CONFIG_GLOBAL="+gitconfig,
+		"GIT_CONFIG_NOSYSTEM=1",
+		"AICOMMIT_LANG=en",
+	)
+	r.Git("init", "-q")
+	return r
+}
+
+func (r *Repo) Setenv(name, value string) *Repo {
+	r.env = append(r.env, name+"="+value)
+	return r
+}
+
+func (r *Repo) Git(args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.Dir
+	cmd.Env = r.env
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("fixture: git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func (r *Repo) Write(path, content string) *Repo {
+	r.t.Helper()
+	full := filepath.Join(r.Dir, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+		r.t.Fatal(err)
+	}
+	return r
+}
+
+func (r *Repo) Remove(path string) *Repo {
+	r.t.Helper()
+	if err := os.RemoveAll(filepath.Join(r.Dir, filepath.FromSlash(path))); err != nil {
+		r.t.Fatal(err)
+	}
+	return r
+}
+
+func (r *Repo) Rename(from, to string) *Repo {
+	r.t.Helper()
+	r.Git("mv", from, to)
+	return r
+}
+
+func (r *Repo) Stage(paths ...string) *Repo {
+	r.t.Helper()
+	r.Git(append([]string{"add", "-A", "--"}, paths...)...)
+	return r
+}
+
+func (r *Repo) Commit(message string) *Repo {
+	r.t.Helper()
+	r.Stage()
+	r.Git("commit", "-q", "--allow-empty", "-m", message)
+	return r
+}
+
+func (r *Repo) Apply(script string) *Repo {
+	r.t.Helper()
+	scanner := bufio.NewScanner(strings.NewReader(script))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cmd, arg, _ := strings.Cut(line, " ")
+		arg = strings.TrimSpace(arg)
+		switch cmd {
+		case "write", "append":
+			var body []string
+			for scanner.Scan() && scanner.Text() != "." {
+				body = append(body, scanner.Text())
+			}
+			content := strings.Join(body, "\n") + "\n"
+			if cmd == "append" {
+				old, _ := os.ReadFile(filepath.Join(r.Dir, filepath.FromSlash(arg)))
+				content = string(old) + content
+			}
+			r.Write(arg, content)
+		case "rm":
+			r.Remove(arg)
+		case "mv":
+			from, to, _ := strings.Cut(arg, " ")
+			r.Rename(from, strings.TrimSpace(to))
+		case "stage":
+			r.Stage(strings.Fields(arg)...)
+		case "commit":
+			r.Commit(arg)
+		case "git":
+			r.Git(strings.Fields(arg)...)
+		default:
+			r.t.Fatalf("fixture: unknown script command %q", line)
+		}
+	}
+	return r
+}
+
+func (r *Repo) Run(args ...string) Result {
+	r.t.Helper()
+	cmd := exec.Command(Binary(r.t), append(args, "-explain", "-explain-format", "json")...)
+	cmd.Dir = r.Dir
+	cmd.Env = r.env
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	res := Result{Message: strings.TrimRight(stdout.String(), "\n"), Stderr: stderr.String()}
+	var exit *exec.ExitError
+	switch {
+	case errors.As(err, &exit):
+		res.Code = exit.ExitCode()
+	case err != nil:
+		r.t.Fatalf("fixture: run aicommit: %v", err)
+	}
+	if i := strings.Index(res.Stderr, "{\n"); i != -1 && (i == 0 || res.Stderr[i-1] == '\n') {
+		if err := json.NewDecoder(strings.NewReader(res.Stderr[i:])).Decode(&res.Explain); err != nil {
+			r.t.Fatalf("fixture: explain output: %v", err)
+		}
+		res.Stderr = res.Stderr[:i]
+	}
+	return res
+}
+
+func Golden(t testing.TB, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if os.Getenv("AICOMMIT_UPDATE_GOLDEN") != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("fixture: %v (run with AICOMMIT_UPDATE_GOLDEN=1 to create it)", err)
+	}
+	if strings.TrimRight(string(want), "\n") != got {
+		t.Errorf("fixture: %s differs from the golden file\n--- want\n%s\n+++ got\n%s", path, want, got)
+	}
+}
@@ -0,0 +1,171 @@
+package aicommit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/skrashevich/aicommit/pkg/llm"
+)
+
+var (
+	version  = "dev"
+	commit   = ""
+	date     = ""
+	features = ""
+)
+
+type Build struct {
+	Version  string
+	Commit   string
+	Date     string
+	Features string
+}
+
+func (b Build) apply() {
+	if b.Version != "" {
+		version = b.Version
+	}
+	commit, date, features = b.Commit, b.Date, b.Features
+}
+
+type buildDetails struct {
+	Module     string
+	Version    string
+	Commit     string
+	CommitTime string
+	Date       string
+	Modified   bool
+	Tags       string
+	Features   []string
+	Go         string
+}
+
+func readBuildDetails() buildDetails {
+	d := buildDetails{Version: version, Commit: commit, Date: date, Go: runtime.Version()}
+	for _, f := range strings.Split(features, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			d.Features = append(d.Features, f)
+		}
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return d
+	}
+	d.Module = info.Main.Path
+	if d.Version == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		d.Version = info.Main.Version
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if d.Commit == "" {
+				d.Commit = s.Value
+			}
+		case "vcs.time":
+			d.CommitTime = s.Value
+		case "vcs.modified":
+			d.Modified = s.Value == "true"
+		case "-tags":
+			d.Tags = s.Value
+		case "CGO_ENABLED":
+			if s.Value == "1" {
+				d.Features = append(d.Features, "cgo")
+			}
+		}
+	}
+	return d
+}
+
+func buildSummary() string {
+	d := readBuildDetails()
+	parts := []string{versionString()}
+	if d.Date != "" {
+		parts = append(parts, "built "+d.Date)
+	} else if d.CommitTime != "" {
+		parts = append(parts, "committed "+d.CommitTime)
+	}
+	parts = append(parts, d.Go+" "+runtime.GOOS+"/"+runtime.GOARCH)
+	if len(d.Features) > 0 {
+		parts = append(parts, "features "+strings.Join(d.Features, ","))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func versionString() string {
+	d := readBuildDetails()
+	out := "aicommit " + d.Version
+	if d.Commit != "" {
+		rev := d.Commit
+		if len(rev) > 12 {
+			rev = rev[:12]
+		}
+		if d.Modified {
+			rev += "-dirty"
+		}
+		out += " (" + rev + ")"
+	}
+	return out
+}
+
+func printAbout(ctx context.Context, w io.Writer, cfg *config) {
+	d := readBuildDetails()
+	fmt.Fprintln(w, versionString())
+	if d.Module != "" {
+		fmt.Fprintf(w, "module:     %s\n", d.Module)
+	}
+	if d.Commit != "" {
+		fmt.Fprintf(w, "commit:     %s\n", d.Commit)
+	}
+	if d.CommitTime != "" {
+		fmt.Fprintf(w, "committed:  %s\n", d.CommitTime)
+	}
+	if d.Date != "" {
+		fmt.Fprintf(w, "built:      %s\n", d.Date)
+	}
+	fmt.Fprintf(w, "go:         %s %s/%s\n", d.Go, runtime.GOOS, runtime.GOARCH)
+	tags := d.Tags
+	if tags == "" {
+		tags = "none"
+	}
+	fmt.Fprintf(w, "build tags: %s\n", tags)
+	feats := strings.Join(d.Features, ", ")
+	if feats == "" {
+		feats = "none"
+	}
+	fmt.Fprintf(w, "features:   %s\n", feats)
+	fmt.Fprintf(w, "providers:  %s\n", strings.Join([]string{llm.ProviderOpenAI, llm.ProviderOpenRouter}, ", "))
+
+	model, _ := findSetting("llm.model")
+	provider, _ := findSetting("llm.provider")
+	providerValue := resolveSetting(provider, cfg).Value
+	if providerValue == "" {
+		providerValue = llm.ProviderOpenAI
+	}
+	fmt.Fprintf(w, "provider:   %s\n", providerValue)
+	fmt.Fprintf(w, "model:      %s\n", resolveSetting(model, cfg).Value)
+
+	fmt.Fprintln(w, "config:")
+	paths := []string{userConfigPath()}
+	if root, err := gitOutput(ctx, "rev-parse", "--show-toplevel"); err == nil && root != "" {
+		paths = append(paths, filepath.Join(root, repoConfigName))
+	}
+	for _, path := range paths {
+		state := "missing"
+		if _, err := os.Stat(path); err == nil {
+			state = "loaded"
+		}
+		fmt.Fprintf(w, "  %s (%s)\n", path, state)
+	}
+	for _, layer := range cfg.Layers {
+		if strings.HasPrefix(layer.Path, "git config") {
+			fmt.Fprintf(w, "  %s (loaded)\n", layer.Path)
+		}
+	}
+	fmt.Fprintf(w, "history:    %s\n", historyPath())
+}
@@ -0,0 +1,185 @@
+package aicommit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type actionEvent struct {
+	Before      string `json:"before"`
+	After       string `json:"after"`
+	PullRequest *struct {
+		Base struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+		} `json:"base"`
+		Head struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+		} `json:"head"`
+	} `json:"pull_request"`
+}
+
+type actionResult struct {
+	Range    string
+	Commits  int
+	Message  string
+	Type     string
+	Scope    string
+	Breaking bool
+	Bump     string
+	Reason   string
+}
+
+func runAction(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("action", flag.ContinueOnError)
+	rng := fs.String("range", "", "commit range to describe (default: taken from the event payload)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr(ctx), "Usage: aicommit action [-range A..B]")
+		fmt.Fprintln(stderr(ctx), "Reads GITHUB_EVENT_PATH and writes outputs to GITHUB_OUTPUT and GITHUB_STEP_SUMMARY.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := ensureGit(); err != nil {
+		return err
+	}
+
+	branch := os.Getenv("GITHUB_HEAD_REF")
+	if branch == "" {
+		branch = os.Getenv("GITHUB_REF_NAME")
+	}
+	if *rng == "" {
+		r, head, err := actionRange(ctx, os.Getenv("GITHUB_EVENT_NAME"), os.Getenv("GITHUB_EVENT_PATH"))
+		if err != nil {
+			return err
+		}
+		*rng = r
+		if head != "" {
+			branch = head
+		}
+	}
+	res, err := describeActionRange(ctx, *rng, branch)
+	if err != nil {
+		return err
+	}
+
+	outputs := [][2]string{
+		{"message", res.Message},
+		{"type", res.Type},
+		{"scope", res.Scope},
+		{"breaking", strconv.FormatBool(res.Breaking)},
+		{"semver", res.Bump},
+		{"commits", strconv.Itoa(res.Commits)},
+		{"range", res.Range},
+	}
+	if path := os.Getenv("GITHUB_OUTPUT"); path != "" {
+		if err := appendFile(path, formatActionOutputs(outputs)); err != nil {
+			return fmt.Errorf("write GITHUB_OUTPUT: %w", err)
+		}
+	} else {
+		fmt.Fprint(out, formatActionOutputs(outputs))
+	}
+	if path := os.Getenv("GITHUB_STEP_SUMMARY"); path != "" {
+		if err := appendFile(path, actionSummary(res)); err != nil {
+			return fmt.Errorf("write GITHUB_STEP_SUMMARY: %w", err)
+		}
+	}
+	fmt.Fprintf(stderr(ctx), "%s: %s (%s)\n", res.Bump, res.Reason, res.Range)
+	return nil
+}
+
+func actionRange(ctx context.Context, event, path string) (string, string, error) {
+	if path == "" {
+		return "", "", errors.New("GITHUB_EVENT_PATH is not set (pass -range outside GitHub Actions)")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	var ev actionEvent
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return "", "", fmt.Errorf("parse event payload: %w", err)
+	}
+	switch {
+	case ev.PullRequest != nil:
+		return ev.PullRequest.Base.SHA + ".." + ev.PullRequest.Head.SHA, ev.PullRequest.Head.Ref, nil
+	case ev.After != "":
+		if strings.Trim(ev.Before, "0") == "" {
+			_, base := baseBranch(ctx, "origin", "")
+			return base + ".." + ev.After, "", nil
+		}
+		return ev.Before + ".." + ev.After, "", nil
+	}
+	return "", "", fmt.Errorf("unsupported event %q (use push or pull_request, or pass -range)", event)
+}
+
+func describeActionRange(ctx context.Context, rng, branch string) (actionResult, error) {
+	res := actionResult{Range: rng}
+	commits, err := logCommits(ctx, "--no-merges", rng)
+	if err != nil {
+		return res, fmt.Errorf("%s: %w (check out with fetch-depth: 0)", rng, err)
+	}
+	if len(commits) == 0 {
+		return res, fmt.Errorf("no commits in %s", rng)
+	}
+	res.Commits = len(commits)
+	if branch == "" {
+		branch = currentBranch(ctx)
+	}
+	mr := describeMR(branch, "", commits)
+	res.Message = strings.TrimSpace(mr.Title + "\n\n" + mr.Body)
+	title := parseCommitMessage(mr.Title)
+	res.Type, res.Scope = title.Type, title.Scope
+	res.Bump, res.Reason = classifyBump(ctx, commits, rng, "in range")
+	res.Breaking = res.Bump == "major"
+	return res, nil
+}
+
+func formatActionOutputs(outputs [][2]string) string {
+	var b strings.Builder
+	for _, kv := range outputs {
+		if !strings.Contains(kv[1], "\n") {
+			fmt.Fprintf(&b, "%s=%s\n", kv[0], kv[1])
+			continue
+		}
+		delim := fmt.Sprintf("AICOMMIT_%d", time.Now().UnixNano())
+		fmt.Fprintf(&b, "%s<<%s\n%s\n%s\n", kv[0], delim, kv[1], delim)
+	}
+	return b.String()
+}
+
+func actionSummary(res actionResult) string {
+	var b strings.Builder
+	b.WriteString("### aicommit\n\n")
+	b.WriteString("| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Range | `%s` |\n", res.Range)
+	fmt.Fprintf(&b, "| Commits | %d |\n", res.Commits)
+	if res.Type != "" {
+		fmt.Fprintf(&b, "| Type | %s |\n", res.Type)
+	}
+	fmt.Fprintf(&b, "| Semver | **%s** — %s |\n", res.Bump, res.Reason)
+	fmt.Fprintf(&b, "\n```\n%s\n```\n", res.Message)
+	return b.String()
+}
+
+func appendFile(path, content string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,888 @@
+package aicommit
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/skrashevich/aicommit/pkg/gitinfo"
+	"github.com/skrashevich/aicommit/pkg/llm"
+	"github.com/skrashevich/aicommit/pkg/render"
+)
+
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+func Main(args []string, build Build) int {
+	build.apply()
+	ctx := withSession(context.Background(), newSession(os.Stdin, os.Stdout, os.Stderr))
+	if err := dispatch(ctx, args); err != nil {
+		if errors.Is(err, errLLMCancelled) {
+			fmt.Fprintln(os.Stderr, "cancelled")
+			return 130
+		}
+		fmt.Fprintln(os.Stderr, "error:", err)
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			return exitErr.code
+		}
+		return 1
+	}
+	return 0
+}
+
+func parseFlags(ctx context.Context, cfg *config, args []string) (Options, error) {
+	var opts Options
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	d := layeredDefaults{cfg: cfg, s: sessionOf(ctx)}
+
+	formatDefault := d.str("format")
+	langDefault := d.str("lang")
+	bodyDefault := d.str("body")
+	maxItemsDefault := d.integer("max_items")
+	maxSubjectDefault := d.integer("max_subject")
+	typeDefault := d.str("type")
+	scopeDefault := d.str("scope")
+	refsDefault := d.str("refs")
+	closesDefault := d.str("closes")
+	scopeMapDefault := envOrDefault("AICOMMIT_SCOPE_MAP", "")
+	modeDefault := d.str("mode")
+	breakingDefault := d.boolean("breaking")
+	emojiDefault := d.boolean("emoji")
+	explainDefault := d.boolean("explain")
+	copyDefault := d.boolean("copy")
+	yesDefault := d.boolean("assume_yes")
+	typeMapDefault := envOrDefault("AICOMMIT_TYPE_MAP", "")
+	rulesDefault := d.str("rules_file")
+	llmDefault := d.boolean("llm.enabled")
+	llmProviderDefault := d.str("llm.provider")
+	llmModelDefault := d.str("llm.model")
+	llmEndpointDefault := d.str("llm.endpoint")
+	llmKeyDefault := d.str("llm.key")
+	llmTemperatureDefault := d.float("llm.temperature")
+	llmMaxTokensDefault := d.integer("llm.max_tokens")
+	llmMaxDiffDefault := d.integer("llm.max_diff")
+	llmStrictDefault := d.boolean("llm.strict")
+	strictSplitDefault := d.boolean("strict_split")
+	explainFormatDefault := d.str("explain_format")
+	llmSystemDefault := d.str("llm.system")
+	llmUserDefault := d.str("llm.user")
+	llmRefererDefault := d.str("llm.referer")
+	llmTitleDefault := d.str("llm.title")
+
+	var modeFlag string
+	var formatFlag string
+	var langFlag string
+	var typeFlag string
+	var scopeFlag string
+	var bodyFlag string
+	var refsFlag string
+	var closesFlag string
+	var scopeMapFlag string
+	var typeMapFlag string
+	var rulesFlag string
+	var stagedFlag bool
+	var unstagedFlag bool
+	var allFlag bool
+	var breakingFlag bool
+	var emojiFlag bool
+	var explainFlag bool
+	var copyFlag bool
+	var strictSplitFlag bool
+	var explainFormatFlag string
+	var maxItemsFlag int
+	var maxSubjectFlag int
+	var maxBodyLinesFlag int
+	var maxBodyBytesFlag int
+	var maxDiffBytesFlag int
+	var llmFlag bool
+	var llmProviderFlag string
+	var llmModelFlag string
+	var llmEndpointFlag string
+	var llmKeyFlag string
+	var llmTemperatureFlag float64
+	var llmMaxTokensFlag int
+	var llmMaxDiffFlag int
+	var llmStrictFlag bool
+	var llmOnCancelFlag string
+	var llmParallelFlag int
+	var llmGzipFlag string
+	var secretsFlag string
+	var allowSecretsFlag bool
+	var allowInsecureFlag bool
+	var llmClientCertFlag string
+	var llmUserAgentFlag string
+	var llmClientKeyFlag string
+	var llmSystemFlag string
+	var llmUserFlag string
+	var llmRefererFlag string
+	var llmTitleFlag string
+	var versionFlag bool
+	var commitFlag bool
+	var interactiveFlag bool
+	var editFlag bool
+	var yesFlag bool
+	var dryRunFlag bool
+	var outputFlag string
+	var print0Flag bool
+	var jsonFlag bool
+	var quietFlag bool
+	var prettyFlag string
+	var semanticReleaseFlag bool
+	var presetFlag string
+	var historyFlag bool
+	var noUntrackedFlag bool
+	var untrackedMaxFlag int
+	var branchRefsFlag bool
+	var jiraURLFlag string
+	var jiraUserFlag string
+	var jiraTokenFlag string
+	var azureBoardsFlag string
+	var linkRefsFlag bool
+	var smart SmartCommit
+	var changeIDFlag bool
+	var mobFlag string
+	var webhookFlag string
+	var webhookFormatFlag string
+	var issueTitlesFlag bool
+	includeFlag := globList{values: splitList(d.str("include"))}
+	excludeFlag := globList{values: splitList(d.str("exclude"))}
+	sensitiveFlag := globList{values: splitList(d.str("sensitive_paths"))}
+	var commitlintFlag bool
+	var commitizenFlag bool
+	var verboseFlag bool
+	var debugFlag bool
+	var logLevelFlag string
+
+	fs.StringVar(&modeFlag, "mode", modeDefault, "auto|staged|unstaged|all")
+	fs.BoolVar(&stagedFlag, "staged", false, "use staged changes")
+	fs.BoolVar(&unstagedFlag, "unstaged", false, "use unstaged changes")
+	fs.BoolVar(&allFlag, "all", false, "use staged and unstaged changes")
+	fs.StringVar(&formatFlag, "format", formatDefault, "plain|conventional|gitmoji")
+	fs.StringVar(&langFlag, "lang", langDefault, "auto|en|ru")
+	fs.StringVar(&typeFlag, "type", typeDefault, "force commit type")
+	fs.StringVar(&scopeFlag, "scope", scopeDefault, "force scope")
+	fs.StringVar(&scopeMapFlag, "scope-map", scopeMapDefault, "path glob to scope mapping (e.g. 'services/payments/**=payments,proto/**=api')")
+	fs.StringVar(&typeMapFlag, "type-map", typeMapDefault, "path glob to type mapping (e.g. 'deploy/**=infra,benchmarks/**=perf')")
+	fs.StringVar(&rulesFlag, "rules", rulesDefault, "file with custom detection rules")
+	fs.BoolVar(&breakingFlag, "breaking", breakingDefault, "mark as breaking change")
+	fs.StringVar(&bodyFlag, "body", bodyDefault, "auto|none|files|stats|summary")
+	fs.IntVar(&maxItemsFlag, "max-items", maxItemsDefault, "max items in body list")
+	fs.IntVar(&maxSubjectFlag, "max-subject", maxSubjectDefault, "max subject length")
+	fs.IntVar(&maxBodyLinesFlag, "max-body-lines", d.integer("max_body_lines"), "max body lines in the final message, 0 for no limit")
+	fs.IntVar(&maxBodyBytesFlag, "max-body-bytes", d.integer("max_body_bytes"), "max body size in bytes in the final message, 0 for no limit")
+	fs.IntVar(&maxDiffBytesFlag, "max-diff-bytes", d.integer("max_diff_bytes"), "stop reading git diff after this many bytes (rounded to a whole hunk), 0 for no limit")
+	fs.StringVar(&refsFlag, "refs", refsDefault, "comma-separated issue references")
+	fs.StringVar(&closesFlag, "closes", closesDefault, "comma-separated issue numbers to close")
+	fs.BoolVar(&branchRefsFlag, "branch-refs", d.boolean("branch_refs"), "take an issue number from the branch name (e.g. fix/123-retry) when -refs/-closes are empty")
+	fs.BoolVar(&issueTitlesFlag, "issue-titles", d.boolean("issue_titles"), "add GitHub issue titles to Refs/Closes footers (needs GH_TOKEN)")
+	fs.StringVar(&jiraURLFlag, "jira-url", d.str("jira.url"), "Jira base URL; enables Jira keys from the branch name")
+	fs.StringVar(&jiraUserFlag, "jira-user", d.str("jira.user"), "Jira user (Jira Cloud e-mail); empty for a bearer token")
+	fs.StringVar(&jiraTokenFlag, "jira-token", d.str("jira.token"), "Jira API token (prefer env)")
+	fs.BoolVar(&linkRefsFlag, "link-refs", d.boolean("link_refs"), "write issue references in footers as links to the forge (GitHub, GitLab, Bitbucket, Gitea) or Jira")
+	fs.StringVar(&azureBoardsFlag, "azure-boards", d.str("azure_boards"), "footer|subject: link Azure Boards work items (AB#123) from -refs or the branch name")
+	fs.BoolVar(&changeIDFlag, "change-id", d.boolean("change_id"), "append a Gerrit Change-Id footer, stable for the same change")
+	fs.StringVar(&mobFlag, "mob", d.str("mob"), "auto|off|comma-separated initials from .git-coauthors: add Co-authored-by trailers")
+	fs.StringVar(&webhookFlag, "webhook", d.str("webhook.url"), "POST the commit to this URL after -commit succeeds (prefer env)")
+	fs.StringVar(&webhookFormatFlag, "webhook-format", d.str("webhook.format"), "json|slack")
+	fs.BoolVar(&emojiFlag, "emoji", emojiDefault, "prepend gitmoji code to subject")
+	fs.StringVar(&presetFlag, "preset", d.str("preset"), "angular|conventionalcommits|atom|ember: commit convention for types, casing and footers")
+	fs.BoolVar(&semanticReleaseFlag, "semantic-release", d.boolean("semantic_release"), "keep messages parseable by semantic-release's default commit analyzer")
+	fs.BoolVar(&explainFlag, "explain", explainDefault, "print reasoning to stderr")
+	fs.StringVar(&explainFormatFlag, "explain-format", explainFormatDefault, "text|json")
+	fs.BoolVar(&copyFlag, "copy", copyDefault, "copy result to clipboard if possible")
+	fs.BoolVar(&strictSplitFlag, "strict-split", strictSplitDefault, "fail with exit code 3 when changes should be split")
+	fs.BoolVar(&llmFlag, "llm", llmDefault, "use LLM to generate message")
+	fs.StringVar(&llmProviderFlag, "provider", llmProviderDefault, "openai|openrouter")
+	fs.StringVar(&llmModelFlag, "model", llmModelDefault, "LLM model name")
+	fs.StringVar(&llmEndpointFlag, "endpoint", llmEndpointDefault, "override LLM endpoint URL")
+	fs.StringVar(&llmUserAgentFlag, "llm-user-agent", d.str("llm.user_agent"), "User-Agent for LLM requests (default aicommit/<version>)")
+	fs.StringVar(&llmClientCertFlag, "llm-client-cert", d.str("llm.client_cert"), "PEM client certificate for LLM gateways that require mutual TLS")
+	fs.StringVar(&llmClientKeyFlag, "llm-client-key", d.str("llm.client_key"), "PEM private key for -llm-client-cert")
+	fs.BoolVar(&allowInsecureFlag, "allow-insecure-endpoint", d.boolean("llm.allow_insecure_endpoint"), "allow a plain-HTTP -endpoint on a non-local host (the API key is sent in cleartext)")
+	fs.StringVar(&llmKeyFlag, "llm-key", llmKeyDefault, "LLM API key (prefer env)")
+	fs.Float64Var(&llmTemperatureFlag, "temperature", llmTemperatureDefault, "LLM sampling temperature")
+	fs.IntVar(&llmMaxTokensFlag, "max-tokens", llmMaxTokensDefault, "LLM max tokens")
+	fs.IntVar(&llmMaxDiffFlag, "llm-max-diff", llmMaxDiffDefault, "max diff bytes to send to LLM")
+	fs.BoolVar(&llmStrictFlag, "llm-strict", llmStrictDefault, "fail if LLM request fails")
+	fs.IntVar(&llmParallelFlag, "llm-parallel", d.integer("llm.parallel"), "maximum concurrent LLM requests for batched work such as rewrite")
+	fs.StringVar(&llmOnCancelFlag, "llm-on-cancel", d.str("llm.on_cancel"), "abort|heuristic: what Ctrl-C during the LLM request does")
+	fs.StringVar(&secretsFlag, "secrets", d.str("llm.secrets"), "redact|block|off: what to do with credentials found in content for the LLM")
+	fs.BoolVar(&allowSecretsFlag, "allow-secrets", d.boolean("allow_secrets"), "send content with detected secrets (redacted) even with -secrets block")
+	fs.StringVar(&llmGzipFlag, "llm-gzip", d.str("llm.gzip"), "auto|on|off: gzip large LLM requests (auto: only for the OpenAI and OpenRouter APIs)")
+	fs.StringVar(&llmSystemFlag, "llm-system", llmSystemDefault, "override LLM system prompt")
+	fs.StringVar(&llmUserFlag, "llm-user", llmUserDefault, "extra LLM user instructions ('-' reads them from stdin)")
+	fs.StringVar(&llmRefererFlag, "llm-referer", llmRefererDefault, "openrouter HTTP-Referer")
+	fs.StringVar(&llmTitleFlag, "llm-title", llmTitleDefault, "openrouter X-Title")
+	fs.BoolVar(&commitFlag, "commit", false, "create the commit with the generated message")
+	fs.BoolVar(&interactiveFlag, "interactive", false, "review the message interactively before committing")
+	fs.BoolVar(&editFlag, "edit", false, "open the generated message in $GIT_EDITOR/$EDITOR before using it")
+	fs.BoolVar(&yesFlag, "yes", yesDefault, "never prompt; assume yes for confirmations (for CI and scripts)")
+	fs.BoolVar(&yesFlag, "no-input", yesDefault, "alias for -yes")
+	fs.BoolVar(&dryRunFlag, "dry-run", false, "report what would be done without calling the LLM or changing anything")
+	fs.StringVar(&outputFlag, "o", "", "write the message to this file instead of stdout (e.g. .git/COMMIT_EDITMSG)")
+	fs.BoolVar(&print0Flag, "print0", false, "terminate the message on stdout with NUL instead of a newline")
+	fs.BoolVar(&jsonFlag, "json", false, "print the message as JSON: subject, body, type, scope, footers, breaking and source (heuristic or llm)")
+	fs.BoolVar(&quietFlag, "quiet", d.boolean("quiet"), "do not show progress on stderr")
+	fs.StringVar(&prettyFlag, "pretty", d.str("pretty"), "auto|on|off: color and progress on a terminal; off prints only the raw message (auto: on when stdout is a TTY)")
+	fs.StringVar(&smart.Key, "smart-key", "", "Jira issue key for smart-commit commands (default: from -refs or the branch name)")
+	fs.StringVar(&smart.Comment, "smart-comment", "", "add a smart-commit '#comment <text>' command")
+	fs.StringVar(&smart.Time, "smart-time", "", "add a smart-commit '#time <duration>' command, e.g. 2h 30m")
+	fs.StringVar(&smart.Transition, "smart-transition", "", "add a smart-commit workflow transition, e.g. done or 'start progress'")
+	fs.Var(&includeFlag, "include", "only use changes matching this path glob (repeatable, e.g. 'src/**')")
+	fs.Var(&excludeFlag, "exclude", "ignore changes matching this path glob (repeatable, e.g. 'examples/**')")
+	fs.Var(&sensitiveFlag, "sensitive", "never send or render diff content of paths matching this glob, only name and status (repeatable, e.g. '**/.env*')")
+	fs.BoolVar(&noUntrackedFlag, "no-untracked", d.boolean("no_untracked"), "ignore untracked files in unstaged/all modes")
+	fs.IntVar(&untrackedMaxFlag, "untracked-max-bytes", d.integer("untracked_max_bytes"), "read at most this many bytes of each untracked file; larger files are summarized by size and type (0: names only)")
+	fs.BoolVar(&historyFlag, "history", d.boolean("history"), "save the generated message to the local history (see aicommit history)")
+	fs.BoolVar(&commitlintFlag, "commitlint", d.boolean("commitlint"), "follow the repository commitlint config when present")
+	fs.BoolVar(&commitizenFlag, "commitizen", d.boolean("commitizen"), "follow the repository commitizen config when present")
+	fs.BoolVar(&verboseFlag, "v", false, "log git commands, config loading and LLM requests to stderr")
+	fs.BoolVar(&debugFlag, "vv", false, "like -v, plus resolved settings and request details")
+	fs.StringVar(&logLevelFlag, "log-level", d.str("log_level"), "warn|info|debug")
+	fs.BoolVar(&versionFlag, "version", false, "print version and build details")
+
+	fs.Usage = func() {
+		fmt.Fprintln(stderr(ctx), "Usage: aicommit [generate] [options]")
+		fmt.Fprintln(stderr(ctx))
+		fmt.Fprintln(stderr(ctx), "Generate a commit message from current git changes.")
+		fmt.Fprintln(stderr(ctx), "\nOptions:")
+		fs.PrintDefaults()
+		fmt.Fprintln(stderr(ctx))
+		printCommands(stderr(ctx))
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected argument: %s", fs.Arg(0))
+	}
+
+	s := sessionOf(ctx)
+	if level, ok := parseLogLevel(logLevelFlag); ok && int32(level) > s.logLevel.Load() {
+		s.logLevel.Store(int32(level))
+	}
+	if debugFlag {
+		s.logLevel.Store(levelDebug)
+	} else if verboseFlag {
+		s.logLevel.Store(max(s.logLevel.Load(), levelInfo))
+	}
+
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	opts.Version = versionFlag
+	opts.Mode = Mode(modeFlag)
+	if !explicit["mode"] {
+		if allFlag {
+			opts.Mode = ModeAll
+		} else if stagedFlag {
+			opts.Mode = ModeStaged
+		} else if unstagedFlag {
+			opts.Mode = ModeUnstaged
+		}
+	}
+
+	opts.Format = Format(formatFlag)
+	opts.Lang = langFlag
+	opts.Type = strings.TrimSpace(typeFlag)
+	opts.Scope = strings.TrimSpace(scopeFlag)
+	opts.Breaking = breakingFlag
+	opts.Body = BodyMode(bodyFlag)
+	opts.MaxItems = maxItemsFlag
+	opts.MaxSubject = maxSubjectFlag
+	opts.MaxBodyLines = maxBodyLinesFlag
+	opts.MaxBodyBytes = maxBodyBytesFlag
+	opts.MaxDiffBytes = maxDiffBytesFlag
+	opts.Refs = splitList(refsFlag)
+	opts.Closes = splitList(closesFlag)
+	opts.BranchRefs = branchRefsFlag
+	opts.IssueTitles = issueTitlesFlag
+	opts.JiraURL = strings.TrimSpace(jiraURLFlag)
+	opts.AzureBoards = strings.TrimSpace(azureBoardsFlag)
+	opts.LinkRefs = linkRefsFlag
+	opts.ForgeHosts = parseMappings(d.str("forge_hosts"))
+	opts.Smart = smart
+	opts.ChangeID = changeIDFlag
+	opts.Mob = mobFlag
+	opts.WebhookURL = strings.TrimSpace(webhookFlag)
+	opts.WebhookFormat = strings.TrimSpace(webhookFormatFlag)
+	opts.JiraUser = strings.TrimSpace(jiraUserFlag)
+	opts.JiraToken = strings.TrimSpace(jiraTokenFlag)
+	opts.ScopeMap = append(parseMappings(scopeMapFlag), cfg.ScopeMap...)
+	opts.TypeMap = append(parseMappings(typeMapFlag), cfg.TypeMap...)
+	opts.Rules = cfg.Rules
+	opts.RulesFile = strings.TrimSpace(rulesFlag)
+	opts.Emoji = emojiFlag
+	opts.Explain = explainFlag
+	opts.ExplainFormat = strings.TrimSpace(explainFormatFlag)
+	opts.Copy = copyFlag
+	opts.StrictSplit = strictSplitFlag
+	opts.Commit = commitFlag
+	opts.Interactive = interactiveFlag
+	opts.Edit = editFlag
+	opts.AssumeYes = yesFlag
+	opts.DryRun = dryRunFlag
+	opts.Output = strings.TrimSpace(outputFlag)
+	opts.Print0 = print0Flag
+	opts.JSON = jsonFlag
+	opts.Quiet = quietFlag
+	switch mode := strings.ToLower(strings.TrimSpace(prettyFlag)); mode {
+	case "", "auto":
+		opts.Pretty = isTerminal(os.Stdout) && os.Getenv("TERM") != "dumb"
+	case "on", "off":
+		opts.Pretty = mode == "on"
+	default:
+		return opts, fmt.Errorf("unsupported pretty value: %s", prettyFlag)
+	}
+	opts.SemanticRelease = semanticReleaseFlag
+	opts.Preset = strings.TrimSpace(presetFlag)
+	opts.History = historyFlag
+	opts.NoUntracked = noUntrackedFlag
+	opts.UntrackedMaxBytes = untrackedMaxFlag
+	opts.Include = includeFlag.values
+	opts.Exclude = excludeFlag.values
+	opts.Sensitive = sensitiveFlag.values
+	opts.UseCommitlint = commitlintFlag
+	opts.UseCommitizen = commitizenFlag
+	opts.LLMEnabled = llmFlag
+	opts.LLMProvider = strings.TrimSpace(llmProviderFlag)
+	opts.LLMModel = strings.TrimSpace(llmModelFlag)
+	opts.LLMEndpoint = strings.TrimSpace(llmEndpointFlag)
+	opts.AllowInsecure = allowInsecureFlag
+	opts.LLMClientCert = strings.TrimSpace(llmClientCertFlag)
+	opts.LLMOIDC = oidcFromSettings(d.str)
+	opts.LLMUserAgent = strings.TrimSpace(llmUserAgentFlag)
+	opts.LLMClientKey = strings.TrimSpace(llmClientKeyFlag)
+	opts.LLMKey = strings.TrimSpace(llmKeyFlag)
+	opts.LLMTemperature = llmTemperatureFlag
+	opts.LLMMaxTokens = llmMaxTokensFlag
+	opts.LLMMaxDiff = llmMaxDiffFlag
+	opts.LLMStrict = llmStrictFlag
+	opts.LLMParallel = max(llmParallelFlag, 1)
+	opts.LLMOnCancel = strings.ToLower(strings.TrimSpace(llmOnCancelFlag))
+	opts.LLMGzip = strings.ToLower(strings.TrimSpace(llmGzipFlag))
+	opts.Secrets = strings.ToLower(strings.TrimSpace(secretsFlag))
+	opts.AllowSecrets = allowSecretsFlag
+	opts.LLMSystem = strings.TrimSpace(llmSystemFlag)
+	opts.LLMUser = strings.TrimSpace(llmUserFlag)
+	opts.StylePrompt = d.str("style_guide.prompt")
+	opts.LLMReferer = strings.TrimSpace(llmRefererFlag)
+	opts.LLMTitle = strings.TrimSpace(llmTitleFlag)
+
+	return applyOffline(ctx, opts), nil
+}
+
+func run(ctx context.Context, opts Options) error {
+	if err := ensureGit(); err != nil {
+		return err
+	}
+	opts, err := normalizeOptions(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	gen, err := generate(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if opts.DryRun {
+		printDryRun(stdout(ctx), opts, gen)
+		return nil
+	}
+	if opts.Edit && opts.AssumeYes {
+		fmt.Fprintln(stderr(ctx), "warning: -edit ignored with -yes")
+	} else if opts.Edit {
+		message, err := editMessage(ctx, gen.Message)
+		if err != nil {
+			return err
+		}
+		gen.Message = message
+	}
+	if opts.Interactive && opts.AssumeYes {
+		opts.Commit = true
+	} else if opts.Interactive {
+		return runInteractive(ctx, opts, gen, sessionOf(ctx).stdin, stderr(ctx))
+	}
+	committed := false
+	defer func() {
+		recordHistory(ctx, opts, gen, committed)
+	}()
+
+	if opts.JSON {
+		err = writeJSONMessage(stdout(ctx), gen.structured())
+	} else {
+		err = writeMessage(ctx, stdout(ctx), gen.Message, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	if opts.Copy {
+		if err := copyToClipboard(gen.Message); err != nil {
+			fmt.Fprintln(stderr(ctx), "copy failed:", err)
+		}
+	}
+	if opts.Commit && !opts.AssumeYes {
+		ok, err := newPrompter(sessionOf(ctx).stdin, stderr(ctx)).confirm("Commit with this message", true)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("aborted")
+		}
+	}
+	if opts.Commit {
+		if err := commitChanges(ctx, gen.Message, gen.Mode, gen.Changes); err != nil {
+			return err
+		}
+		committed = true
+		notifyCommit(ctx, opts, gen)
+	}
+	if opts.Explain {
+		if err := printExplain(stderr(ctx), gen.Explain, opts.ExplainFormat); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func normalizeOptions(ctx context.Context, opts Options) (Options, error) {
+	if opts.MaxItems <= 0 {
+		opts.MaxItems = 8
+	}
+	if opts.MaxSubject <= 0 {
+		opts.MaxSubject = 72
+	}
+	if opts.Mode == "" {
+		opts.Mode = ModeAuto
+	}
+	if opts.Format == "" {
+		opts.Format = FormatConventional
+	}
+	if opts.Body == "" {
+		opts.Body = BodyAuto
+	}
+	opts.LLMParallel = max(opts.LLMParallel, 1)
+	preset, err := lookupPreset(opts.Preset)
+	if err != nil {
+		return opts, err
+	}
+	opts.preset = preset
+	if opts.SemanticRelease || opts.preset != nil {
+		if opts.Format != FormatConventional || opts.Emoji {
+			fmt.Fprintln(stderr(ctx), "warning: -semantic-release and -preset use their own header format; -format and -emoji are ignored")
+		}
+		opts.Format, opts.Emoji = FormatConventional, false
+	}
+	if opts.LLMEnabled && opts.LLMMaxDiff <= 0 {
+		opts.LLMMaxDiff = 20000
+	}
+	if opts.LLMEnabled && opts.LLMMaxTokens <= 0 {
+		opts.LLMMaxTokens = 300
+	}
+	if opts.LLMEnabled && opts.LLMModel == "" {
+		opts.LLMModel = "gpt-5-nano"
+	}
+	if opts.LLMUser == "-" {
+		if opts.Interactive && !opts.AssumeYes {
+			return opts, errors.New("-llm-user - reads stdin and cannot be combined with -interactive")
+		}
+		data, err := io.ReadAll(sessionOf(ctx).stdin)
+		if err != nil {
+			return opts, fmt.Errorf("read llm instructions from stdin: %w", err)
+		}
+		opts.LLMUser = strings.TrimSpace(string(data))
+		debugf(ctx, "llm: read %d bytes of extra instructions from stdin", len(data))
+	}
+	if opts.UseCommitlint {
+		if root, err := gitOutput(ctx, "rev-parse", "--show-toplevel"); err == nil {
+			lint, err := loadCommitlint(ctx, root)
+			if err != nil {
+				fmt.Fprintln(stderr(ctx), "warning: commitlint config ignored:", err)
+			}
+			opts.commitlint = lint
+		}
+	}
+	if opts.UseCommitizen {
+		if root, err := gitOutput(ctx, "rev-parse", "--show-toplevel"); err == nil {
+			cz, err := loadCommitizen(ctx, root)
+			if err != nil {
+				fmt.Fprintln(stderr(ctx), "warning: commitizen config ignored:", err)
+			}
+			if cz != nil {
+				opts.commitizen = cz
+				opts.commitlint = cz.apply(opts.commitlint)
+			}
+		}
+	}
+	opts.Smart, err = resolveSmartKey(ctx, opts.Smart, append(append([]string{}, opts.Refs...), opts.Closes...))
+	if err != nil {
+		return opts, err
+	}
+	if opts.CoAuthors == nil {
+		root, _ := gitOutput(ctx, "rev-parse", "--show-toplevel")
+		authors, err := resolveCoauthors(ctx, opts.Mob, root)
+		if err != nil {
+			return opts, err
+		}
+		opts.CoAuthors = append([]string{}, authors...)
+	}
+	if opts.RulesFile != "" {
+		rules, err := loadRulesFile(sessionOf(ctx).path(opts.RulesFile))
+		if err != nil {
+			return opts, fmt.Errorf("load rules: %w", err)
+		}
+		opts.Rules = append(rules, opts.Rules...)
+		opts.RulesFile = ""
+	}
+	if opts.Lang == "auto" || opts.Lang == "" {
+		opts.Lang = detectLang()
+	}
+	if opts.Lang != "en" && opts.Lang != "ru" {
+		return opts, fmt.Errorf("unsupported lang: %s", opts.Lang)
+	}
+	if !validFormat(opts.Format) {
+		return opts, fmt.Errorf("unsupported format: %s", opts.Format)
+	}
+	if !validBody(opts.Body) {
+		return opts, fmt.Errorf("unsupported body mode: %s", opts.Body)
+	}
+	if !validMode(opts.Mode) {
+		return opts, fmt.Errorf("unsupported mode: %s", opts.Mode)
+	}
+	if opts.AzureBoards != "" && opts.AzureBoards != "footer" && opts.AzureBoards != "subject" {
+		return opts, fmt.Errorf("unsupported azure-boards placement: %s", opts.AzureBoards)
+	}
+	if opts.Secrets != "" && opts.Secrets != "redact" && opts.Secrets != "block" && opts.Secrets != "off" {
+		return opts, fmt.Errorf("unsupported secrets mode: %s", opts.Secrets)
+	}
+	if opts.LLMEnabled {
+		if err := llm.CheckEndpoint(opts.LLMEndpoint, opts.AllowInsecure); err != nil {
+			return opts, err
+		}
+	}
+	if opts.LLMOnCancel != "" && opts.LLMOnCancel != "abort" && opts.LLMOnCancel != "heuristic" {
+		return opts, fmt.Errorf("unsupported llm-on-cancel value: %s", opts.LLMOnCancel)
+	}
+	if opts.LLMGzip != "" && opts.LLMGzip != "auto" && opts.LLMGzip != "on" && opts.LLMGzip != "off" {
+		return opts, fmt.Errorf("unsupported llm-gzip value: %s", opts.LLMGzip)
+	}
+	if opts.WebhookFormat != "" && opts.WebhookFormat != "json" && opts.WebhookFormat != "slack" {
+		return opts, fmt.Errorf("unsupported webhook format: %s", opts.WebhookFormat)
+	}
+	if opts.ExplainFormat != "" && opts.ExplainFormat != "text" && opts.ExplainFormat != "json" {
+		return opts, fmt.Errorf("unsupported explain format: %s", opts.ExplainFormat)
+	}
+	return opts, nil
+}
+
+type generation struct {
+	Mode         Mode
+	Changes      []Change
+	Message      string
+	PromptTokens int
+	Explain      explainInfo
+}
+
+type Message struct {
+	render.Message
+}
+
+func Generate(ctx context.Context, opts Options) (Message, error) {
+	s := sessionOf(ctx).derive(opts.Dir)
+	if opts.Stderr != nil {
+		s.stderr = opts.Stderr
+	}
+	ctx = withSession(ctx, s)
+	opts, err := normalizeOptions(ctx, opts)
+	if err != nil {
+		return Message{}, err
+	}
+	gen, err := generate(ctx, opts)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{Message: gen.structured()}, nil
+}
+
+func (g *generation) structured() render.Message {
+	p := parseCommitMessage(g.Message)
+	m := render.Message{
+		Text:     g.Message,
+		Subject:  p.Subject,
+		Body:     strings.Join(p.Body, "\n"),
+		Type:     p.Type,
+		Scope:    p.Scope,
+		Footers:  p.Footer,
+		Breaking: p.Breaking,
+		Source:   render.SourceHeuristic,
+	}
+	if p.Type == "" {
+		m.Type, m.Scope, m.Breaking = g.Explain.Type, g.Explain.Scope, g.Explain.Breaking
+	}
+	for _, f := range p.Footer {
+		if strings.HasPrefix(f, "BREAKING CHANGE") || strings.HasPrefix(f, "BREAKING-CHANGE") {
+			m.Breaking = true
+		}
+	}
+	if g.Explain.LLM {
+		m.Source = render.SourceLLM
+	}
+	return m
+}
+
+func generate(ctx context.Context, opts Options) (*generation, error) {
+	st, err := collectState(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return generateFrom(ctx, opts, st)
+}
+
+func collectState(ctx context.Context, opts Options) (ChangeSet, error) {
+	modes := []Mode{opts.Mode}
+	if opts.Mode == ModeAuto {
+		modes = []Mode{ModeStaged, ModeUnstaged}
+	}
+	var root string
+	var staged, unstaged []Change
+	stats := make([][]FileStat, len(modes))
+	stop := startProgress(ctx, opts, "reading changes", nil)
+	g, gctx := newTaskGroup(ctx)
+	g.Go(func() (err error) {
+		root, err = gitOutput(gctx, "rev-parse", "--show-toplevel")
+		return err
+	})
+	g.Go(func() (err error) {
+		staged, unstaged, err = gitinfo.CollectChanges(gctx, gitBytes, !opts.NoUntracked)
+		return err
+	})
+	for i, m := range modes {
+		g.Go(func() (err error) {
+			stats[i], err = gitinfo.CollectNumstat(gctx, gitBytes, m)
+			return err
+		})
+	}
+	err := g.Wait()
+	stop()
+	if ctx.Err() != nil {
+		return ChangeSet{}, context.Cause(ctx)
+	}
+	if root == "" {
+		return ChangeSet{}, errors.New("not a git repository")
+	}
+	if err != nil {
+		return ChangeSet{}, err
+	}
+
+	modeUsed, changes := gitinfo.SelectChanges(opts.Mode, staged, unstaged)
+	if len(changes) > 0 && opts.filter().active() {
+		changes = opts.filter().changes(changes)
+		if len(changes) == 0 {
+			return ChangeSet{}, fmt.Errorf("no changes match -include/-exclude for mode %s", modeUsed)
+		}
+	}
+	if len(changes) == 0 {
+		return ChangeSet{}, fmt.Errorf("no changes found for mode %s", modeUsed)
+	}
+	var untracked []Change
+	for _, ch := range changes {
+		if ch.Status == "U" {
+			untracked = append(untracked, ch)
+		}
+	}
+	i := slices.Index(modes, modeUsed)
+	if i < 0 {
+		return ChangeSet{}, fmt.Errorf("unsupported mode: %s", opts.Mode)
+	}
+	modeStats := opts.filter().stats(stats[i])
+	diff := ""
+	if !needsDiff(opts) {
+		infof(ctx, "diff: skipped (type, scope and body %s need only file names)", opts.Body)
+	} else {
+		if paths := selectDiffPaths(ctx, pathFilter{Exclude: opts.Sensitive}.changes(changes), modeStats, opts.MaxDiffBytes); len(paths) > 0 {
+			var read atomic.Int64
+			stop := startProgress(ctx, opts, "collecting diff", &read)
+			var err error
+			diff, err = collectDiff(withProgressCounter(ctx, &read), modeUsed, opts.MaxDiffBytes, paths)
+			stop()
+			if ctx.Err() != nil {
+				return ChangeSet{}, context.Cause(ctx)
+			}
+			if err != nil {
+				return ChangeSet{}, fmt.Errorf("read %s diff: %w", modeUsed, err)
+			}
+		}
+		budget := opts.MaxDiffBytes - len(diff)
+		if len(untracked) > 0 && opts.UntrackedMaxBytes > 0 && (opts.MaxDiffBytes <= 0 || budget > 0) {
+			if extra := untrackedDiff(ctx, root, pathFilter{Exclude: opts.Sensitive}.changes(untracked), opts.UntrackedMaxBytes, max(budget, 0)); extra != "" && diff != "" {
+				diff += "\n" + extra
+			} else if extra != "" {
+				diff = extra
+			}
+		}
+	}
+	return ChangeSet{Root: root, Mode: modeUsed, Changes: changes, Untracked: untracked, Diff: suppressSensitive(opts.Sensitive, opts.filter().diff(diff)), Stats: modeStats}, nil
+}
+
+func diffState(ctx context.Context, opts Options, diff string) (ChangeSet, error) {
+	changes, stats := changesFromDiff(diff)
+	if opts.filter().active() {
+		changes = opts.filter().changes(changes)
+		diff = opts.filter().diff(diff)
+		stats = opts.filter().stats(stats)
+	}
+	diff = suppressSensitive(opts.Sensitive, diff)
+	if len(changes) == 0 {
+		return ChangeSet{}, errors.New("no file changes found in diff")
+	}
+	root, _ := gitOutput(ctx, "rev-parse", "--show-toplevel")
+	return ChangeSet{Root: root, Mode: ModeDiff, Changes: changes, Diff: diff, Stats: stats}, nil
+}
+
+func generateFrom(ctx context.Context, opts Options, st ChangeSet) (*generation, error) {
+	modeUsed, changes, diff, stats := st.Mode, st.Changes, st.Diff, st.Stats
+	opts = resolveIssueRefs(ctx, opts)
+
+	commitType, reasons, typeConfidence := detectType(st, opts)
+	mixed := detectMixed(changes, stats, opts)
+	if len(mixed) > 0 {
+		if opts.StrictSplit {
+			return nil, &exitError{code: exitMixed, err: errors.New(mixedWarning(mixed))}
+		}
+		fmt.Fprintln(stderr(ctx), "warning:", mixedWarning(mixed))
+	}
+	scope, scopeConfidence := detectScope(st, opts)
+	breaking, breakingNote, breakingConfidence := detectBreaking(st, opts)
+	assets := collectAssets(ctx, st)
+	subject := buildSubject(commitType, scope, changes, diff, assets, opts)
+	body := buildBody(st, assets, opts, breaking, breakingNote)
+	message := formatMessage(commitType, scope, subject, body, opts, breaking)
+
+	llmUsed := false
+	promptTokens := 0
+	if opts.LLMEnabled && opts.DryRun {
+		system, user := buildLLMPrompts(opts, st, commitType, scope, breaking, breakingNote, message, reasons)
+		promptTokens = llm.EstimateTokens(system + user)
+	} else if opts.LLMEnabled {
+		opts.LLMRequestID = llm.NewRequestID()
+		llmMessage, err := generateWithLLM(ctx, opts, st, commitType, scope, breaking, breakingNote, message, reasons)
+		var blocked *secretsError
+		if errors.Is(err, errLLMCancelled) {
+			if opts.LLMOnCancel != "heuristic" {
+				return nil, err
+			}
+			fmt.Fprintln(stderr(ctx), "cancelled, using heuristic")
+		} else if err != nil {
+			if opts.LLMStrict || errors.As(err, &blocked) || ctx.Err() != nil {
+				return nil, err
+			}
+			fmt.Fprintln(stderr(ctx), "llm failed, using heuristic:", err)
+		} else if llmMessage != "" {
+			message = llmMessage
+			llmUsed = true
+		}
+	}
+
+	if opts.commitlint != nil {
+		message = fixCommitlint(ctx, message, opts.commitlint)
+	}
+	if opts.commitizen != nil && !llmUsed {
+		message = opts.commitizen.render(message)
+	}
+	if opts.commitlint != nil {
+		for _, v := range lintMessage(message, opts.commitlint) {
+			fmt.Fprintln(stderr(ctx), "commitlint:", v)
+		}
+	}
+	if opts.commitizen != nil && opts.commitizen.SchemaPattern != nil && !opts.commitizen.SchemaPattern.MatchString(message) {
+		fmt.Fprintln(stderr(ctx), "commitizen: message does not match schema_pattern")
+	}
+	if opts.preset != nil {
+		message = opts.preset.normalize(message, breaking, breakingNote, opts.Lang)
+	} else if opts.SemanticRelease {
+		message = semanticReleaseMessage(message, breaking, breakingNote, opts.Lang)
+	}
+	message = addSubjectRefs(message, opts.SubjectRefs)
+	message = addTrailers(message, "Co-authored-by", opts.CoAuthors)
+	message = addSmartCommit(message, opts.Smart)
+	if opts.ChangeID {
+		message = addChangeID(message, currentBranch(ctx), changes, diff)
+	}
+	message = render.LimitBody(message, opts.MaxBodyLines, opts.MaxBodyBytes, opts.Lang)
+
+	return &generation{
+		Mode:         modeUsed,
+		Changes:      changes,
+		Message:      message,
+		PromptTokens: promptTokens,
+		Explain: explainInfo{
+			Mode:               modeUsed,
+			Files:              len(changes),
+			Type:               commitType,
+			TypeConfidence:     typeConfidence,
+			Reasons:            reasons,
+			Scope:              scope,
+			ScopeConfidence:    scopeConfidence,
+			Breaking:           breaking,
+			BreakingNote:       breakingNote,
+			BreakingConfidence: breakingConfidence,
+			Mixed:              mixed,
+			LLM:                llmUsed,
+			LLMRequestID:       opts.LLMRequestID,
+			Format:             opts.Format,
+			Body:               opts.Body,
+			Lang:               opts.Lang,
+		},
+	}, nil
+}
+
+func parseBool(raw string) (bool, bool) {
+	switch strings.TrimSpace(strings.ToLower(raw)) {
+	case "1", "true", "yes", "y", "on":
+		return true, true
+	case "0", "false", "no", "n", "off":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+func splitList(raw string) []string {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	parts := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\t'
+	})
+	var out []string
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
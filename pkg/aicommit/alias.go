@@ -0,0 +1,93 @@
+package aicommit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+func expandAlias(ctx context.Context, args []string) ([]string, bool, error) {
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("config: %w", err)
+	}
+	value, ok := cfg.Aliases[args[0]]
+	if !ok {
+		return nil, false, nil
+	}
+	words, err := splitArgs(value)
+	if err != nil {
+		return nil, false, fmt.Errorf("alias %s: %w", args[0], err)
+	}
+	if err := checkAliasWords(words); err != nil {
+		return nil, false, fmt.Errorf("alias %s: %w", args[0], err)
+	}
+	return append(words, args[1:]...), true, nil
+}
+
+func checkAliasWords(words []string) error {
+	if len(words) > 0 && !strings.HasPrefix(words[0], "-") {
+		return fmt.Errorf("expands to %q; aliases can only hold generate flags", words[0])
+	}
+	for _, w := range words {
+		if w == "--" {
+			break
+		}
+		if !strings.HasPrefix(w, "-") {
+			continue
+		}
+		name, _, _ := strings.Cut(strings.TrimLeft(w, "-"), "=")
+		if slices.ContainsFunc(settings, func(s setting) bool { return s.User && s.Flag == name }) {
+			return fmt.Errorf("-%s can only be passed on the command line", name)
+		}
+	}
+	return nil
+}
+
+func splitArgs(raw string) ([]string, error) {
+	var words []string
+	var cur strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range raw {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if escaped {
+		return nil, errors.New("trailing backslash")
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,38 @@
+package aicommit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skrashevich/aicommit/internal/fixture"
+)
+
+func TestRepoAliasCannotChangeUserConfig(t *testing.T) {
+	r := fixture.New(t)
+	r.Write(".aicommit.toml", "[alias]\nquick = \"config set llm.endpoint http://evil.example/v1\"\n").Commit("initial commit")
+	home := enterRepo(t, r)
+
+	if err := dispatch(context.Background(), []string{"quick"}); err == nil {
+		t.Error("repo alias quick was expanded")
+	}
+	if _, err := os.Stat(filepath.Join(home, ".config", "aicommit", "config.toml")); !os.IsNotExist(err) {
+		t.Errorf("user config was written: %v", err)
+	}
+}
+
+func TestAliasRejectsUserOnlyFlags(t *testing.T) {
+	for _, value := range []string{"config set llm.endpoint http://evil.example/v1", "-endpoint http://evil.example/v1", "-allow-insecure-endpoint", "-webhook=https://hook.example"} {
+		words, err := splitArgs(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if checkAliasWords(words) == nil {
+			t.Errorf("alias %q was accepted", value)
+		}
+	}
+	if err := checkAliasWords([]string{"-format", "plain", "-body", "none"}); err != nil {
+		t.Errorf("generate flags rejected: %v", err)
+	}
+}
@@ -0,0 +1,282 @@
+package aicommit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/skrashevich/aicommit/pkg/detect"
+)
+
+type assetChange struct {
+	Change
+	Kind  string
+	Delta int64
+}
+
+func allAssets(changes []Change) bool {
+	if len(changes) == 0 {
+		return false
+	}
+	for _, ch := range changes {
+		if detect.CategorizePath(ch.Path) != detect.Assets {
+			return false
+		}
+	}
+	return true
+}
+
+func collectAssets(ctx context.Context, cs ChangeSet) []assetChange {
+	mode, root := cs.Mode, cs.Root
+	byPath := statsByPath(cs.Stats)
+	var out []assetChange
+	for _, ch := range cs.Changes {
+		st, ok := byPath[ch.Path]
+		if !detect.IsAssetPath(ch.Path) && !(ok && st.Binary) {
+			continue
+		}
+		out = append(out, assetChange{Change: ch, Kind: detect.AssetKind(ch.Path), Delta: assetSizeDelta(ctx, ch, mode, root)})
+	}
+	return out
+}
+
+func assetSizeDelta(ctx context.Context, ch Change, mode Mode, root string) int64 {
+	oldPath := ch.Path
+	if ch.OldPath != "" {
+		oldPath = ch.OldPath
+	}
+	var before, after int64
+	switch mode {
+	case ModeDiff:
+		return 0
+	case ModeStaged:
+		before = blobSize(ctx, "HEAD:"+oldPath)
+		after = blobSize(ctx, ":"+ch.Path)
+	case ModeUnstaged:
+		before = blobSize(ctx, ":"+oldPath)
+		after = worktreeSize(root, ch.Path)
+	default:
+		before = blobSize(ctx, "HEAD:"+oldPath)
+		after = worktreeSize(root, ch.Path)
+	}
+	if ch.Status == "D" {
+		after = 0
+	}
+	if ch.Status == "U" || ch.Status == "A" {
+		before = 0
+		if after == 0 {
+			after = worktreeSize(root, ch.Path)
+		}
+	}
+	return after - before
+}
+
+func blobSize(ctx context.Context, spec string) int64 {
+	out, err := gitOutput(ctx, "cat-file", "-s", spec)
+	if err != nil {
+		return 0
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(out), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return size
+}
+
+func worktreeSize(root, path string) int64 {
+	info, err := os.Stat(filepath.Join(root, filepath.FromSlash(path)))
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+func assetPaths(assets []assetChange) map[string]struct{} {
+	set := make(map[string]struct{}, len(assets))
+	for _, a := range assets {
+		set[a.Path] = struct{}{}
+	}
+	return set
+}
+
+func withoutAssets(changes []Change, assets []assetChange) []Change {
+	if len(assets) == 0 {
+		return changes
+	}
+	set := assetPaths(assets)
+	var out []Change
+	for _, ch := range changes {
+		if _, ok := set[ch.Path]; !ok {
+			out = append(out, ch)
+		}
+	}
+	return out
+}
+
+type assetGroup struct {
+	Kind   string
+	Action string
+	Count  int
+	Delta  int64
+}
+
+func groupAssets(assets []assetChange) []assetGroup {
+	byKey := map[string]*assetGroup{}
+	for _, a := range assets {
+		action := "updated"
+		switch a.Status {
+		case "A", "U", "C":
+			action = "added"
+		case "D":
+			action = "removed"
+		case "R":
+			action = "moved"
+		}
+		key := a.Kind + "/" + action
+		g, ok := byKey[key]
+		if !ok {
+			g = &assetGroup{Kind: a.Kind, Action: action}
+			byKey[key] = g
+		}
+		g.Count++
+		g.Delta += a.Delta
+	}
+	out := make([]assetGroup, 0, len(byKey))
+	for _, g := range byKey {
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind < out[j].Kind
+		}
+		return out[i].Action < out[j].Action
+	})
+	return out
+}
+
+func buildAssetLines(assets []assetChange, lang string) []string {
+	var lines []string
+	for _, g := range groupAssets(assets) {
+		lines = append(lines, fmt.Sprintf("- %s (%s)", describeAssetGroup(g, lang), formatSizeDelta(g.Delta)))
+	}
+	return lines
+}
+
+func describeAssetGroup(g assetGroup, lang string) string {
+	if lang == "ru" {
+		return fmt.Sprintf("%s: %d, %s", assetKindLabel(g.Kind, lang), g.Count, assetActionLabel(g.Action, lang))
+	}
+	noun := assetKindLabel(g.Kind, lang)
+	if g.Count != 1 {
+		noun += "s"
+	}
+	return fmt.Sprintf("%d %s %s", g.Count, noun, g.Action)
+}
+
+func assetSubject(assets []assetChange, lang string) string {
+	added := 0
+	kinds := map[string]int{}
+	var total int64
+	for _, a := range assets {
+		if a.Status == "A" || a.Status == "U" || a.Status == "C" {
+			added++
+		}
+		kinds[a.Kind]++
+		total += a.Delta
+	}
+	var names []string
+	for kind := range kinds {
+		names = append(names, kind)
+	}
+	sort.Strings(names)
+	var parts []string
+	for _, kind := range names {
+		count := kinds[kind]
+		if lang == "ru" {
+			parts = append(parts, fmt.Sprintf("%s: %d", assetKindLabel(kind, lang), count))
+			continue
+		}
+		noun := assetKindLabel(kind, lang)
+		if count != 1 {
+			noun += "s"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", count, noun))
+	}
+	if lang == "ru" {
+		verb := "Обнови"
+		if added == len(assets) {
+			verb = "Добавь"
+		}
+		return fmt.Sprintf("%s ресурсы (%s, %s)", verb, strings.Join(parts, ", "), formatSizeDelta(total))
+	}
+	verb := "Update"
+	if added == len(assets) {
+		verb = "Add"
+	}
+	return fmt.Sprintf("%s %s (%s)", verb, strings.Join(parts, ", "), formatSizeDelta(total))
+}
+
+func assetKindLabel(kind, lang string) string {
+	if lang == "ru" {
+		switch kind {
+		case "image":
+			return "изображения"
+		case "font":
+			return "шрифты"
+		case "audio":
+			return "аудио"
+		case "video":
+			return "видео"
+		case "document":
+			return "документы"
+		default:
+			return "бинарные файлы"
+		}
+	}
+	switch kind {
+	case "binary":
+		return "binary file"
+	default:
+		return kind
+	}
+}
+
+func assetActionLabel(action, lang string) string {
+	if lang != "ru" {
+		return action
+	}
+	switch action {
+	case "added":
+		return "добавлено"
+	case "removed":
+		return "удалено"
+	case "moved":
+		return "перемещено"
+	default:
+		return "обновлено"
+	}
+}
+
+func formatSizeDelta(delta int64) string {
+	sign := "+"
+	if delta < 0 {
+		sign = "-"
+		delta = -delta
+	}
+	const unit = 1024
+	if delta < unit {
+		return fmt.Sprintf("%s%d B", sign, delta)
+	}
+	value := float64(delta)
+	suffixes := []string{"KB", "MB", "GB", "TB"}
+	i := -1
+	for value >= unit && i < len(suffixes)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%s%.1f %s", sign, value, suffixes[i])
+}
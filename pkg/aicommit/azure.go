@@ -0,0 +1,80 @@
+package aicommit
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	azureWorkItemPattern = regexp.MustCompile(`(?i)^AB#(\d+)$`)
+	azureBranchPattern   = regexp.MustCompile(`(?i)(?:^|[/_-])AB#?-?(\d+)(?:[-_/]|$)`)
+)
+
+func azureWorkItem(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if m := azureWorkItemPattern.FindStringSubmatch(ref); m != nil {
+		return "AB#" + m[1]
+	}
+	return ""
+}
+
+func branchWorkItem(branch string) string {
+	if m := azureBranchPattern.FindStringSubmatch(branch); m != nil {
+		return "AB#" + m[1]
+	}
+	if ref := branchIssue(branch); ref != "" {
+		return "AB" + ref
+	}
+	return ""
+}
+
+func azureRefs(refs []string) []string {
+	out := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if m := issueRefPattern.FindStringSubmatch(strings.TrimSpace(ref)); m != nil {
+			ref = "AB#" + m[1]
+		}
+		out = append(out, ref)
+	}
+	return out
+}
+
+func resolveAzureRefs(opts Options, branch string) Options {
+	opts.Refs, opts.Closes = azureRefs(opts.Refs), azureRefs(opts.Closes)
+	if len(opts.Refs) == 0 && len(opts.Closes) == 0 {
+		if item := branchWorkItem(branch); item != "" {
+			opts.Refs = []string{item}
+		}
+	}
+	if opts.AzureBoards != "subject" {
+		return opts
+	}
+	var rest []string
+	for _, ref := range opts.Refs {
+		if item := azureWorkItem(ref); item != "" {
+			opts.SubjectRefs = append(opts.SubjectRefs, item)
+		} else {
+			rest = append(rest, ref)
+		}
+	}
+	opts.Refs = rest
+	return opts
+}
+
+func addSubjectRefs(message string, refs []string) string {
+	header, rest, _ := strings.Cut(message, "\n")
+	var missing []string
+	for _, ref := range refs {
+		if !strings.Contains(header, ref) {
+			missing = append(missing, ref)
+		}
+	}
+	if len(missing) == 0 {
+		return message
+	}
+	header += " (" + strings.Join(missing, ", ") + ")"
+	if rest == "" {
+		return header
+	}
+	return header + "\n" + rest
+}
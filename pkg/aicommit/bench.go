@@ -0,0 +1,117 @@
+package aicommit
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	benchFuncRe   = regexp.MustCompile(`^func\s+(Benchmark[A-Za-z0-9_]*)\s*\(`)
+	benchLineRe   = regexp.MustCompile(`^(Benchmark[^\s]+?)(?:-\d+)?\s+\d+\s+([\d.]+)\s+ns/op`)
+	benchstatRe   = regexp.MustCompile(`^([^\s]+)\s+([\d.]+[^\s±]*)\s+±\s*[^\s]+\s+([\d.]+[^\s±]*)\s+±\s*[^\s]+\s+([-+~][\d.]*%?)`)
+	benchDirNames = map[string]bool{"bench": true, "benches": true, "benchmark": true, "benchmarks": true}
+)
+
+type benchResult struct {
+	Name   string
+	Before string
+	After  string
+	Delta  string
+}
+
+func isBenchmarkPath(path string) bool {
+	base := strings.ToLower(filepath.Base(path))
+	if strings.HasSuffix(base, "_bench_test.go") || strings.HasSuffix(base, "_benchmark_test.go") {
+		return true
+	}
+	parts := strings.Split(strings.ToLower(path), "/")
+	for _, part := range parts[:len(parts)-1] {
+		if benchDirNames[part] {
+			return true
+		}
+	}
+	return false
+}
+
+func isBenchResultPath(path string) bool {
+	base := strings.ToLower(filepath.Base(path))
+	if !strings.Contains(base, "bench") {
+		return false
+	}
+	switch filepath.Ext(base) {
+	case ".txt", ".out", ".bench", ".benchstat", ".log":
+		return true
+	default:
+		return false
+	}
+}
+
+func diffHasBenchmarks(diff string) bool {
+	for _, fd := range parseDiffFiles(diff) {
+		for _, line := range fd.Added {
+			if benchFuncRe.MatchString(strings.TrimSpace(line)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func collectBenchResults(diff string) []benchResult {
+	var out []benchResult
+	for _, fd := range parseDiffFiles(diff) {
+		if !isBenchResultPath(fd.Path) {
+			continue
+		}
+		before := map[string]string{}
+		for _, line := range fd.Removed {
+			if m := benchLineRe.FindStringSubmatch(strings.TrimSpace(line)); len(m) > 2 {
+				before[m[1]] = m[2]
+			}
+		}
+		for _, line := range fd.Added {
+			content := strings.TrimSpace(line)
+			if m := benchstatRe.FindStringSubmatch(content); len(m) > 4 && m[1] != "name" {
+				out = append(out, benchResult{Name: m[1], Before: m[2], After: m[3], Delta: m[4]})
+				continue
+			}
+			m := benchLineRe.FindStringSubmatch(content)
+			if len(m) < 3 {
+				continue
+			}
+			old, ok := before[m[1]]
+			if !ok {
+				continue
+			}
+			out = append(out, benchResult{Name: m[1], Before: old + " ns/op", After: m[2] + " ns/op", Delta: benchDelta(old, m[2])})
+		}
+	}
+	return out
+}
+
+func benchDelta(before, after string) string {
+	b, err1 := strconv.ParseFloat(before, 64)
+	a, err2 := strconv.ParseFloat(after, 64)
+	if err1 != nil || err2 != nil || b == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%+.1f%%", (a-b)/b*100)
+}
+
+func buildBenchLines(results []benchResult, maxItems int) []string {
+	var lines []string
+	for i, r := range results {
+		if maxItems > 0 && i >= maxItems {
+			break
+		}
+		line := fmt.Sprintf("- %s: %s -> %s", r.Name, r.Before, r.After)
+		if r.Delta != "" {
+			line += " (" + r.Delta + ")"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
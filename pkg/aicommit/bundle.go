@@ -0,0 +1,269 @@
+package aicommit
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var personalKeys = []string{"assume_yes", "log_level", "history", "mob", "copy", "explain", "llm.client_cert", "llm.client_key"}
+
+func configExport(ctx context.Context, args []string, cfg *config, out io.Writer) error {
+	fs := flag.NewFlagSet("config export", flag.ContinueOnError)
+	output := fs.String("o", "", "write the bundle to this file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	bundle, err := exportBundle(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	data := renderConfigBundle(bundle, false)
+	if *output == "" {
+		_, err := io.WriteString(out, data)
+		return err
+	}
+	if err := os.WriteFile(*output, []byte(data), 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "wrote %s (%d settings, %d scope mappings, %d type mappings, %d rules)\n", *output, len(bundle.Values), len(bundle.ScopeMap), len(bundle.TypeMap), len(bundle.Rules))
+	return nil
+}
+
+func exportBundle(ctx context.Context, cfg *config) (configLayer, error) {
+	bundle := configLayer{Path: "bundle", Values: map[string]string{}, Aliases: map[string]string{}}
+	guide, _ := styleGuideSource(ctx, cfg.Layers)
+	for _, layer := range cfg.Layers {
+		if guide != "" && layer.Path == guide {
+			continue
+		}
+		bundle = mergeLayers(bundle, layer)
+	}
+	if file := bundle.Values["rules_file"]; file != "" {
+		rules, err := loadRulesFile(file)
+		if err != nil {
+			return configLayer{}, fmt.Errorf("rules_file: %w", err)
+		}
+		bundle.Rules = append(bundle.Rules, rules...)
+		delete(bundle.Values, "rules_file")
+	}
+	for key := range bundle.Values {
+		s, ok := findSetting(key)
+		if !ok || s.Secret || slices.Contains(personalKeys, key) || key == "llm.user" && bundle.Values[key] == "-" {
+			delete(bundle.Values, key)
+		}
+	}
+	return bundle, nil
+}
+
+func mergeLayers(base, over configLayer) configLayer {
+	for key, value := range over.Values {
+		base.Values[key] = value
+	}
+	for name, value := range over.Aliases {
+		base.Aliases[name] = value
+	}
+	base.ScopeMap = mergeMappings(base.ScopeMap, over.ScopeMap)
+	base.TypeMap = mergeMappings(base.TypeMap, over.TypeMap)
+	for _, r := range over.Rules {
+		if !slices.ContainsFunc(base.Rules, func(b Rule) bool { return ruleFields(b) == ruleFields(r) }) {
+			base.Rules = append(base.Rules, r)
+		}
+	}
+	return base
+}
+
+func mergeMappings(base, over []PathMapping) []PathMapping {
+	out := append([]PathMapping{}, base...)
+	for _, m := range over {
+		if i := slices.IndexFunc(out, func(b PathMapping) bool { return b.Pattern == m.Pattern }); i != -1 {
+			out[i] = m
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func ruleFields(r Rule) string {
+	var fields []string
+	if r.Path != nil {
+		fields = append(fields, "path = "+strconv.Quote(r.Path.String()))
+	}
+	if r.Diff != nil {
+		fields = append(fields, "diff = "+strconv.Quote(r.Diff.String()))
+	}
+	if r.Type != "" {
+		fields = append(fields, "type = "+strconv.Quote(r.Type))
+	}
+	if r.Scope != "" {
+		fields = append(fields, "scope = "+strconv.Quote(r.Scope))
+	}
+	if r.Reason != "" {
+		fields = append(fields, "reason = "+strconv.Quote(r.Reason))
+	}
+	return strings.Join(fields, "\n")
+}
+
+func renderConfigBundle(layer configLayer, secrets bool) string {
+	sections := map[string][]string{}
+	for key, value := range layer.Values {
+		if s, ok := findSetting(key); ok && s.Secret && !secrets {
+			continue
+		}
+		section, name := "", key
+		if i := strings.LastIndex(key, "."); i != -1 {
+			section, name = key[:i], key[i+1:]
+		}
+		sections[section] = append(sections[section], name+" = "+tomlLiteral(value))
+	}
+	var b strings.Builder
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		lines := sections[name]
+		sort.Strings(lines)
+		if name != "" {
+			fmt.Fprintf(&b, "\n[%s]\n", name)
+		}
+		b.WriteString(strings.Join(lines, "\n") + "\n")
+	}
+	writeMappings := func(table string, mappings []PathMapping) {
+		if len(mappings) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n[%s]\n", table)
+		for _, m := range mappings {
+			fmt.Fprintf(&b, "%s = %s\n", strconv.Quote(m.Pattern), strconv.Quote(m.Value))
+		}
+	}
+	aliases := make([]PathMapping, 0, len(layer.Aliases))
+	for name, value := range layer.Aliases {
+		aliases = append(aliases, PathMapping{Pattern: name, Value: value})
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Pattern < aliases[j].Pattern })
+	writeMappings("alias", aliases)
+	writeMappings("scope_map", layer.ScopeMap)
+	writeMappings("type_map", layer.TypeMap)
+	for _, r := range layer.Rules {
+		fmt.Fprintf(&b, "\n[[rules]]\n%s\n", ruleFields(r))
+	}
+	return strings.TrimLeft(b.String(), "\n")
+}
+
+func configImport(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("config import", flag.ContinueOnError)
+	repo := fs.Bool("repo", false, "import into the repository .aicommit.toml")
+	replace := fs.Bool("replace", false, "replace the target file instead of merging into it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: aicommit config import [-repo] [-replace] <file|url|->")
+	}
+	src := fs.Arg(0)
+	var data []byte
+	var err error
+	switch {
+	case src == "-":
+		data, err = io.ReadAll(sessionOf(ctx).stdin)
+	case isRemoteSource(src):
+		data, err = fetchStyleGuide(ctx, src)
+	default:
+		data, err = os.ReadFile(src)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", src, err)
+	}
+	bundle, err := parseConfigLayer(src, string(data))
+	if err != nil {
+		return err
+	}
+	for key, value := range bundle.Values {
+		s, ok := findSetting(key)
+		switch {
+		case !ok:
+			fmt.Fprintf(stderr(ctx), "warning: %s: unknown key %s ignored\n", src, key)
+		case s.Secret:
+			fmt.Fprintf(stderr(ctx), "warning: %s: secret %s ignored; set it with aicommit config set\n", src, key)
+		case *repo && userOnly(key, value):
+			fmt.Fprintf(stderr(ctx), "warning: %s: %s can only be set in the user config, git config or environment; ignored\n", src, key)
+		default:
+			if err := validateSetting(s, value); err != nil {
+				return fmt.Errorf("%s: %w", src, err)
+			}
+			continue
+		}
+		delete(bundle.Values, key)
+	}
+	if *repo && len(bundle.Aliases) > 0 {
+		fmt.Fprintf(stderr(ctx), "warning: %s: aliases can only be defined in the user config or git config; ignored\n", src)
+		bundle.Aliases = map[string]string{}
+	}
+
+	path := userConfigPath()
+	if *repo {
+		root, err := gitOutput(ctx, "rev-parse", "--show-toplevel")
+		if err != nil {
+			return errors.New("not a git repository")
+		}
+		path = filepath.Join(root, repoConfigName)
+	}
+	target := configLayer{Path: path, Values: map[string]string{}, Aliases: map[string]string{}}
+	old, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if len(old) > 0 {
+		existing, err := parseConfigLayer(path, string(old))
+		if err != nil {
+			return err
+		}
+		if !*replace {
+			target = existing
+		}
+		for key, value := range existing.Values {
+			if s, ok := findSetting(key); ok && s.Secret {
+				target.Values[key] = value
+			}
+		}
+	}
+	secret := false
+	for key := range target.Values {
+		if s, ok := findSetting(key); ok && s.Secret {
+			secret = true
+		}
+	}
+	merged := mergeLayers(target, bundle)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if len(old) > 0 {
+		if err := os.WriteFile(path+".bak", old, 0o600); err != nil {
+			return err
+		}
+	}
+	perm := os.FileMode(0o644)
+	if secret {
+		perm = 0o600
+	}
+	if err := os.WriteFile(path, []byte(renderConfigBundle(merged, true)), perm); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "imported %d settings, %d scope mappings, %d type mappings, %d rules into %s\n", len(bundle.Values), len(bundle.ScopeMap), len(bundle.TypeMap), len(bundle.Rules), path)
+	if len(old) > 0 {
+		fmt.Fprintf(out, "previous version saved to %s.bak\n", path)
+	}
+	return nil
+}
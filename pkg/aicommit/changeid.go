@@ -0,0 +1,27 @@
+package aicommit
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+)
+
+var changeIDPattern = regexp.MustCompile(`(?m)^Change-Id: I[0-9a-f]{40}\s*$`)
+
+func changeID(branch string, changes []Change, diff string) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "branch %s\n", branch)
+	for _, c := range changes {
+		fmt.Fprintf(h, "%s %s %s\n", c.Status, c.OldPath, c.Path)
+	}
+	fmt.Fprintf(h, "\n%s", diff)
+	return "I" + hex.EncodeToString(h.Sum(nil))
+}
+
+func addChangeID(message, branch string, changes []Change, diff string) string {
+	if changeIDPattern.MatchString(message) {
+		return message
+	}
+	return addTrailers(message, "Change-Id", []string{changeID(branch, changes, diff)})
+}
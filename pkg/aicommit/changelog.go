@@ -0,0 +1,252 @@
+package aicommit
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/skrashevich/aicommit/pkg/llm"
+)
+
+var keepAChangelogSections = []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security"}
+
+type changelogRange struct {
+	Since   string
+	To      string
+	Version string
+	Date    string
+}
+
+func runChangelog(ctx context.Context, args []string, cfg *config, out io.Writer) error {
+	d := layeredDefaults{cfg: cfg, s: sessionOf(ctx)}
+	fs := flag.NewFlagSet("changelog", flag.ContinueOnError)
+	since := fs.String("since", "", "start of the range, exclusive (default: latest tag)")
+	to := fs.String("to", "HEAD", "end of the range")
+	version := fs.String("version", "", "section title (default: the tag at -to or Unreleased)")
+	style := fs.String("style", "conventional", "conventional|keepachangelog")
+	output := fs.String("o", "", "prepend the section to this file instead of printing it")
+	polish := fs.Bool("llm", false, "polish the wording of entries with the configured LLM")
+	presetName := fs.String("preset", d.str("preset"), "angular|conventionalcommits|atom|ember: parse headers and pick visible types like conventional-changelog")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr(ctx), "Usage: aicommit changelog [-since v1.2.0] [-to HEAD] [-style conventional|keepachangelog] [-preset angular] [-llm] [-o CHANGELOG.md]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *style != "conventional" && *style != "keepachangelog" {
+		return fmt.Errorf("invalid -style %q (conventional|keepachangelog)", *style)
+	}
+	preset, err := lookupPreset(*presetName)
+	if err != nil {
+		return err
+	}
+	if err := ensureGit(); err != nil {
+		return err
+	}
+
+	r := resolveChangelogRange(ctx, *since, *to, *version)
+	revs := []string{"--no-merges", r.To}
+	if r.Since != "" {
+		revs[1] = r.Since + ".." + r.To
+	}
+	commits, err := logCommits(ctx, revs...)
+	if err != nil {
+		return err
+	}
+	if preset != nil {
+		commits = preset.applyTo(commits)
+	}
+	if len(commits) == 0 {
+		return fmt.Errorf("no commits in %s", revs[1])
+	}
+
+	var section string
+	if *style == "keepachangelog" {
+		section = renderKeepAChangelog(r, commits)
+	} else {
+		section = renderConventionalChangelog(r, commits)
+	}
+	if *polish {
+		section = polishChangelog(ctx, cfg, section)
+	}
+	if *output == "" {
+		fmt.Fprint(out, section)
+		return nil
+	}
+	if err := prependChangelog(*output, section); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "updated %s (%d commits)\n", *output, len(commits))
+	return nil
+}
+
+func resolveChangelogRange(ctx context.Context, since, to, version string) changelogRange {
+	r := changelogRange{Since: since, To: to, Version: version}
+	exact, _ := gitOutput(ctx, "describe", "--tags", "--exact-match", r.To)
+	if r.Since == "" {
+		from := r.To
+		if exact != "" {
+			from += "^"
+		}
+		r.Since, _ = gitOutput(ctx, "describe", "--tags", "--abbrev=0", from)
+	}
+	if r.Version == "" {
+		r.Version = exact
+	}
+	if r.Version == "" {
+		r.Version = "Unreleased"
+	}
+	r.Date, _ = gitOutput(ctx, "log", "-1", "--format=%cs", r.To)
+	return r
+}
+
+func changelogEntry(c loggedCommit) string {
+	subject := c.Parsed.Subject
+	if c.Parsed.Scope != "" {
+		subject = "**" + c.Parsed.Scope + ":** " + subject
+	}
+	return subject
+}
+
+func sortByScope(commits []loggedCommit) []loggedCommit {
+	out := slices.Clone(commits)
+	slices.SortStableFunc(out, func(a, b loggedCommit) int {
+		return strings.Compare(a.Parsed.Scope, b.Parsed.Scope)
+	})
+	return out
+}
+
+func renderConventionalChangelog(r changelogRange, commits []loggedCommit) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s (%s)\n", r.Version, r.Date)
+	var breaking []string
+	for _, c := range sortByScope(commits) {
+		for _, note := range breakingNotes(c) {
+			if c.Parsed.Scope != "" {
+				note = "**" + c.Parsed.Scope + ":** " + note
+			}
+			breaking = append(breaking, note)
+		}
+	}
+	if len(breaking) > 0 {
+		b.WriteString("\n### ⚠ BREAKING CHANGES\n\n")
+		for _, note := range breaking {
+			fmt.Fprintf(&b, "* %s\n", note)
+		}
+	}
+	for _, g := range groupCommits(commits) {
+		fmt.Fprintf(&b, "\n### %s\n\n", g.Title)
+		for _, c := range sortByScope(g.Commits) {
+			fmt.Fprintf(&b, "* %s (%s)\n", changelogEntry(c), c.SHA[:min(len(c.SHA), 7)])
+		}
+	}
+	return b.String()
+}
+
+func keepAChangelogSection(c loggedCommit) string {
+	subject := strings.ToLower(c.Parsed.Subject)
+	switch {
+	case c.Parsed.Type == "security" || c.Parsed.Scope == "security" || strings.Contains(subject, "security") || strings.Contains(subject, "cve-"):
+		return "Security"
+	case strings.HasPrefix(subject, "deprecate"):
+		return "Deprecated"
+	case strings.HasPrefix(subject, "remove") || strings.HasPrefix(subject, "drop"):
+		return "Removed"
+	}
+	switch c.Parsed.Type {
+	case "feat":
+		return "Added"
+	case "fix":
+		return "Fixed"
+	case "docs", "test", "ci", "chore", "style", "build":
+		if c.Parsed.Breaking {
+			return "Changed"
+		}
+		return ""
+	default:
+		return "Changed"
+	}
+}
+
+func renderKeepAChangelog(r changelogRange, commits []loggedCommit) string {
+	sections := map[string][]string{}
+	for _, c := range sortByScope(commits) {
+		name := keepAChangelogSection(c)
+		if name == "" {
+			continue
+		}
+		entry := changelogEntry(c)
+		if c.Parsed.Breaking {
+			entry = "**BREAKING:** " + entry
+		}
+		sections[name] = append(sections[name], entry)
+	}
+	var b strings.Builder
+	if r.Version == "Unreleased" {
+		b.WriteString("## [Unreleased]\n")
+	} else {
+		fmt.Fprintf(&b, "## [%s] - %s\n", strings.TrimPrefix(r.Version, "v"), r.Date)
+	}
+	for _, name := range keepAChangelogSections {
+		if len(sections[name]) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n", name)
+		for _, entry := range sections[name] {
+			fmt.Fprintf(&b, "- %s\n", entry)
+		}
+	}
+	return b.String()
+}
+
+func polishChangelog(ctx context.Context, cfg *config, section string) string {
+	opts, err := parseFlags(ctx, cfg, nil)
+	if err != nil {
+		fmt.Fprintln(stderr(ctx), "llm polish skipped:", err)
+		return section
+	}
+	opts.LLMMaxTokens = max(opts.LLMMaxTokens, 4*llm.EstimateTokens(section))
+	system := strings.Join([]string{
+		"You edit release changelogs written in Markdown.",
+		"Rewrite each entry so it is clear to users of the project.",
+		"Keep every heading, the order and the number of entries, scopes in bold and commit hashes unchanged.",
+		"Do not invent or merge changes. Return only the Markdown.",
+	}, " ")
+	polished, err := completeChat(ctx, opts, system, section)
+	if err == nil {
+		polished = llm.CleanMessage(polished)
+		if polished == "" {
+			err = errors.New("llm response content is empty")
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(stderr(ctx), "llm polish failed, using generated wording:", err)
+		return section
+	}
+	return polished + "\n"
+}
+
+func prependChangelog(path, section string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	existing := string(data)
+	if existing == "" {
+		existing = "# Changelog\n"
+	}
+	if strings.HasPrefix(existing, "## ") {
+		return os.WriteFile(path, []byte(section+"\n"+existing), 0o644)
+	}
+	idx := strings.Index(existing, "\n## ")
+	if idx == -1 {
+		return os.WriteFile(path, []byte(strings.TrimRight(existing, "\n")+"\n\n"+section), 0o644)
+	}
+	return os.WriteFile(path, []byte(existing[:idx+1]+section+"\n"+existing[idx+1:]), 0o644)
+}
@@ -0,0 +1,118 @@
+package aicommit
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode/utf16"
+)
+
+type clipboardCommand struct {
+	name string
+	args []string
+}
+
+var clipboardCommands = []clipboardCommand{
+	{name: "pbcopy"},
+	{name: "wl-copy"},
+	{name: "xclip", args: []string{"-selection", "clipboard"}},
+	{name: "xsel", args: []string{"--clipboard", "--input"}},
+}
+
+func availableClipboard() (string, bool) {
+	name, _ := clipboardBackend()
+	return name, name != ""
+}
+
+func copyToClipboard(text string) error {
+	name, copyFn := clipboardBackend()
+	if copyFn == nil {
+		return errors.New("no clipboard command found")
+	}
+	if err := copyFn(text); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if name == "tmux" {
+		fmt.Fprintln(os.Stderr, "copied to the tmux paste buffer (no system clipboard found; paste with prefix + ])")
+	} else {
+		fmt.Fprintf(os.Stderr, "copied to clipboard via %s\n", name)
+	}
+	return nil
+}
+
+func clipboardBackend() (string, func(string) error) {
+	if isWSL() {
+		if _, err := exec.LookPath("clip.exe"); err == nil {
+			return "clip.exe", copyWithClipExe
+		}
+	}
+	if os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_TTY") != "" {
+		return "osc52", copyWithOSC52
+	}
+	for _, c := range clipboardCommands {
+		if _, err := exec.LookPath(c.name); err == nil {
+			return c.name, func(text string) error {
+				cmd := exec.Command(c.name, c.args...)
+				cmd.Stdin = strings.NewReader(text)
+				return cmd.Run()
+			}
+		}
+	}
+	if os.Getenv("TMUX") != "" {
+		if _, err := exec.LookPath("tmux"); err == nil {
+			return "tmux", func(text string) error {
+				cmd := exec.Command("tmux", "load-buffer", "-")
+				cmd.Stdin = strings.NewReader(text)
+				return cmd.Run()
+			}
+		}
+	}
+	if os.Getenv("TMUX") != "" || os.Getenv("STY") != "" {
+		return "osc52", copyWithOSC52
+	}
+	return "", nil
+}
+
+func isWSL() bool {
+	if os.Getenv("WSL_DISTRO_NAME") != "" || os.Getenv("WSL_INTEROP") != "" {
+		return true
+	}
+	release, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	return err == nil && strings.Contains(strings.ToLower(string(release)), "microsoft")
+}
+
+func copyWithClipExe(text string) error {
+	units := utf16.Encode(append([]rune{0xfeff}, []rune(text)...))
+	data := make([]byte, 0, len(units)*2)
+	for _, u := range units {
+		data = append(data, byte(u), byte(u>>8))
+	}
+	cmd := exec.Command("clip.exe")
+	cmd.Stdin = strings.NewReader(string(data))
+	return cmd.Run()
+}
+
+func copyWithOSC52(text string) error {
+	var w io.Writer = os.Stderr
+	if tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+		defer tty.Close()
+		w = tty
+	}
+	_, err := io.WriteString(w, osc52Sequence(text))
+	return err
+}
+
+func osc52Sequence(text string) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	switch {
+	case os.Getenv("TMUX") != "":
+		return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	case os.Getenv("STY") != "":
+		return "\x1bP" + seq + "\x1b\\"
+	}
+	return seq
+}
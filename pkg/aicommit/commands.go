@@ -0,0 +1,276 @@
+package aicommit
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+func commandList() []command {
+	return []command{
+		{name: "generate", summary: "generate a commit message from current changes (default)", run: runGenerate},
+		{name: "split-by-package", summary: "propose (and with -commit create) one commit per workspace package", run: func(ctx context.Context, args []string) error {
+			cfg, err := loadConfig(ctx)
+			if err != nil {
+				return fmt.Errorf("config: %w", err)
+			}
+			return runSplitByPackage(ctx, args, cfg, stdout(ctx))
+		}},
+		{name: "init", summary: "interactive setup wizard", run: func(ctx context.Context, args []string) error {
+			return runInit(ctx, args, sessionOf(ctx).stdin, stdout(ctx))
+		}},
+		{name: "config", summary: "get, set, list, export and import configuration values", run: func(ctx context.Context, args []string) error {
+			cfg, err := loadConfig(ctx)
+			if err != nil {
+				return fmt.Errorf("config: %w", err)
+			}
+			return runConfigCommand(ctx, args, cfg, stdout(ctx))
+		}},
+		{name: "lint", summary: "check commit messages against the configured format rules", run: func(ctx context.Context, args []string) error {
+			cfg, err := loadConfig(ctx)
+			if err != nil {
+				return fmt.Errorf("config: %w", err)
+			}
+			return runLint(ctx, args, cfg, stdout(ctx))
+		}},
+		{name: "verify", summary: "check commit messages in a range for CI (text, JSON or SARIF report)", run: func(ctx context.Context, args []string) error {
+			cfg, err := loadConfig(ctx)
+			if err != nil {
+				return fmt.Errorf("config: %w", err)
+			}
+			return runVerify(ctx, args, cfg, stdout(ctx))
+		}},
+		{name: "history", summary: "list previously generated messages", run: func(ctx context.Context, args []string) error {
+			return runHistory(ctx, args, stdout(ctx))
+		}},
+		{name: "last", summary: "print (or commit with) a previously generated message", run: func(ctx context.Context, args []string) error {
+			return runLast(ctx, args, stdout(ctx))
+		}},
+		{name: "undo", summary: "soft-reset the last commit created by aicommit if it was not pushed", run: func(ctx context.Context, args []string) error {
+			return runUndo(ctx, stdout(ctx))
+		}},
+		{name: "reword", summary: "regenerate the message of an unpushed commit and rewrite it", run: func(ctx context.Context, args []string) error {
+			cfg, err := loadConfig(ctx)
+			if err != nil {
+				return fmt.Errorf("config: %w", err)
+			}
+			return runReword(ctx, args, cfg, stdout(ctx))
+		}},
+		{name: "rewrite", summary: "regenerate the messages of every unpushed commit in a range", run: func(ctx context.Context, args []string) error {
+			cfg, err := loadConfig(ctx)
+			if err != nil {
+				return fmt.Errorf("config: %w", err)
+			}
+			return runRewrite(ctx, args, cfg, stdout(ctx))
+		}},
+		{name: "release-notes", summary: "summarize a tag range into release notes with the LLM", run: func(ctx context.Context, args []string) error {
+			cfg, err := loadConfig(ctx)
+			if err != nil {
+				return fmt.Errorf("config: %w", err)
+			}
+			return runReleaseNotes(ctx, args, cfg, stdout(ctx))
+		}},
+		{name: "next-version", summary: "suggest the next semantic version from commits since the last tag", run: func(ctx context.Context, args []string) error {
+			return runNextVersion(ctx, args, stdout(ctx))
+		}},
+		{name: "prepush", summary: "summarize unpushed commits on the current branch", run: func(ctx context.Context, args []string) error {
+			cfg, err := loadConfig(ctx)
+			if err != nil {
+				return fmt.Errorf("config: %w", err)
+			}
+			return runPrepush(ctx, args, cfg, sessionOf(ctx).stdin, stdout(ctx))
+		}},
+		{name: "serve", summary: "serve a JSON API for editor extensions and tools (POST /generate)", run: func(ctx context.Context, args []string) error {
+			return runServe(ctx, args, stdout(ctx))
+		}},
+		{name: "action", summary: "describe a GitHub Actions push/PR range and write step outputs", run: func(ctx context.Context, args []string) error {
+			return runAction(ctx, args, stdout(ctx))
+		}},
+		{name: "pr", summary: "describe the current branch as a merge request (and create it on GitLab)", run: func(ctx context.Context, args []string) error {
+			cfg, err := loadConfig(ctx)
+			if err != nil {
+				return fmt.Errorf("config: %w", err)
+			}
+			return runPR(ctx, args, cfg, stdout(ctx))
+		}},
+		{name: "hook", summary: "install, uninstall or inspect the prepare-commit-msg hook", run: func(ctx context.Context, args []string) error {
+			return runHook(ctx, args, stdout(ctx))
+		}},
+		{name: "changelog", summary: "render a changelog section from conventional commits", run: func(ctx context.Context, args []string) error {
+			cfg, err := loadConfig(ctx)
+			if err != nil {
+				return fmt.Errorf("config: %w", err)
+			}
+			return runChangelog(ctx, args, cfg, stdout(ctx))
+		}},
+		{name: "stats", summary: "summarize your commit history: types, scopes, subject length, compliance", run: func(ctx context.Context, args []string) error {
+			cfg, err := loadConfig(ctx)
+			if err != nil {
+				return fmt.Errorf("config: %w", err)
+			}
+			return runStats(ctx, args, cfg, stdout(ctx))
+		}},
+		{name: "models", summary: "list models available from the LLM provider", run: func(ctx context.Context, args []string) error {
+			cfg, err := loadConfig(ctx)
+			if err != nil {
+				return fmt.Errorf("config: %w", err)
+			}
+			return runModels(ctx, args, cfg, stdout(ctx))
+		}},
+		{name: "doctor", summary: "check environment, configuration and hook state", run: func(ctx context.Context, args []string) error {
+			return runDoctor(ctx, stdout(ctx))
+		}},
+		{name: "about", summary: "print version and build details", run: func(ctx context.Context, args []string) error {
+			cfg, err := loadConfig(ctx)
+			if err != nil {
+				return fmt.Errorf("config: %w", err)
+			}
+			printAbout(ctx, stdout(ctx), cfg)
+			return nil
+		}},
+		{name: "help", summary: "show this help", run: func(ctx context.Context, args []string) error {
+			printCommands(stdout(ctx))
+			return nil
+		}},
+	}
+}
+
+func findCommand(name string) (command, bool) {
+	for _, c := range commandList() {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+func globalFlagsEnd(args []string) int {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || !strings.HasPrefix(arg, "-") {
+			return i
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		switch name {
+		case "offline":
+		case "timeout", "profile":
+			if !hasValue {
+				i++
+			}
+		default:
+			if end := slices.Index(args, "--"); end >= 0 {
+				return end
+			}
+			return len(args)
+		}
+	}
+	return len(args)
+}
+
+func dispatch(ctx context.Context, args []string) (err error) {
+	args, profile, err := cutProfileFlag(args)
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+	args, offline, err := cutOfflineFlag(args)
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+	s := sessionOf(ctx)
+	s.offline = offline
+	args, timeout, err := cutTimeoutFlag(args)
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+	s.logLevel.Store(int32(setupLogging(args)))
+	warnLegacyEnv(s.stderr)
+	stop, err := startProfile(ctx, profile)
+	if err != nil {
+		return err
+	}
+	defer stop()
+	ctx, cancel := startTimeout(ctx, timeout)
+	defer cancel()
+	defer func() { err = timeoutError(ctx, err) }()
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runGenerate(ctx, args)
+	}
+	c, ok := findCommand(args[0])
+	if ok {
+		return c.run(ctx, args[1:])
+	}
+	expanded, ok, err := expandAlias(ctx, args)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		printCommands(s.stderr)
+		return fmt.Errorf("unknown command: %s", args[0])
+	}
+	infof(ctx, "alias %s: %s", args[0], strings.Join(expanded, " "))
+	expanded, offline, err = cutOfflineFlag(expanded)
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+	s.offline = s.offline || offline
+	expanded, timeout, err = cutTimeoutFlag(expanded)
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+	if s.timeout == 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = startTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return runGenerate(ctx, expanded)
+}
+
+func runGenerate(ctx context.Context, args []string) error {
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	opts, err := parseFlags(ctx, cfg, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+	if opts.Version {
+		printAbout(ctx, stdout(ctx), cfg)
+		return nil
+	}
+	if needsOnboarding(cfg, opts) {
+		if err := onboard(ctx, sessionOf(ctx).stdin, stderr(ctx), opts.AssumeYes); err != nil {
+			return err
+		}
+		if cfg, err = loadConfig(ctx); err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+		if opts, err = parseFlags(ctx, cfg, args); err != nil {
+			return &exitError{code: 2, err: err}
+		}
+	}
+	return run(ctx, opts)
+}
+
+func printCommands(w io.Writer) {
+	fmt.Fprintln(w, "Usage: aicommit [command] [options]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, c := range commandList() {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w, "\nGlobal options:\n  -profile file  write a CPU profile (pprof) of the run to file\n  -offline       block all network access (LLM, issue titles, remote style guides, webhooks)\n  -timeout 2m    abort the whole run (git commands and network requests) after this long")
+	fmt.Fprintln(w, "\nRun 'aicommit generate -h' for generation options.")
+}
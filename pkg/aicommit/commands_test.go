@@ -0,0 +1,56 @@
+package aicommit
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCutOfflineFlagStopsAtSubcommand(t *testing.T) {
+	t.Setenv("AICOMMIT_OFFLINE", "")
+	tests := []struct {
+		args    []string
+		want    []string
+		offline bool
+	}{
+		{[]string{"-offline", "-mode", "staged"}, []string{"-mode", "staged"}, true},
+		{[]string{"-mode", "staged", "-offline"}, []string{"-mode", "staged"}, true},
+		{[]string{"-offline", "reword", "HEAD"}, []string{"reword", "HEAD"}, true},
+		{[]string{"reword", "-offline"}, []string{"reword", "-offline"}, false},
+		{[]string{"-timeout", "5s", "pr", "-offline"}, []string{"-timeout", "5s", "pr", "-offline"}, false},
+		{[]string{"-mode", "staged", "--", "-offline"}, []string{"-mode", "staged", "--", "-offline"}, false},
+	}
+	for _, tt := range tests {
+		got, offline, err := cutOfflineFlag(tt.args)
+		if err != nil {
+			t.Fatalf("cutOfflineFlag(%q): %v", tt.args, err)
+		}
+		if !slices.Equal(got, tt.want) || offline != tt.offline {
+			t.Errorf("cutOfflineFlag(%q) = %q, %v; want %q, %v", tt.args, got, offline, tt.want, tt.offline)
+		}
+	}
+}
+
+func TestCutTimeoutFlagStopsAtSubcommand(t *testing.T) {
+	t.Setenv("AICOMMIT_TIMEOUT", "")
+	tests := []struct {
+		args    []string
+		want    []string
+		timeout time.Duration
+	}{
+		{[]string{"-timeout", "5s", "-mode", "staged"}, []string{"-mode", "staged"}, 5 * time.Second},
+		{[]string{"-mode", "staged", "-timeout=1m"}, []string{"-mode", "staged"}, time.Minute},
+		{[]string{"-offline", "-timeout", "5s", "reword"}, []string{"-offline", "reword"}, 5 * time.Second},
+		{[]string{"rewrite", "-timeout", "5s"}, []string{"rewrite", "-timeout", "5s"}, 0},
+		{[]string{"-mode", "staged", "--", "-timeout", "5s"}, []string{"-mode", "staged", "--", "-timeout", "5s"}, 0},
+	}
+	for _, tt := range tests {
+		got, timeout, err := cutTimeoutFlag(tt.args)
+		if err != nil {
+			t.Fatalf("cutTimeoutFlag(%q): %v", tt.args, err)
+		}
+		if !slices.Equal(got, tt.want) || timeout != tt.timeout {
+			t.Errorf("cutTimeoutFlag(%q) = %q, %v; want %q, %v", tt.args, got, timeout, tt.want, tt.timeout)
+		}
+	}
+}
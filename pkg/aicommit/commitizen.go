@@ -0,0 +1,289 @@
+package aicommit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var commitizenFiles = []string{
+	".cz.toml",
+	"cz.toml",
+	".cz.json",
+	"cz.json",
+	".cz.yaml",
+	"cz.yaml",
+	"pyproject.toml",
+	".czrc",
+	"package.json",
+}
+
+type commitizenConfig struct {
+	Path          string
+	Name          string
+	Types         []string
+	Scopes        []string
+	Template      string
+	Schema        string
+	SchemaPattern *regexp.Regexp
+}
+
+func loadCommitizen(ctx context.Context, root string) (*commitizenConfig, error) {
+	for _, name := range commitizenFiles {
+		path := filepath.Join(root, name)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		section, err := decodeCommitizen(name, string(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if section == nil {
+			continue
+		}
+		cfg, err := newCommitizenConfig(path, section)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		infof(ctx, "commitizen: loaded %s (%s, %d types, %d scopes)", path, cfg.Name, len(cfg.Types), len(cfg.Scopes))
+		return cfg, nil
+	}
+	return nil, nil
+}
+
+func decodeCommitizen(name, data string) (map[string]any, error) {
+	switch {
+	case strings.HasSuffix(name, ".toml"):
+		if name == "pyproject.toml" {
+			data = tomlSections(data, "tool.commitizen")
+			if data == "" {
+				return nil, nil
+			}
+		}
+		entries, err := parseTOML(data)
+		if err != nil {
+			return nil, err
+		}
+		tree := tomlTree(entries)
+		if tool, ok := tree["tool"].(map[string]any); ok {
+			tree = tool
+		}
+		section, _ := tree["commitizen"].(map[string]any)
+		return section, nil
+	case strings.HasSuffix(name, ".yaml"):
+		value, err := parseYAML(data)
+		if err != nil {
+			return nil, err
+		}
+		tree, _ := value.(map[string]any)
+		section, _ := tree["commitizen"].(map[string]any)
+		return section, nil
+	case name == "package.json":
+		var pkg struct {
+			Config map[string]any `json:"config"`
+		}
+		if err := json.Unmarshal([]byte(data), &pkg); err != nil {
+			return nil, err
+		}
+		section, _ := pkg.Config["commitizen"].(map[string]any)
+		return section, nil
+	default:
+		var tree map[string]any
+		if err := json.Unmarshal([]byte(data), &tree); err != nil {
+			return nil, err
+		}
+		if section, ok := tree["commitizen"].(map[string]any); ok {
+			return section, nil
+		}
+		return tree, nil
+	}
+}
+
+func tomlSections(data, prefix string) string {
+	var b strings.Builder
+	keep := false
+	for _, line := range strings.Split(data, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			name := strings.TrimSpace(strings.Trim(trimmed, "[]"))
+			keep = name == prefix || strings.HasPrefix(name, prefix+".")
+		}
+		if keep {
+			b.WriteString(line + "\n")
+		}
+	}
+	return b.String()
+}
+
+func newCommitizenConfig(path string, section map[string]any) (*commitizenConfig, error) {
+	cfg := &commitizenConfig{Path: path, Name: "cz_conventional_commits"}
+	if name, ok := section["name"].(string); ok && name != "" {
+		cfg.Name = name
+	}
+	if p, ok := section["path"].(string); ok && p != "" {
+		cfg.Name = p
+	}
+	if strings.Contains(cfg.Name, "conventional") {
+		for _, t := range conventionalTypes {
+			cfg.Types = append(cfg.Types, t.(string))
+		}
+		cfg.Schema = "<type>(<scope>): <subject>\n<BLANK LINE>\n<body>\n<BLANK LINE>\n(BREAKING CHANGE: )<footer>"
+	}
+	if types := choiceValues(section["types"]); len(types) > 0 {
+		cfg.Types = types
+	}
+	if scopes := choiceValues(section["scopes"]); len(scopes) > 0 {
+		cfg.Scopes = scopes
+	}
+	if custom, ok := section["customize"].(map[string]any); ok {
+		cfg.Template, _ = custom["message_template"].(string)
+		if schema, ok := custom["schema"].(string); ok {
+			cfg.Schema = schema
+		}
+		if pattern, ok := custom["schema_pattern"].(string); ok && pattern != "" {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("schema_pattern: %w", err)
+			}
+			cfg.SchemaPattern = re
+		}
+		questions, _ := custom["questions"].([]any)
+		for _, item := range questions {
+			q, _ := item.(map[string]any)
+			switch q["name"] {
+			case "change_type", "type":
+				if values := choiceValues(q["choices"]); len(values) > 0 {
+					cfg.Types = values
+				}
+			case "scope", "scopes":
+				if values := choiceValues(q["choices"]); len(values) > 0 {
+					cfg.Scopes = values
+				}
+			}
+		}
+	}
+	return cfg, nil
+}
+
+func choiceValues(raw any) []string {
+	var out []string
+	switch v := raw.(type) {
+	case []any:
+		for _, item := range v {
+			switch c := item.(type) {
+			case string:
+				out = append(out, c)
+			case map[string]any:
+				for _, key := range []string{"value", "name"} {
+					if s, ok := c[key].(string); ok && s != "" {
+						out = append(out, s)
+						break
+					}
+				}
+			}
+		}
+	case map[string]any:
+		for key := range v {
+			out = append(out, key)
+		}
+		slices.Sort(out)
+	}
+	return out
+}
+
+func (c *commitizenConfig) apply(lint *commitlintConfig) *commitlintConfig {
+	if lint == nil {
+		lint = &commitlintConfig{Path: c.Path, Rules: map[string]commitlintRule{}}
+	}
+	toAny := func(values []string) []any {
+		out := make([]any, 0, len(values))
+		for _, v := range values {
+			out = append(out, v)
+		}
+		return out
+	}
+	if _, ok := lint.Rules["type-enum"]; !ok && len(c.Types) > 0 {
+		lint.Rules["type-enum"] = commitlintRule{Level: 2, When: "always", Value: toAny(c.Types)}
+	}
+	if _, ok := lint.Rules["scope-enum"]; !ok && len(c.Scopes) > 0 {
+		lint.Rules["scope-enum"] = commitlintRule{Level: 2, When: "always", Value: toAny(c.Scopes)}
+	}
+	return lint
+}
+
+func (c *commitizenConfig) render(message string) string {
+	if c.Template == "" {
+		return message
+	}
+	p := parseCommitMessage(message)
+	if p.Type == "" {
+		return message
+	}
+	breaking := ""
+	var footer []string
+	for _, line := range p.Footer {
+		if note, ok := strings.CutPrefix(line, "BREAKING CHANGE: "); ok {
+			breaking = note
+			continue
+		}
+		footer = append(footer, line)
+	}
+	if p.Breaking && breaking == "" {
+		breaking = "true"
+	}
+	vars := map[string]string{
+		"change_type":        p.Type,
+		"prefix":             p.Type,
+		"type":               p.Type,
+		"scope":              p.Scope,
+		"scopes":             p.Scope,
+		"message":            p.Subject,
+		"subject":            p.Subject,
+		"body":               strings.Join(p.Body, "\n"),
+		"footer":             strings.Join(footer, "\n"),
+		"is_breaking_change": breaking,
+	}
+	out := renderCzTemplate(c.Template, vars)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	return strings.TrimSpace(czBlankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+var (
+	czIfPattern  = regexp.MustCompile(`(?s)\{%-?\s*if\s+(\w+)\s*-?%\}(.*?)(?:\{%-?\s*else\s*-?%\}(.*?))?\{%-?\s*endif\s*-?%\}`)
+	czVarPattern = regexp.MustCompile(`\{\{-?\s*(\w+)[^}]*-?\}\}`)
+	czBlankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+func renderCzTemplate(tpl string, vars map[string]string) string {
+	out := czIfPattern.ReplaceAllStringFunc(tpl, func(block string) string {
+		m := czIfPattern.FindStringSubmatch(block)
+		if vars[m[1]] != "" {
+			return m[2]
+		}
+		return m[3]
+	})
+	return czVarPattern.ReplaceAllStringFunc(out, func(ref string) string {
+		return vars[czVarPattern.FindStringSubmatch(ref)[1]]
+	})
+}
+
+func (c *commitizenConfig) promptLines() []string {
+	var out []string
+	if c.Schema != "" {
+		out = append(out, "Message schema (commitizen): "+strings.ReplaceAll(c.Schema, "\n", " / "))
+	}
+	if c.Template != "" {
+		out = append(out, "Message template (commitizen): "+strings.ReplaceAll(c.Template, "\n", " / "))
+	}
+	return out
+}
@@ -0,0 +1,692 @@
+package aicommit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/skrashevich/aicommit/pkg/render"
+)
+
+var commitlintFiles = []string{
+	".commitlintrc",
+	".commitlintrc.json",
+	".commitlintrc.yaml",
+	".commitlintrc.yml",
+	".commitlintrc.js",
+	".commitlintrc.cjs",
+	".commitlintrc.mjs",
+	".commitlintrc.ts",
+	"commitlint.config.js",
+	"commitlint.config.cjs",
+	"commitlint.config.mjs",
+	"commitlint.config.ts",
+	"package.json",
+}
+
+var conventionalTypes = []any{"build", "chore", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test"}
+
+var configConventionalRules = map[string]commitlintRule{
+	"body-leading-blank":     {Level: 1, When: "always"},
+	"body-max-line-length":   {Level: 2, When: "always", Value: 100.0},
+	"footer-leading-blank":   {Level: 1, When: "always"},
+	"footer-max-line-length": {Level: 2, When: "always", Value: 100.0},
+	"header-max-length":      {Level: 2, When: "always", Value: 100.0},
+	"subject-case":           {Level: 2, When: "never", Value: []any{"sentence-case", "start-case", "pascal-case", "upper-case"}},
+	"subject-empty":          {Level: 2, When: "never"},
+	"subject-full-stop":      {Level: 2, When: "never", Value: "."},
+	"type-case":              {Level: 2, When: "always", Value: "lower-case"},
+	"type-empty":             {Level: 2, When: "never"},
+	"type-enum":              {Level: 2, When: "always", Value: conventionalTypes},
+}
+
+type commitlintRule struct {
+	Level int
+	When  string
+	Value any
+}
+
+type commitlintConfig struct {
+	Path  string
+	Rules map[string]commitlintRule
+}
+
+type lintViolation struct {
+	Level   int
+	Rule    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (v lintViolation) severity() string {
+	if v.Level == 1 {
+		return "warning"
+	}
+	return "error"
+}
+
+func (v lintViolation) String() string {
+	return fmt.Sprintf("%d:%d: %s %s: %s", v.Line, v.Column, v.severity(), v.Rule, v.Message)
+}
+
+func loadCommitlint(ctx context.Context, root string) (*commitlintConfig, error) {
+	for _, name := range commitlintFiles {
+		path := filepath.Join(root, name)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		raw, err := decodeCommitlint(name, string(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if raw == nil {
+			continue
+		}
+		cfg, err := newCommitlintConfig(path, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		infof(ctx, "commitlint: loaded %s (%d rules)", path, len(cfg.Rules))
+		return cfg, nil
+	}
+	return nil, nil
+}
+
+func decodeCommitlint(name, data string) (map[string]any, error) {
+	var raw any
+	switch {
+	case name == "package.json":
+		var pkg map[string]any
+		if err := json.Unmarshal([]byte(data), &pkg); err != nil {
+			return nil, err
+		}
+		section, _ := pkg["commitlint"].(map[string]any)
+		return section, nil
+	case strings.HasSuffix(name, ".json"):
+		if err := json.Unmarshal([]byte(data), &raw); err != nil {
+			return nil, err
+		}
+	case strings.HasSuffix(name, ".yaml"), strings.HasSuffix(name, ".yml"):
+		value, err := parseYAML(data)
+		if err != nil {
+			return nil, err
+		}
+		raw = value
+	case name == ".commitlintrc":
+		if err := json.Unmarshal([]byte(data), &raw); err != nil {
+			value, yamlErr := parseYAML(data)
+			if yamlErr != nil {
+				return nil, yamlErr
+			}
+			raw = value
+		}
+	default:
+		converted, err := jsExportToJSON(data)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(converted), &raw); err != nil {
+			return nil, fmt.Errorf("unsupported JavaScript config: %w", err)
+		}
+	}
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		return nil, errors.New("config is not an object")
+	}
+	return obj, nil
+}
+
+func newCommitlintConfig(path string, raw map[string]any) (*commitlintConfig, error) {
+	cfg := &commitlintConfig{Path: path, Rules: map[string]commitlintRule{}}
+	var extends []string
+	switch v := raw["extends"].(type) {
+	case string:
+		extends = []string{v}
+	case []any:
+		for _, item := range v {
+			extends = append(extends, fmt.Sprint(item))
+		}
+	}
+	for _, name := range extends {
+		if strings.Contains(name, "config-conventional") || strings.Contains(name, "config-angular") {
+			for rule, value := range configConventionalRules {
+				cfg.Rules[rule] = value
+			}
+		}
+	}
+	rules, _ := raw["rules"].(map[string]any)
+	for name, value := range rules {
+		items, ok := value.([]any)
+		if !ok || len(items) == 0 {
+			return nil, fmt.Errorf("rule %s: expected [level, when, value]", name)
+		}
+		level, ok := items[0].(float64)
+		if !ok {
+			return nil, fmt.Errorf("rule %s: level must be 0, 1 or 2", name)
+		}
+		rule := commitlintRule{Level: int(level), When: "always"}
+		if len(items) > 1 {
+			rule.When = fmt.Sprint(items[1])
+		}
+		if len(items) > 2 {
+			rule.Value = items[2]
+		}
+		cfg.Rules[name] = rule
+	}
+	return cfg, nil
+}
+
+func (c *commitlintConfig) rule(name string) (commitlintRule, bool) {
+	if c == nil {
+		return commitlintRule{}, false
+	}
+	r, ok := c.Rules[name]
+	return r, ok && r.Level > 0
+}
+
+func (r commitlintRule) strings() []string {
+	switch v := r.Value.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			out = append(out, fmt.Sprint(item))
+		}
+		return out
+	}
+	return nil
+}
+
+func (r commitlintRule) number() int {
+	if n, ok := r.Value.(float64); ok {
+		return int(n)
+	}
+	return 0
+}
+
+var jsExportPattern = regexp.MustCompile(`(?:module\.exports\s*=|export\s+default)\s*`)
+
+var jsSeverity = map[string]string{
+	"RuleConfigSeverity.Disabled": "0",
+	"RuleConfigSeverity.Warning":  "1",
+	"RuleConfigSeverity.Error":    "2",
+}
+
+func jsExportToJSON(src string) (string, error) {
+	loc := jsExportPattern.FindStringIndex(src)
+	if loc == nil {
+		return "", errors.New("no module.exports or export default found")
+	}
+	s := src[loc[1]:]
+	if idx := strings.IndexByte(s, '{'); idx == -1 || strings.TrimSpace(s[:idx]) != "" {
+		return "", errors.New("only object literal exports are supported")
+	}
+	var b strings.Builder
+	depth := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '/' && i+1 < len(s) && s[i+1] == '/':
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(s) && s[i+1] == '*':
+			end := strings.Index(s[i+2:], "*/")
+			if end == -1 {
+				return "", errors.New("unterminated comment")
+			}
+			i += end + 4
+		case c == '"' || c == '\'' || c == '`':
+			end := i + 1
+			var value strings.Builder
+			for end < len(s) && s[end] != c {
+				if s[end] == '\\' && end+1 < len(s) {
+					end++
+				}
+				value.WriteByte(s[end])
+				end++
+			}
+			if end >= len(s) {
+				return "", errors.New("unterminated string")
+			}
+			quoted, _ := json.Marshal(value.String())
+			b.Write(quoted)
+			i = end + 1
+		case c == ',':
+			j := i + 1
+			for j < len(s) && unicode.IsSpace(rune(s[j])) {
+				j++
+			}
+			if j < len(s) && (s[j] == '}' || s[j] == ']') {
+				i++
+				continue
+			}
+			b.WriteByte(c)
+			i++
+		case c == '{' || c == '[':
+			depth++
+			b.WriteByte(c)
+			i++
+		case c == '}' || c == ']':
+			depth--
+			b.WriteByte(c)
+			i++
+			if depth == 0 {
+				return b.String(), nil
+			}
+		case c == '_' || c == '$' || unicode.IsLetter(rune(c)):
+			j := i
+			for j < len(s) && (s[j] == '_' || s[j] == '$' || s[j] == '.' || s[j] == '-' || unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j]))) {
+				j++
+			}
+			word := s[i:j]
+			k := j
+			for k < len(s) && (s[k] == ' ' || s[k] == '\t') {
+				k++
+			}
+			switch {
+			case k < len(s) && s[k] == ':':
+				quoted, _ := json.Marshal(word)
+				b.Write(quoted)
+			case word == "true" || word == "false" || word == "null":
+				b.WriteString(word)
+			case jsSeverity[word] != "":
+				b.WriteString(jsSeverity[word])
+			default:
+				return "", fmt.Errorf("unsupported expression %q", word)
+			}
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return "", errors.New("unterminated object literal")
+}
+
+var conventionalHeaderPattern = regexp.MustCompile(`^((?::\w+:|\p{So})\s+)?(\w+)(?:\(([^)]*)\))?(!)?: (.*)$`)
+
+type parsedMessage struct {
+	Prefix   string
+	Header   string
+	Type     string
+	Scope    string
+	Breaking bool
+	Subject  string
+	Body     []string
+	Footer   []string
+	Blank    bool
+}
+
+func parseCommitMessage(message string) parsedMessage {
+	lines := strings.Split(strings.TrimRight(message, "\n"), "\n")
+	p := parsedMessage{Header: lines[0], Subject: lines[0]}
+	if m := conventionalHeaderPattern.FindStringSubmatch(lines[0]); m != nil {
+		p.Prefix = m[1]
+		p.Type = m[2]
+		p.Scope = m[3]
+		p.Breaking = m[4] != ""
+		p.Subject = m[5]
+	}
+	rest := lines[1:]
+	p.Blank = len(rest) == 0 || strings.TrimSpace(rest[0]) == ""
+	for len(rest) > 0 && strings.TrimSpace(rest[0]) == "" {
+		rest = rest[1:]
+	}
+	rest, p.Footer = render.SplitFooter(rest)
+	for len(rest) > 0 && strings.TrimSpace(rest[len(rest)-1]) == "" {
+		rest = rest[:len(rest)-1]
+	}
+	p.Body = rest
+	return p
+}
+
+func (p parsedMessage) render() string {
+	header := p.Header
+	if p.Type != "" {
+		header = p.Prefix + p.Type
+		if p.Scope != "" {
+			header += "(" + p.Scope + ")"
+		}
+		if p.Breaking {
+			header += "!"
+		}
+		header += ": " + p.Subject
+	}
+	out := header
+	if len(p.Body) > 0 {
+		out += "\n\n" + strings.Join(p.Body, "\n")
+	}
+	if len(p.Footer) > 0 {
+		out += "\n\n" + strings.Join(p.Footer, "\n")
+	}
+	return out
+}
+
+var (
+	camelCasePattern  = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+	kebabCasePattern  = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	snakeCasePattern  = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+	pascalCasePattern = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+	nonAlnumPattern   = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+func matchesCase(s, c string) bool {
+	letters := strings.IndexFunc(s, unicode.IsLetter) != -1
+	switch c {
+	case "lower-case", "lowercase":
+		return s == strings.ToLower(s)
+	case "upper-case", "uppercase":
+		return s == strings.ToUpper(s)
+	case "camel-case":
+		return camelCasePattern.MatchString(s)
+	case "kebab-case":
+		return kebabCasePattern.MatchString(s)
+	case "snake-case":
+		return snakeCasePattern.MatchString(s)
+	case "pascal-case":
+		return pascalCasePattern.MatchString(s)
+	case "sentence-case", "sentencecase":
+		r := []rune(s)
+		return letters && unicode.IsUpper(r[0]) && string(r[1:]) == strings.ToLower(string(r[1:]))
+	case "start-case":
+		for _, word := range strings.Fields(s) {
+			if r := []rune(word); unicode.IsLetter(r[0]) && !unicode.IsUpper(r[0]) {
+				return false
+			}
+		}
+		return letters
+	}
+	return true
+}
+
+func toCase(s, c string) string {
+	switch c {
+	case "lower-case", "lowercase":
+		return strings.ToLower(s)
+	case "upper-case", "uppercase":
+		return strings.ToUpper(s)
+	case "kebab-case":
+		return strings.Trim(nonAlnumPattern.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	case "snake-case":
+		return strings.Trim(nonAlnumPattern.ReplaceAllString(strings.ToLower(s), "_"), "_")
+	case "sentence-case", "sentencecase":
+		r := []rune(strings.ToLower(s))
+		if len(r) > 0 {
+			r[0] = unicode.ToUpper(r[0])
+		}
+		return string(r)
+	}
+	return s
+}
+
+func checkCase(r commitlintRule, s string) bool {
+	cases := r.strings()
+	matched := false
+	for _, c := range cases {
+		if matchesCase(s, c) {
+			matched = true
+			break
+		}
+	}
+	if r.When == "never" {
+		return !matched
+	}
+	return matched || len(cases) == 0
+}
+
+func scopeParts(scope string) []string {
+	return strings.FieldsFunc(scope, func(r rune) bool { return r == ',' || r == '/' || r == ' ' })
+}
+
+func lintMessage(message string, c *commitlintConfig) []lintViolation {
+	p := parseCommitMessage(message)
+	var out []lintViolation
+	add := func(rule commitlintRule, name string, line, col int, format string, args ...any) {
+		out = append(out, lintViolation{Level: rule.Level, Rule: name, Line: line, Column: col, Message: fmt.Sprintf(format, args...)})
+	}
+	typeCol := len([]rune(p.Prefix)) + 1
+	scopeCol := typeCol + len([]rune(p.Type)) + 1
+	subjectCol := len([]rune(p.Header)) - len([]rune(p.Subject)) + 1
+
+	if r, ok := c.rule("header-max-length"); ok && len([]rune(p.Header)) > r.number() {
+		add(r, "header-max-length", 1, r.number()+1, "header must not be longer than %d characters, current length is %d", r.number(), len([]rune(p.Header)))
+	}
+	if r, ok := c.rule("header-min-length"); ok && len([]rune(p.Header)) < r.number() {
+		add(r, "header-min-length", 1, 1, "header must not be shorter than %d characters", r.number())
+	}
+	if r, ok := c.rule("type-empty"); ok && (p.Type == "") == (r.When == "never") {
+		add(r, "type-empty", 1, typeCol, "type may not be empty")
+	}
+	if r, ok := c.rule("type-enum"); ok && p.Type != "" && slices.Contains(r.strings(), p.Type) == (r.When == "never") {
+		add(r, "type-enum", 1, typeCol, "type must %sbe one of [%s]", neverWord(r), strings.Join(r.strings(), ", "))
+	}
+	if r, ok := c.rule("type-case"); ok && p.Type != "" && !checkCase(r, p.Type) {
+		add(r, "type-case", 1, typeCol, "type must %sbe %s", neverWord(r), strings.Join(r.strings(), ", "))
+	}
+	if r, ok := c.rule("scope-empty"); ok && (p.Scope == "") == (r.When == "never") {
+		if r.When == "never" {
+			add(r, "scope-empty", 1, scopeCol, "scope may not be empty")
+		} else {
+			add(r, "scope-empty", 1, scopeCol, "scope must be empty")
+		}
+	}
+	if r, ok := c.rule("scope-enum"); ok && p.Scope != "" {
+		for _, part := range scopeParts(p.Scope) {
+			if slices.Contains(r.strings(), part) == (r.When == "never") {
+				add(r, "scope-enum", 1, scopeCol, "scope must %sbe one of [%s]", neverWord(r), strings.Join(r.strings(), ", "))
+				break
+			}
+		}
+	}
+	if r, ok := c.rule("scope-case"); ok && p.Scope != "" && !checkCase(r, p.Scope) {
+		add(r, "scope-case", 1, scopeCol, "scope must %sbe %s", neverWord(r), strings.Join(r.strings(), ", "))
+	}
+	if r, ok := c.rule("subject-empty"); ok && (strings.TrimSpace(p.Subject) == "") == (r.When == "never") {
+		add(r, "subject-empty", 1, subjectCol, "subject may not be empty")
+	}
+	if r, ok := c.rule("subject-case"); ok && p.Subject != "" && !checkCase(r, p.Subject) {
+		add(r, "subject-case", 1, subjectCol, "subject must %sbe %s", neverWord(r), strings.Join(r.strings(), ", "))
+	}
+	if r, ok := c.rule("subject-full-stop"); ok && p.Subject != "" {
+		stop := "."
+		if s := r.strings(); len(s) > 0 {
+			stop = s[0]
+		}
+		if strings.HasSuffix(p.Subject, stop) == (r.When == "never") {
+			add(r, "subject-full-stop", 1, len([]rune(p.Header)), "subject may %send with %q", map[bool]string{true: "not ", false: ""}[r.When == "never"], stop)
+		}
+	}
+	if r, ok := c.rule("subject-max-length"); ok && len([]rune(p.Subject)) > r.number() {
+		add(r, "subject-max-length", 1, subjectCol+r.number(), "subject must not be longer than %d characters", r.number())
+	}
+	if r, ok := c.rule("body-leading-blank"); ok && len(p.Body) > 0 && !p.Blank && r.When == "always" {
+		add(r, "body-leading-blank", 2, 1, "body must have a leading blank line")
+	}
+	bodyStart := 3
+	if r, ok := c.rule("body-max-line-length"); ok {
+		for i, line := range p.Body {
+			if n := len([]rune(line)); n > r.number() && !strings.Contains(line, "://") {
+				add(r, "body-max-line-length", bodyStart+i, r.number()+1, "body's lines must not be longer than %d characters", r.number())
+			}
+		}
+	}
+	if r, ok := c.rule("body-max-length"); ok && len(strings.Join(p.Body, "\n")) > r.number() {
+		add(r, "body-max-length", bodyStart, 1, "body must not be longer than %d characters", r.number())
+	}
+	footerStart := bodyStart
+	if len(p.Body) > 0 {
+		footerStart += len(p.Body) + 1
+	}
+	if r, ok := c.rule("footer-max-line-length"); ok {
+		for i, line := range p.Footer {
+			if n := len([]rune(line)); n > r.number() && !strings.Contains(line, "://") {
+				add(r, "footer-max-line-length", footerStart+i, r.number()+1, "footer's lines must not be longer than %d characters", r.number())
+			}
+		}
+	}
+	return out
+}
+
+func neverWord(r commitlintRule) string {
+	if r.When == "never" {
+		return "never "
+	}
+	return ""
+}
+
+var typeFallbacks = map[string][]string{
+	"infra":    {"ci", "build", "chore"},
+	"i18n":     {"feat", "chore"},
+	"perf":     {"refactor", "fix"},
+	"style":    {"refactor", "chore"},
+	"revert":   {"fix", "chore"},
+	"docs":     {"chore"},
+	"test":     {"chore"},
+	"ci":       {"build", "chore"},
+	"build":    {"chore"},
+	"refactor": {"chore"},
+}
+
+func fixCommitlint(ctx context.Context, message string, c *commitlintConfig) string {
+	p := parseCommitMessage(message)
+	if p.Type != "" {
+		if r, ok := c.rule("type-enum"); ok && r.When != "never" && len(r.strings()) > 0 && !slices.Contains(r.strings(), p.Type) {
+			allowed := r.strings()
+			replacement := allowed[0]
+			for _, candidate := range append(typeFallbacks[p.Type], "chore", "feat", "fix") {
+				if slices.Contains(allowed, candidate) {
+					replacement = candidate
+					break
+				}
+			}
+			debugf(ctx, "commitlint: type %s is not allowed, using %s", p.Type, replacement)
+			p.Type = replacement
+		}
+		if r, ok := c.rule("type-case"); ok && r.When != "never" && !checkCase(r, p.Type) && len(r.strings()) > 0 {
+			p.Type = toCase(p.Type, r.strings()[0])
+		}
+		if r, ok := c.rule("scope-enum"); ok && r.When != "never" && p.Scope != "" && len(r.strings()) > 0 {
+			var kept []string
+			for _, part := range scopeParts(p.Scope) {
+				if slices.Contains(r.strings(), part) {
+					kept = append(kept, part)
+				}
+			}
+			p.Scope = strings.Join(kept, ",")
+		}
+		if r, ok := c.rule("scope-empty"); ok && r.When == "never" && p.Scope == "" {
+			if enum, ok := c.rule("scope-enum"); ok && enum.When != "never" && len(enum.strings()) > 0 {
+				p.Scope = enum.strings()[0]
+			}
+		}
+		if r, ok := c.rule("scope-case"); ok && r.When != "never" && p.Scope != "" && !checkCase(r, p.Scope) && len(r.strings()) > 0 {
+			p.Scope = toCase(p.Scope, r.strings()[0])
+		}
+	}
+	if r, ok := c.rule("subject-full-stop"); ok && r.When == "never" {
+		stop := "."
+		if s := r.strings(); len(s) > 0 {
+			stop = s[0]
+		}
+		p.Subject = strings.TrimRight(strings.TrimSuffix(p.Subject, stop), " ")
+	}
+	if r, ok := c.rule("subject-case"); ok && p.Subject != "" && !checkCase(r, p.Subject) {
+		if r.When == "never" {
+			if fixed := render.LowerFirst(p.Subject); checkCase(r, fixed) {
+				p.Subject = fixed
+			} else {
+				p.Subject = strings.ToLower(p.Subject)
+			}
+		} else if cases := r.strings(); len(cases) > 0 {
+			p.Subject = toCase(p.Subject, cases[0])
+		}
+	}
+	if p.Type == "" {
+		p.Subject = p.Header
+	}
+	limit := 0
+	if r, ok := c.rule("subject-max-length"); ok {
+		limit = r.number()
+	}
+	if r, ok := c.rule("header-max-length"); ok {
+		header, _, _ := strings.Cut(p.render(), "\n")
+		room := r.number() - (len([]rune(header)) - len([]rune(p.Subject)))
+		if limit == 0 || room < limit {
+			limit = room
+		}
+	}
+	if limit > 0 {
+		p.Subject = render.TrimSubject(p.Subject, limit)
+	}
+	if p.Type == "" {
+		p.Header = p.Subject
+	}
+	if r, ok := c.rule("body-max-line-length"); ok && r.number() > 0 {
+		p.Body = wrapLines(p.Body, r.number())
+	}
+	if r, ok := c.rule("footer-max-line-length"); ok && r.number() > 0 {
+		p.Footer = wrapLines(p.Footer, r.number())
+	}
+	return p.render()
+}
+
+func wrapLines(lines []string, width int) []string {
+	var out []string
+	for _, line := range lines {
+		indent := ""
+		if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") {
+			indent = "  "
+		}
+		for len([]rune(line)) > width && !strings.Contains(line, "://") {
+			runes := []rune(line)
+			cut := strings.LastIndex(string(runes[:width+1]), " ")
+			if cut <= len(indent) {
+				break
+			}
+			out = append(out, strings.TrimRight(line[:cut], " "))
+			line = indent + strings.TrimLeft(line[cut:], " ")
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+func (c *commitlintConfig) promptLines() []string {
+	var out []string
+	if r, ok := c.rule("type-enum"); ok && r.When != "never" {
+		out = append(out, "Allowed types: "+strings.Join(r.strings(), ", ")+".")
+	}
+	if r, ok := c.rule("scope-enum"); ok && r.When != "never" && len(r.strings()) > 0 {
+		out = append(out, "Allowed scopes: "+strings.Join(r.strings(), ", ")+".")
+	}
+	if r, ok := c.rule("scope-empty"); ok && r.When == "never" {
+		out = append(out, "A scope is required.")
+	}
+	if r, ok := c.rule("header-max-length"); ok {
+		out = append(out, fmt.Sprintf("The whole first line must be at most %d characters.", r.number()))
+	}
+	if r, ok := c.rule("subject-case"); ok {
+		out = append(out, fmt.Sprintf("Subject case must %sbe: %s.", neverWord(r), strings.Join(r.strings(), ", ")))
+	}
+	if r, ok := c.rule("subject-full-stop"); ok && r.When == "never" {
+		out = append(out, "Do not end the subject with a period.")
+	}
+	if r, ok := c.rule("body-max-line-length"); ok {
+		out = append(out, fmt.Sprintf("Wrap body lines at %d characters.", r.number()))
+	}
+	return out
+}
@@ -0,0 +1,115 @@
+package aicommit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type loggedCommit struct {
+	SHA     string
+	Message string
+	Parsed  parsedMessage
+}
+
+type commitGroup struct {
+	Type    string
+	Title   string
+	Commits []loggedCommit
+}
+
+var typeTitles = []struct {
+	Type  string
+	Title string
+}{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance"},
+	{"refactor", "Refactoring"},
+	{"revert", "Reverts"},
+	{"docs", "Documentation"},
+	{"test", "Tests"},
+	{"build", "Build"},
+	{"ci", "CI"},
+	{"infra", "Infrastructure"},
+	{"style", "Style"},
+	{"chore", "Chores"},
+}
+
+func logCommits(ctx context.Context, revs ...string) ([]loggedCommit, error) {
+	raw, err := gitOutput(ctx, append([]string{"log", "--format=%H%x00%B%x00"}, revs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("git log %s: %w", strings.Join(revs, " "), err)
+	}
+	var out []loggedCommit
+	parts := strings.Split(raw, "\x00")
+	for i := 0; i+1 < len(parts); i += 2 {
+		message := strings.TrimSpace(parts[i+1])
+		out = append(out, loggedCommit{
+			SHA:     strings.TrimSpace(parts[i]),
+			Message: message,
+			Parsed:  parseCommitMessage(message),
+		})
+	}
+	return out, nil
+}
+
+func groupCommits(commits []loggedCommit) []commitGroup {
+	byType := map[string][]loggedCommit{}
+	for _, c := range commits {
+		byType[c.Parsed.Type] = append(byType[c.Parsed.Type], c)
+	}
+	var out []commitGroup
+	for _, t := range typeTitles {
+		if list := byType[t.Type]; len(list) > 0 {
+			out = append(out, commitGroup{Type: t.Type, Title: t.Title, Commits: list})
+			delete(byType, t.Type)
+		}
+	}
+	var other []loggedCommit
+	for _, c := range commits {
+		if _, ok := byType[c.Parsed.Type]; ok {
+			other = append(other, c)
+		}
+	}
+	if len(other) > 0 {
+		out = append(out, commitGroup{Title: "Other Changes", Commits: other})
+	}
+	return out
+}
+
+func breakingNotes(c loggedCommit) []string {
+	var notes []string
+	for _, line := range c.Parsed.Footer {
+		for _, prefix := range []string{"BREAKING CHANGE: ", "BREAKING-CHANGE: "} {
+			if note, ok := strings.CutPrefix(line, prefix); ok {
+				notes = append(notes, note)
+			}
+		}
+	}
+	if c.Parsed.Breaking && len(notes) == 0 {
+		notes = append(notes, c.Parsed.Subject)
+	}
+	return notes
+}
+
+func issueFooters(commits []loggedCommit) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, c := range commits {
+		for _, line := range c.Parsed.Footer {
+			key, _, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			switch strings.ToLower(key) {
+			case "closes", "fixes", "resolves", "refs":
+				if !seen[line] {
+					seen[line] = true
+					out = append(out, line)
+				}
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,226 @@
+package aicommit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const repoConfigName = ".aicommit.toml"
+
+type configLayer struct {
+	Path     string
+	Values   map[string]string
+	ScopeMap []PathMapping
+	TypeMap  []PathMapping
+	Rules    []Rule
+	Aliases  map[string]string
+}
+
+type config struct {
+	Layers   []configLayer
+	values   map[string]string
+	sources  map[string]string
+	ScopeMap []PathMapping
+	TypeMap  []PathMapping
+	Rules    []Rule
+	Aliases  map[string]string
+}
+
+func loadConfig(ctx context.Context) (*config, error) {
+	cfg := &config{values: map[string]string{}, sources: map[string]string{}, Aliases: map[string]string{}}
+	paths := []string{userConfigPath()}
+	if root, err := gitOutput(ctx, "rev-parse", "--show-toplevel"); err == nil && root != "" {
+		paths = append(paths, filepath.Join(root, repoConfigName))
+	}
+	var layers []configLayer
+	shared := 0
+	for i, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			debugf(ctx, "config: %s not found", path)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		layer, err := parseConfigLayer(path, string(data))
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			layer = restrictLayer(ctx, layer)
+		}
+		infof(ctx, "config: loaded %s (%d keys)", path, len(layer.Values))
+		layers = append(layers, layer)
+		if i == 0 {
+			shared = 1
+		}
+	}
+	if layer, ok := gitConfigLayer(ctx); ok {
+		infof(ctx, "config: loaded git config (%d keys)", len(layer.Values))
+		layers = append(layers, layer)
+	}
+	if src, ttl := styleGuideSource(ctx, layers); src != "" {
+		layer, err := loadStyleGuide(ctx, src, ttl)
+		if err != nil {
+			fmt.Fprintf(stderr(ctx), "warning: style guide %s ignored: %v\n", src, err)
+		} else {
+			layer = restrictLayer(ctx, layer)
+			infof(ctx, "config: loaded style guide %s (%d keys, %d rules)", src, len(layer.Values), len(layer.Rules))
+			layers = append(layers[:shared], append([]configLayer{layer}, layers[shared:]...)...)
+		}
+	}
+	for _, layer := range layers {
+		cfg.add(layer)
+	}
+	sessionOf(ctx).http.configure(cfg)
+	return cfg, nil
+}
+
+func restrictLayer(ctx context.Context, layer configLayer) configLayer {
+	for _, key := range slices.Sorted(maps.Keys(layer.Values)) {
+		if userOnly(key, layer.Values[key]) {
+			fmt.Fprintf(stderr(ctx), "warning: %s: %s can only be set in the user config, git config or environment; ignored\n", layer.Path, key)
+			delete(layer.Values, key)
+		}
+	}
+	if len(layer.Aliases) > 0 {
+		fmt.Fprintf(stderr(ctx), "warning: %s: aliases can only be defined in the user config or git config; ignored\n", layer.Path)
+		layer.Aliases = nil
+	}
+	return layer
+}
+
+func gitConfigLayer(ctx context.Context) (configLayer, bool) {
+	raw, err := gitBytes(ctx, "config", "-z", "--get-regexp", `^aicommit\.`)
+	if err != nil || len(raw) == 0 {
+		return configLayer{}, false
+	}
+	layer := configLayer{Path: "git config", Values: map[string]string{}, Aliases: map[string]string{}}
+	for _, entry := range strings.Split(string(raw), "\x00") {
+		if entry == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(entry, "\n")
+		if alias, ok := strings.CutPrefix(name, "aicommit.alias."); ok {
+			layer.Aliases[alias] = value
+			continue
+		}
+		key := strings.ReplaceAll(strings.TrimPrefix(name, "aicommit."), "-", "_")
+		switch key {
+		case "scope_map":
+			layer.ScopeMap = append(layer.ScopeMap, parseMappings(value)...)
+		case "type_map":
+			layer.TypeMap = append(layer.TypeMap, parseMappings(value)...)
+		default:
+			layer.Values[key] = value
+		}
+	}
+	return layer, true
+}
+
+func parseConfigLayer(path, data string) (configLayer, error) {
+	entries, err := parseTOML(data)
+	if err != nil {
+		return configLayer{}, fmt.Errorf("%s: %w", path, err)
+	}
+	layer := configLayer{Path: path, Values: map[string]string{}, Aliases: map[string]string{}}
+	rules := map[int]map[string]string{}
+	ruleCount := 0
+	for _, e := range entries {
+		table := strings.Join(e.Table, ".")
+		switch {
+		case table == "scope_map":
+			layer.ScopeMap = append(layer.ScopeMap, PathMapping{Pattern: strings.Join(e.Key, "."), Value: tomlString(e.Value)})
+		case table == "type_map":
+			layer.TypeMap = append(layer.TypeMap, PathMapping{Pattern: strings.Join(e.Key, "."), Value: tomlString(e.Value)})
+		case table == "alias":
+			layer.Aliases[strings.Join(e.Key, ".")] = tomlString(e.Value)
+		case table == "rules" && e.Index >= 0:
+			fields, ok := rules[e.Index]
+			if !ok {
+				fields = map[string]string{}
+				rules[e.Index] = fields
+				ruleCount++
+			}
+			fields[strings.Join(e.Key, ".")] = tomlString(e.Value)
+		default:
+			key := strings.Join(append(append([]string{}, e.Table...), e.Key...), ".")
+			value := tomlString(e.Value)
+			if (key == "rules_file" || key == "llm.client_cert" || key == "llm.client_key" || key == "style_guide.url" && !isRemoteSource(value)) && value != "" && !filepath.IsAbs(value) {
+				value = filepath.Join(filepath.Dir(path), value)
+			}
+			layer.Values[key] = value
+		}
+	}
+	for i := 0; i < ruleCount; i++ {
+		rule, err := newRule(rules[i])
+		if err != nil {
+			return configLayer{}, fmt.Errorf("%s: rules[%d]: %w", path, i, err)
+		}
+		layer.Rules = append(layer.Rules, rule)
+	}
+	return layer, nil
+}
+
+func (c *config) add(layer configLayer) {
+	c.Layers = append(c.Layers, layer)
+	for key, value := range layer.Values {
+		c.values[key] = value
+		c.sources[key] = layer.Path
+	}
+	c.ScopeMap = append(append([]PathMapping{}, layer.ScopeMap...), c.ScopeMap...)
+	c.TypeMap = append(append([]PathMapping{}, layer.TypeMap...), c.TypeMap...)
+	c.Rules = append(append([]Rule{}, layer.Rules...), c.Rules...)
+	for name, value := range layer.Aliases {
+		c.Aliases[name] = value
+	}
+}
+
+func (c *config) lookup(key string) (string, string, bool) {
+	if c == nil {
+		return "", "", false
+	}
+	val, ok := c.values[key]
+	return val, c.sources[key], ok
+}
+
+func (c *config) str(key, def string) string {
+	if c == nil {
+		return def
+	}
+	if val, ok := c.values[key]; ok {
+		return val
+	}
+	return def
+}
+
+func (c *config) integer(key string, def int) int {
+	parsed, err := strconv.Atoi(strings.TrimSpace(c.str(key, "")))
+	if err != nil {
+		return def
+	}
+	return parsed
+}
+
+func (c *config) float(key string, def float64) float64 {
+	parsed, err := strconv.ParseFloat(strings.TrimSpace(c.str(key, "")), 64)
+	if err != nil {
+		return def
+	}
+	return parsed
+}
+
+func (c *config) boolean(key string, def bool) bool {
+	if val, ok := parseBool(c.str(key, "")); ok {
+		return val
+	}
+	return def
+}
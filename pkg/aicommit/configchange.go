@@ -0,0 +1,178 @@
+package aicommit
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/skrashevich/aicommit/pkg/detect"
+	"github.com/skrashevich/aicommit/pkg/render"
+)
+
+const bodyConfig BodyMode = "config"
+
+var configKeyRe = regexp.MustCompile(`^\s*"?([A-Za-z0-9_.\-]+)"?\s*[:=]\s*(.*?)\s*,?\s*$`)
+
+type configKeyChange struct {
+	Key    string
+	Before string
+	After  string
+	Added  bool
+	Gone   bool
+}
+
+func allConfig(changes []Change) bool {
+	if len(changes) == 0 {
+		return false
+	}
+	for _, ch := range changes {
+		if detect.CategorizePath(ch.Path) != detect.Config || contractKind(ch.Path) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func configScope(changes []Change) string {
+	var scope string
+	for i, ch := range changes {
+		candidate := detect.TopLevel(ch.Path)
+		if i == 0 {
+			scope = candidate
+			continue
+		}
+		if scope != candidate {
+			return detect.Config
+		}
+	}
+	if scope == "" {
+		return detect.Config
+	}
+	return detect.SanitizeScope(scope)
+}
+
+func collectConfigKeys(diff string) []configKeyChange {
+	byKey := map[string]*configKeyChange{}
+	var order []string
+	get := func(key string) *configKeyChange {
+		kc, ok := byKey[key]
+		if !ok {
+			kc = &configKeyChange{Key: key}
+			byKey[key] = kc
+			order = append(order, key)
+		}
+		return kc
+	}
+	for _, fd := range parseDiffFiles(diff) {
+		if !detect.IsConfigPath(fd.Path) {
+			continue
+		}
+		for _, line := range fd.Removed {
+			if key, value, ok := parseConfigLine(line); ok {
+				get(key).Before = value
+				get(key).Gone = true
+			}
+		}
+		for _, line := range fd.Added {
+			if key, value, ok := parseConfigLine(line); ok {
+				kc := get(key)
+				kc.After = value
+				kc.Added = !kc.Gone
+				kc.Gone = false
+			}
+		}
+	}
+	out := make([]configKeyChange, 0, len(order))
+	for _, key := range order {
+		kc := byKey[key]
+		if !kc.Added && !kc.Gone && kc.Before == kc.After {
+			continue
+		}
+		out = append(out, *kc)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+func parseConfigLine(line string) (string, string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") || strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "- ") {
+		return "", "", false
+	}
+	m := configKeyRe.FindStringSubmatch(trimmed)
+	if len(m) < 3 {
+		return "", "", false
+	}
+	value := strings.TrimSpace(m[2])
+	if value == "{" || value == "[" {
+		value = ""
+	}
+	return m[1], value, true
+}
+
+func configSubject(changes []Change, diff string, lang string) string {
+	keys := collectConfigKeys(diff)
+	if len(keys) == 0 || len(keys) > 3 {
+		target := inferTarget(changes, configScope(changes))
+		if lang == "ru" {
+			return "Обнови настройки " + target
+		}
+		return "Update " + target + " settings"
+	}
+	names := make([]string, 0, len(keys))
+	for _, kc := range keys {
+		names = append(names, kc.Key)
+	}
+	if lang == "ru" {
+		return "Настрой " + strings.Join(names, ", ")
+	}
+	return "Tune " + strings.Join(names, ", ")
+}
+
+func buildConfigLines(changes []Change, diff string, maxItems int, lang string) []string {
+	keys := collectConfigKeys(diff)
+	if len(keys) == 0 {
+		return render.FileLines(changes, maxItems, lang)
+	}
+	limit := len(keys)
+	if maxItems > 0 && limit > maxItems {
+		limit = maxItems
+	}
+	var lines []string
+	for i := 0; i < limit; i++ {
+		lines = append(lines, "- "+describeConfigKey(keys[i], lang))
+	}
+	if limit < len(keys) {
+		remaining := len(keys) - limit
+		if lang == "ru" {
+			lines = append(lines, fmt.Sprintf("- и еще %d", remaining))
+		} else {
+			lines = append(lines, fmt.Sprintf("- and %d more", remaining))
+		}
+	}
+	return lines
+}
+
+func describeConfigKey(kc configKeyChange, lang string) string {
+	if lang == "ru" {
+		switch {
+		case kc.Added:
+			return fmt.Sprintf("добавлен %s = %s", kc.Key, kc.After)
+		case kc.Gone:
+			return fmt.Sprintf("удален %s", kc.Key)
+		default:
+			return fmt.Sprintf("%s: %s -> %s", kc.Key, kc.Before, kc.After)
+		}
+	}
+	switch {
+	case kc.Added:
+		return fmt.Sprintf("add %s = %s", kc.Key, kc.After)
+	case kc.Gone:
+		return fmt.Sprintf("remove %s", kc.Key)
+	default:
+		return fmt.Sprintf("%s: %s -> %s", kc.Key, kc.Before, kc.After)
+	}
+}
@@ -0,0 +1,214 @@
+package aicommit
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type resolvedSetting struct {
+	setting
+	Value  string
+	Source string
+}
+
+func runConfigCommand(ctx context.Context, args []string, cfg *config, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: aicommit config <get|set|list|export|import> ...")
+	}
+	switch args[0] {
+	case "get":
+		return configGet(args[1:], cfg, out)
+	case "set":
+		return configSet(ctx, args[1:])
+	case "list":
+		return configList(args[1:], cfg, out)
+	case "export":
+		return configExport(ctx, args[1:], cfg, out)
+	case "import":
+		return configImport(ctx, args[1:], out)
+	default:
+		return fmt.Errorf("unknown config command: %s", args[0])
+	}
+}
+
+func configGet(args []string, cfg *config, out io.Writer) error {
+	fs := flag.NewFlagSet("config get", flag.ContinueOnError)
+	showOrigin := fs.Bool("show-origin", false, "print the layer the value came from")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: aicommit config get [-show-origin] <key>")
+	}
+	s, ok := findSetting(fs.Arg(0))
+	if !ok {
+		return fmt.Errorf("unknown config key: %s", fs.Arg(0))
+	}
+	r := resolveSetting(s, cfg)
+	if *showOrigin {
+		fmt.Fprintf(out, "%s\t%s\n", r.Source, r.Value)
+		return nil
+	}
+	fmt.Fprintln(out, r.Value)
+	return nil
+}
+
+func configSet(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("config set", flag.ContinueOnError)
+	repo := fs.Bool("repo", false, "write to the repository .aicommit.toml")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return errors.New("usage: aicommit config set [-repo] <key> <value>")
+	}
+	key, value := fs.Arg(0), fs.Arg(1)
+	s, ok := findSetting(key)
+	if !ok {
+		return fmt.Errorf("unknown config key: %s", key)
+	}
+	if err := validateSetting(s, value); err != nil {
+		return err
+	}
+	path := userConfigPath()
+	if *repo {
+		if s.Secret || userOnly(key, value) {
+			return fmt.Errorf("refusing to store %s in the repository config", key)
+		}
+		root, err := gitOutput(ctx, "rev-parse", "--show-toplevel")
+		if err != nil {
+			return errors.New("not a git repository")
+		}
+		path = filepath.Join(root, repoConfigName)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	perm := os.FileMode(0o644)
+	if s.Secret {
+		perm = 0o600
+	}
+	updated := setTOMLValue(string(data), key, tomlLiteral(value))
+	if _, err := parseTOML(updated); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return os.WriteFile(path, []byte(updated), perm)
+}
+
+func configList(args []string, cfg *config, out io.Writer) error {
+	fs := flag.NewFlagSet("config list", flag.ContinueOnError)
+	resolved := fs.Bool("resolved", false, "show final values including defaults and environment")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	for _, s := range settings {
+		r := resolveSetting(s, cfg)
+		if !*resolved && (r.Source == "default" || r.Source == "env "+s.Env) {
+			continue
+		}
+		fmt.Fprintf(out, "%s = %s\t(%s)\n", s.Key, displayValue(r), r.Source)
+	}
+	if len(cfg.ScopeMap) > 0 || len(cfg.TypeMap) > 0 || len(cfg.Rules) > 0 {
+		fmt.Fprintf(out, "# %d scope mappings, %d type mappings, %d rules\n", len(cfg.ScopeMap), len(cfg.TypeMap), len(cfg.Rules))
+	}
+	return nil
+}
+
+func resolveSetting(s setting, cfg *config) resolvedSetting {
+	r := resolvedSetting{setting: s, Value: s.Default, Source: "default"}
+	if value, source, ok := cfg.lookup(s.Key); ok {
+		r.Value = value
+		r.Source = source
+	}
+	if s.Env != "" {
+		if value, name := getenv(s.Env); value != "" {
+			r.Value = value
+			r.Source = "env " + name
+		}
+	}
+	return r
+}
+
+func displayValue(r resolvedSetting) string {
+	if r.Secret && r.Value != "" {
+		return "********"
+	}
+	return r.Value
+}
+
+func setTOMLValue(data, key, literal string) string {
+	section := ""
+	name := key
+	if idx := strings.LastIndex(key, "."); idx != -1 {
+		section = key[:idx]
+		name = key[idx+1:]
+	}
+	assignment := name + " = " + literal
+	lines := strings.Split(strings.TrimRight(data, "\n"), "\n")
+	if data == "" {
+		lines = nil
+	}
+	current := ""
+	sectionEnd := -1
+	firstHeader := -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(stripTOMLComment(line))
+		if strings.HasPrefix(trimmed, "[") {
+			if firstHeader == -1 {
+				firstHeader = i
+			}
+			current = strings.Trim(trimmed, "[] ")
+			if current == section {
+				sectionEnd = i + 1
+			}
+			continue
+		}
+		if current != section {
+			continue
+		}
+		if trimmed != "" {
+			sectionEnd = i + 1
+		}
+		rawKey, _, ok := cutTOMLAssignment(trimmed)
+		if !ok {
+			continue
+		}
+		if keys, err := parseTOMLKey(rawKey); err == nil && len(keys) == 1 && keys[0] == name {
+			lines[i] = assignment
+			return strings.Join(lines, "\n") + "\n"
+		}
+	}
+	switch {
+	case section == "" && firstHeader != -1:
+		insertAt := firstHeader
+		for insertAt > 0 && strings.TrimSpace(lines[insertAt-1]) == "" {
+			insertAt--
+		}
+		insert := []string{assignment}
+		if insertAt == firstHeader {
+			insert = append(insert, "")
+		}
+		lines = append(lines[:insertAt], append(insert, lines[insertAt:]...)...)
+	case section == "" || sectionEnd != -1:
+		if sectionEnd == -1 {
+			sectionEnd = len(lines)
+		}
+		lines = append(lines[:sectionEnd], append([]string{assignment}, lines[sectionEnd:]...)...)
+	default:
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "["+section+"]", assignment)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
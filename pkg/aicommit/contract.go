@@ -0,0 +1,286 @@
+package aicommit
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	protoFieldRe = regexp.MustCompile(`^(?:optional\s+|repeated\s+|required\s+)?([A-Za-z_][A-Za-z0-9_.]*(?:<[^>]*>)?)\s+([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(\d+)`)
+	protoDeclRe  = regexp.MustCompile(`^(message|enum|service|rpc)\s+([A-Za-z_][A-Za-z0-9_]*)`)
+	gqlDeclRe    = regexp.MustCompile(`^(?:extend\s+)?(type|input|enum|interface|union|scalar)\s+([A-Za-z_][A-Za-z0-9_]*)`)
+	gqlFieldRe   = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*(?:\([^)]*\))?\s*:\s*([A-Za-z0-9_!\[\]]+)`)
+	apiPathRe    = regexp.MustCompile(`^"?(/[^"\s]*)"?\s*:`)
+)
+
+func contractKind(path string) string {
+	base := strings.ToLower(filepath.Base(path))
+	ext := strings.ToLower(filepath.Ext(path))
+	switch {
+	case ext == ".proto":
+		return "proto"
+	case ext == ".graphql" || ext == ".gql" || ext == ".graphqls":
+		return "graphql"
+	case (ext == ".yaml" || ext == ".yml" || ext == ".json") && (strings.Contains(base, "openapi") || strings.Contains(base, "swagger")):
+		return "openapi"
+	case strings.HasSuffix(base, ".schema.json"):
+		return "jsonschema"
+	default:
+		return ""
+	}
+}
+
+func findContractBreaks(diff string) []string {
+	var notes []string
+	for _, fd := range parseDiffFiles(diff) {
+		var found []string
+		switch contractKind(fd.Path) {
+		case "proto":
+			found = protoBreaks(fd)
+		case "graphql":
+			found = graphqlBreaks(fd)
+		case "openapi", "jsonschema":
+			found = schemaBreaks(fd)
+		}
+		if len(found) > 0 {
+			notes = append(notes, fd.Path+": "+strings.Join(found, ", "))
+		}
+	}
+	return notes
+}
+
+func protoBreaks(fd fileDiff) []string {
+	type field struct{ typ, name string }
+	removed := map[string]field{}
+	removedDecls := map[string]string{}
+	for _, line := range fd.Removed {
+		content := strings.TrimSpace(line)
+		if m := protoDeclRe.FindStringSubmatch(content); len(m) > 2 {
+			removedDecls[m[2]] = m[1]
+			continue
+		}
+		if m := protoFieldRe.FindStringSubmatch(content); len(m) > 3 {
+			removed[m[3]] = field{typ: m[1], name: m[2]}
+		}
+	}
+	added := map[string]field{}
+	for _, line := range fd.Added {
+		content := strings.TrimSpace(line)
+		if m := protoDeclRe.FindStringSubmatch(content); len(m) > 2 {
+			delete(removedDecls, m[2])
+			continue
+		}
+		if m := protoFieldRe.FindStringSubmatch(content); len(m) > 3 {
+			added[m[3]] = field{typ: m[1], name: m[2]}
+		}
+	}
+	var out []string
+	for name, kind := range removedDecls {
+		out = append(out, fmt.Sprintf("removed %s %s", kind, name))
+	}
+	for num, old := range removed {
+		now, ok := added[num]
+		switch {
+		case !ok:
+			out = append(out, fmt.Sprintf("removed field %s (=%s)", old.name, num))
+		case now.name != old.name:
+			out = append(out, fmt.Sprintf("renamed field %s -> %s (=%s)", old.name, now.name, num))
+		case now.typ != old.typ:
+			out = append(out, fmt.Sprintf("changed type of field %s: %s -> %s", old.name, old.typ, now.typ))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func graphqlBreaks(fd fileDiff) []string {
+	removedDecls := map[string]string{}
+	removedFields := map[string]string{}
+	for _, line := range fd.Removed {
+		content := strings.TrimSpace(line)
+		if m := gqlDeclRe.FindStringSubmatch(content); len(m) > 2 {
+			removedDecls[m[2]] = m[1]
+			continue
+		}
+		if m := gqlFieldRe.FindStringSubmatch(content); len(m) > 2 {
+			removedFields[m[1]] = m[2]
+		}
+	}
+	addedFields := map[string]string{}
+	for _, line := range fd.Added {
+		content := strings.TrimSpace(line)
+		if m := gqlDeclRe.FindStringSubmatch(content); len(m) > 2 {
+			delete(removedDecls, m[2])
+			continue
+		}
+		if m := gqlFieldRe.FindStringSubmatch(content); len(m) > 2 {
+			addedFields[m[1]] = m[2]
+		}
+	}
+	var out []string
+	for name, kind := range removedDecls {
+		out = append(out, fmt.Sprintf("removed %s %s", kind, name))
+	}
+	for name, typ := range removedFields {
+		now, ok := addedFields[name]
+		switch {
+		case !ok:
+			out = append(out, "removed field "+name)
+		case now != typ:
+			out = append(out, fmt.Sprintf("changed type of field %s: %s -> %s", name, typ, now))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func schemaBreaks(fd fileDiff) []string {
+	removedPaths := map[string][]string{}
+	removedOps := map[string]struct{}{}
+	removedKeys := map[string]struct{}{}
+	var block string
+	blockIndent := 0
+	for _, line := range fd.Removed {
+		content := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		key, _, isKey := parseConfigLine(content)
+		if block != "" && indent > blockIndent {
+			if _, ok := removedPaths[block]; ok && isKey && httpMethods[strings.ToLower(key)] {
+				removedPaths[block] = append(removedPaths[block], strings.ToUpper(key))
+			}
+			continue
+		}
+		block = ""
+		if m := apiPathRe.FindStringSubmatch(content); len(m) > 1 {
+			removedPaths[m[1]] = nil
+			block, blockIndent = m[1], indent
+			continue
+		}
+		if !isKey {
+			continue
+		}
+		if httpMethods[strings.ToLower(key)] {
+			removedOps[strings.ToUpper(key)] = struct{}{}
+			block, blockIndent = key, indent
+			continue
+		}
+		if !schemaMetaKey(key) {
+			removedKeys[key] = struct{}{}
+		}
+	}
+	for _, line := range fd.Added {
+		content := strings.TrimSpace(line)
+		if m := apiPathRe.FindStringSubmatch(content); len(m) > 1 {
+			delete(removedPaths, m[1])
+			continue
+		}
+		if key, _, ok := parseConfigLine(content); ok {
+			delete(removedOps, strings.ToUpper(key))
+			delete(removedKeys, key)
+		}
+	}
+	var paths, ops, fields []string
+	for p, methods := range removedPaths {
+		if len(methods) > 0 {
+			p += " (" + strings.Join(methods, ", ") + ")"
+		}
+		paths = append(paths, "removed path "+p)
+	}
+	for op := range removedOps {
+		ops = append(ops, "removed operation "+op)
+	}
+	for key := range removedKeys {
+		fields = append(fields, "removed field "+key)
+	}
+	sort.Strings(paths)
+	sort.Strings(ops)
+	sort.Strings(fields)
+	return append(append(paths, ops...), fields...)
+}
+
+var httpMethods = map[string]bool{
+	"get":     true,
+	"put":     true,
+	"post":    true,
+	"delete":  true,
+	"patch":   true,
+	"head":    true,
+	"options": true,
+	"trace":   true,
+}
+
+func schemaMetaKey(key string) bool {
+	switch key {
+	case "description", "summary", "example", "examples", "title", "$comment", "deprecated", "x-internal":
+		return true
+	default:
+		return false
+	}
+}
+
+func allContracts(changes []Change) bool {
+	if len(changes) == 0 {
+		return false
+	}
+	for _, ch := range changes {
+		if contractKind(ch.Path) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+func contractSubject(changes []Change, diff string, lang string) string {
+	var breaks []string
+	for _, fd := range parseDiffFiles(diff) {
+		switch contractKind(fd.Path) {
+		case "proto":
+			breaks = append(breaks, protoBreaks(fd)...)
+		case "graphql":
+			breaks = append(breaks, graphqlBreaks(fd)...)
+		case "openapi", "jsonschema":
+			breaks = append(breaks, schemaBreaks(fd)...)
+		}
+	}
+	if len(breaks) == 1 {
+		verb, rest, _ := strings.Cut(breaks[0], " ")
+		verbs := map[string][2]string{
+			"removed": {"Remove", "Удали"},
+			"renamed": {"Rename", "Переименуй"},
+			"changed": {"Change", "Измени"},
+		}
+		if v, ok := verbs[verb]; ok {
+			if lang == "ru" {
+				return v[1] + " " + rest
+			}
+			return v[0] + " " + rest
+		}
+	}
+	label := contractLabel(contractKind(changes[0].Path))
+	for _, ch := range changes[1:] {
+		if contractLabel(contractKind(ch.Path)) != label {
+			label = "API"
+			break
+		}
+	}
+	if lang == "ru" {
+		return "Обнови контракт " + label
+	}
+	return "Update " + label + " contract"
+}
+
+func contractLabel(kind string) string {
+	switch kind {
+	case "proto":
+		return "protobuf"
+	case "graphql":
+		return "GraphQL"
+	case "jsonschema":
+		return "JSON Schema"
+	default:
+		return "API"
+	}
+}
@@ -0,0 +1,337 @@
+package aicommit
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/skrashevich/aicommit/pkg/detect"
+)
+
+const nonCodeDominance = 3
+
+func detectType(cs ChangeSet, opts Options) (string, []string, float64) {
+	changes, diff, stats := cs.Changes, cs.Diff, cs.Stats
+	if opts.Type != "" {
+		return strings.ToLower(opts.Type), []string{"type override"}, 1
+	}
+	if t, reason, ok := ruleType(opts.Rules, changes, diff); ok {
+		return t, []string{"rule: " + reason}, 0.9
+	}
+	byPath := statsByPath(stats)
+	counts := map[string]int{}
+	weights := map[string]int{}
+	mapped := map[string]int{}
+	var hasNewCodeFile bool
+	var hasPerfHint bool
+	var hasBenchHint bool
+	var hasRefactorHint bool
+	var hasStyleHint bool
+
+	for _, ch := range changes {
+		weight := lineWeight(byPath, ch.Path)
+		if t, ok := lookupMapping(opts.TypeMap, ch.Path); ok {
+			mapped[strings.ToLower(t)] += weight
+			continue
+		}
+		cat := detect.CategorizePath(ch.Path)
+		if st, ok := byPath[ch.Path]; ok && st.Binary && cat == detect.Code {
+			cat = detect.Assets
+		}
+		counts[cat]++
+		weights[cat] += weight
+		if cat == detect.Code && (ch.Status == "A" || ch.Status == "U" || ch.Status == "C") {
+			hasNewCodeFile = true
+		}
+		lower := strings.ToLower(ch.Path)
+		if strings.Contains(lower, "perf") || strings.Contains(lower, "optimiz") {
+			hasPerfHint = true
+		}
+		if isBenchmarkPath(ch.Path) || isBenchResultPath(ch.Path) {
+			hasBenchHint = true
+		}
+		if strings.Contains(lower, "refactor") || strings.Contains(lower, "cleanup") {
+			hasRefactorHint = true
+		}
+		if strings.Contains(lower, "lint") || strings.Contains(lower, "format") || strings.Contains(lower, "style") {
+			hasStyleHint = true
+		}
+	}
+
+	reasons := []string{}
+	if len(weights)+len(mapped) > 1 {
+		reasons = append(reasons, "line weights: "+formatWeights(weights, mapped))
+	}
+	if allRenames(changes) {
+		reasons = append(reasons, "only renames without content changes")
+		return "refactor", reasons, 0.9
+	}
+	if t, _ := dominantMapped(mapped); t != "" && len(counts) == 0 && len(mapped) == 1 {
+		reasons = append(reasons, "type map")
+		return t, reasons, 0.95
+	}
+	if counts[detect.Code] == 0 && counts[detect.I18n] == len(changes) {
+		reasons = append(reasons, "only translation files")
+		return "feat", reasons, 0.9
+	}
+	if counts[detect.Assets] == len(changes) {
+		reasons = append(reasons, "only binary assets")
+		return "chore", reasons, 0.8
+	}
+	if allContracts(changes) {
+		reasons = append(reasons, "only API contract files")
+		return "feat", reasons, 0.7
+	}
+	if counts[detect.Code] == 0 && counts[detect.Config] == len(changes) {
+		if detect.DiffHasKeyword(diff, []string{"fix", "bug"}) {
+			reasons = append(reasons, "only config files with fix hints")
+			return "fix", reasons, 0.6
+		}
+		reasons = append(reasons, "only config files")
+		return "chore", reasons, 0.8
+	}
+	if hasBenchHint || diffHasBenchmarks(diff) {
+		reasons = append(reasons, "benchmark changes")
+		return "perf", reasons, 0.65
+	}
+	if counts[detect.Code] > 0 && commentOnlyCode(changes, diff) {
+		reasons = append(reasons, "only comments changed in code files")
+		return "docs", reasons, 0.75
+	}
+	if counts[detect.Code] == 0 {
+		t := dominantNonCode(weights)
+		if mt, n := dominantMapped(mapped); n > weights[t] {
+			reasons = append(reasons, "type map")
+			return mt, reasons, 0.75
+		}
+		reasons = append(reasons, "only non-code files")
+		return t, reasons, 0.5 + 0.4*weightShare(weights, t)
+	}
+	if t := dominantNonCode(weights); t != detect.Test && weights[t] > weights[detect.Code]*nonCodeDominance {
+		reasons = append(reasons, t+" outweighs code")
+		return t, reasons, 0.6
+	}
+	if mt, n := dominantMapped(mapped); n > weights[detect.Code]*nonCodeDominance {
+		reasons = append(reasons, "type map")
+		return mt, reasons, 0.6
+	}
+
+	if todos := findResolvedTodos(diff); len(todos) > 0 {
+		reasons = append(reasons, "resolved TODO/FIXME comments")
+		return todoType(todos), reasons, 0.6
+	}
+	if hasPerfHint || detect.DiffHasKeyword(diff, []string{"perf", "optimiz", "speed"}) {
+		reasons = append(reasons, "performance hints")
+		return "perf", reasons, 0.5
+	}
+	if hasRefactorHint || detect.DiffHasKeyword(diff, []string{"refactor", "cleanup", "restructure"}) {
+		reasons = append(reasons, "refactor hints")
+		return "refactor", reasons, 0.5
+	}
+	if hasStyleHint || detect.DiffHasKeyword(diff, []string{"format", "lint", "style"}) {
+		reasons = append(reasons, "style hints")
+		return "style", reasons, 0.5
+	}
+	if hasNewCodeFile || len(detect.FindExportedNames(diff, '+')) > 0 {
+		reasons = append(reasons, "new code or exported symbols")
+		return "feat", reasons, 0.7
+	}
+	reasons = append(reasons, "defaulted to fix")
+	return "fix", reasons, 0.3
+}
+
+func detectBreaking(cs ChangeSet, opts Options) (bool, string, float64) {
+	diff := cs.Diff
+	if opts.Breaking {
+		return true, "", 1
+	}
+	if detect.DiffHasKeyword(diff, []string{"breaking change", "breaking-change"}) {
+		return true, "", 0.9
+	}
+	var notes []string
+	confidence := 0.0
+	if removed := detect.RemovedExportedNames(diff); len(removed) > 0 {
+		notes = append(notes, "removed exported symbols: "+strings.Join(removed, ", "))
+		confidence = math.Max(confidence, 0.75)
+	}
+	if changed := findSignatureChanges(diff); len(changed) > 0 {
+		notes = append(notes, signatureNote(changed))
+		confidence = math.Max(confidence, 0.85)
+	}
+	if contract := findContractBreaks(diff); len(contract) > 0 {
+		notes = append(notes, "api contract: "+strings.Join(contract, "; "))
+		confidence = math.Max(confidence, 0.8)
+	}
+	if len(notes) > 0 {
+		return true, strings.Join(notes, "; "), confidence
+	}
+	if diff == "" {
+		return false, "", 0.5
+	}
+	return false, "", 0.8
+}
+
+func detectScope(cs ChangeSet, opts Options) (string, float64) {
+	changes, diff, root := cs.Changes, cs.Diff, cs.Root
+	if strings.TrimSpace(opts.Scope) != "" {
+		return detect.SanitizeScope(opts.Scope), 1
+	}
+	if len(changes) == 0 {
+		return "", 0.5
+	}
+	if scope, ok := ruleScope(opts.Rules, changes, diff); ok {
+		return detect.SanitizeScope(scope), 0.9
+	}
+	if scope, ok := mappedScope(changes, opts.ScopeMap); ok {
+		return detect.SanitizeScope(scope), 0.95
+	}
+	if scope, ok := packageScope(changes, root); ok {
+		return detect.SanitizeScope(scope), 0.85
+	}
+	if allI18n(changes) {
+		return detect.I18n, 0.9
+	}
+	if allConfig(changes) {
+		return configScope(changes), 0.7
+	}
+	if allAssets(changes) {
+		return detect.Assets, 0.85
+	}
+	if allInfra(changes) {
+		return infraScope(changes), 0.75
+	}
+	if len(changes) == 1 {
+		return detect.SanitizeScope(detect.ScopeFromPath(changes[0].Path)), 0.6
+	}
+
+	var scope string
+	for i, ch := range changes {
+		candidate := detect.TopLevel(ch.Path)
+		if candidate == "" {
+			return "", 0.5
+		}
+		if i == 0 {
+			scope = candidate
+			continue
+		}
+		if scope != candidate {
+			return "", 0.6
+		}
+	}
+	return detect.SanitizeScope(scope), 0.7
+}
+
+func mappedScope(changes []Change, mappings []PathMapping) (string, bool) {
+	if len(mappings) == 0 {
+		return "", false
+	}
+	var scope string
+	for i, ch := range changes {
+		candidate, ok := lookupMapping(mappings, ch.Path)
+		if !ok {
+			return "", false
+		}
+		if i == 0 {
+			scope = candidate
+			continue
+		}
+		if scope != candidate {
+			return "", true
+		}
+	}
+	return scope, true
+}
+
+func dominantNonCode(counts map[string]int) string {
+	order := []string{detect.Docs, detect.Test, detect.CI, detect.Infra, detect.Build, detect.Chore}
+	best := detect.Chore
+	bestCount := -1
+	for _, cat := range order {
+		count := counts[cat]
+		if count > bestCount {
+			bestCount = count
+			best = cat
+		}
+	}
+	if bestCount <= 0 {
+		return "chore"
+	}
+	return best
+}
+
+func statsByPath(stats []FileStat) map[string]FileStat {
+	byPath := make(map[string]FileStat, len(stats))
+	for _, st := range stats {
+		byPath[st.Path] = st
+	}
+	return byPath
+}
+
+func lineWeight(byPath map[string]FileStat, path string) int {
+	st, ok := byPath[path]
+	if !ok || st.Binary {
+		return 1
+	}
+	if weight := st.Added + st.Deleted; weight > 0 {
+		return weight
+	}
+	return 1
+}
+
+func weightShare(weights map[string]int, cat string) float64 {
+	total := 0
+	for _, weight := range weights {
+		total += weight
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(weights[cat]) / float64(total)
+}
+
+func formatWeights(weights, mapped map[string]int) string {
+	var parts []string
+	for cat, weight := range weights {
+		parts = append(parts, fmt.Sprintf("%s=%d", cat, weight))
+	}
+	for t, weight := range mapped {
+		parts = append(parts, fmt.Sprintf("%s(map)=%d", t, weight))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
+
+func dominantMapped(mapped map[string]int) (string, int) {
+	best := ""
+	bestCount := 0
+	for t, count := range mapped {
+		if count > bestCount || (count == bestCount && t < best) {
+			best = t
+			bestCount = count
+		}
+	}
+	return best, bestCount
+}
+
+func commentOnlyCode(changes []Change, diff string) bool {
+	files := parseDiffFiles(diff)
+	for _, ch := range changes {
+		if detect.CategorizePath(ch.Path) != detect.Code {
+			continue
+		}
+		fd, ok := diffForPath(files, ch.Path)
+		if !ok || len(fd.Added)+len(fd.Removed) == 0 {
+			return false
+		}
+		for _, lines := range [][]string{fd.Added, fd.Removed} {
+			for _, line := range lines {
+				content := strings.TrimSpace(line)
+				if content != "" && !detect.IsCommentLine(content) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package aicommit
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/skrashevich/aicommit/pkg/detect"
+)
+
+type fileDiff struct {
+	Path    string
+	Added   []string
+	Removed []string
+}
+
+func parseDiffFiles(diff string) []fileDiff {
+	if diff == "" {
+		return nil
+	}
+	var out []fileDiff
+	var cur *fileDiff
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			out = append(out, fileDiff{Path: pathFromDiffHeader(line)})
+			cur = &out[len(out)-1]
+			continue
+		}
+		if cur == nil || line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "+++ ") {
+			if p := strings.TrimPrefix(line, "+++ "); p != "/dev/null" {
+				cur.Path = strings.TrimPrefix(p, "b/")
+			}
+			continue
+		}
+		if detect.IsDiffHeader(line) {
+			continue
+		}
+		switch line[0] {
+		case '+':
+			cur.Added = append(cur.Added, line[1:])
+		case '-':
+			cur.Removed = append(cur.Removed, line[1:])
+		}
+	}
+	return out
+}
+
+func pathFromDiffHeader(line string) string {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if idx := strings.LastIndex(rest, " b/"); idx != -1 {
+		return rest[idx+3:]
+	}
+	return rest
+}
+
+func diffForPath(files []fileDiff, path string) (fileDiff, bool) {
+	for _, fd := range files {
+		if fd.Path == path {
+			return fd, true
+		}
+	}
+	return fileDiff{}, false
+}
+
+func changesFromDiff(diff string) ([]Change, []FileStat) {
+	var changes []Change
+	var stats []FileStat
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			changes = append(changes, Change{Path: pathFromDiffHeader(line), Status: "M", Source: ModeDiff})
+			stats = append(stats, FileStat{Path: pathFromDiffHeader(line)})
+			continue
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		ch, st := &changes[len(changes)-1], &stats[len(stats)-1]
+		switch {
+		case strings.HasPrefix(line, "new file mode"):
+			ch.Status = "A"
+		case strings.HasPrefix(line, "deleted file mode"):
+			ch.Status = "D"
+		case strings.HasPrefix(line, "rename from "):
+			ch.Status, ch.OldPath = "R", strings.TrimPrefix(line, "rename from ")
+		case strings.HasPrefix(line, "similarity index "):
+			ch.Similarity, _ = strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(line, "similarity index "), "%"))
+		case strings.HasPrefix(line, "Binary files "):
+			st.Binary = true
+		case detect.IsDiffHeader(line):
+		case strings.HasPrefix(line, "+"):
+			st.Added++
+		case strings.HasPrefix(line, "-"):
+			st.Deleted++
+		}
+	}
+	return changes, stats
+}
@@ -0,0 +1,76 @@
+package aicommit
+
+import (
+	"context"
+	"sort"
+
+	"github.com/skrashevich/aicommit/pkg/detect"
+)
+
+const (
+	diffLineBytes   = 40
+	diffHeaderBytes = 160
+	diffBatchSize   = 500
+)
+
+var diffPriority = map[string]int{
+	detect.Code:   0,
+	detect.Test:   1,
+	detect.Config: 2,
+	detect.Infra:  2,
+	detect.CI:     2,
+	detect.I18n:   3,
+	detect.Docs:   3,
+	detect.Chore:  4,
+	detect.Build:  5,
+	detect.Assets: 6,
+}
+
+func selectDiffPaths(ctx context.Context, changes []Change, stats []FileStat, limit int) []string {
+	byPath := statsByPath(stats)
+	type candidate struct {
+		change   Change
+		priority int
+		size     int
+	}
+	var candidates []candidate
+	for _, ch := range changes {
+		st, ok := byPath[ch.Path]
+		if ch.Status == "U" || st.Binary {
+			continue
+		}
+		size := diffHeaderBytes
+		if ok {
+			size += (st.Added + st.Deleted) * diffLineBytes
+		}
+		candidates = append(candidates, candidate{change: ch, priority: diffPriority[detect.CategorizePath(ch.Path)], size: size})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].priority != candidates[j].priority {
+			return candidates[i].priority < candidates[j].priority
+		}
+		return candidates[i].size < candidates[j].size
+	})
+	var paths []string
+	total, selected := 0, 0
+	for _, c := range candidates {
+		if limit > 0 && total >= limit {
+			break
+		}
+		total += c.size
+		selected++
+		if c.change.OldPath != "" {
+			paths = append(paths, c.change.OldPath)
+		}
+		paths = append(paths, c.change.Path)
+	}
+	infof(ctx, "diff: %d of %d files selected (~%d bytes)", selected, len(changes), total)
+	return paths
+}
+
+func needsDiff(opts Options) bool {
+	if opts.LLMEnabled || opts.ChangeID || opts.Explain || opts.Type == "" || opts.Scope == "" {
+		return true
+	}
+	return opts.Body != BodyFiles && opts.Body != BodyStats && opts.Body != BodyNone
+}
@@ -0,0 +1,198 @@
+package aicommit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/skrashevich/aicommit/pkg/llm"
+)
+
+type doctorReport struct {
+	w        io.Writer
+	failures int
+	warnings int
+}
+
+func (r *doctorReport) ok(format string, args ...any) {
+	fmt.Fprintf(r.w, "ok    "+format+"\n", args...)
+}
+
+func (r *doctorReport) warn(format string, args ...any) {
+	r.warnings++
+	fmt.Fprintf(r.w, "warn  "+format+"\n", args...)
+}
+
+func (r *doctorReport) fail(format string, args ...any) {
+	r.failures++
+	fmt.Fprintf(r.w, "fail  "+format+"\n", args...)
+}
+
+func runDoctor(ctx context.Context, out io.Writer) error {
+	r := &doctorReport{w: out}
+	r.ok("build: %s", buildSummary())
+
+	if path, err := exec.LookPath("git"); err != nil {
+		r.fail("git is not available in PATH")
+	} else {
+		r.ok("git: %s", path)
+	}
+	if root, err := gitOutput(ctx, "rev-parse", "--show-toplevel"); err != nil {
+		r.warn("not inside a git repository")
+	} else {
+		r.ok("repository: %s", root)
+	}
+
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		r.fail("config: %v", err)
+		cfg = &config{values: map[string]string{}, sources: map[string]string{}, Aliases: map[string]string{}}
+	}
+	doctorConfig(r, cfg)
+	doctorEnv(r)
+	doctorConflicts(r, cfg)
+
+	if name, ok := availableClipboard(); ok {
+		r.ok("clipboard: %s", name)
+	} else {
+		r.warn("clipboard: no pbcopy, wl-copy, xclip, xsel or clip.exe found and no SSH/tmux session for OSC 52; -copy will fail")
+	}
+
+	switch path, status := hookStatus(ctx, "prepare-commit-msg"); status {
+	case "installed":
+		r.ok("hook: %s", path)
+	case "foreign":
+		r.warn("hook: %s exists but was not installed by aicommit (aicommit hook install -append adds to it)", path)
+	case "missing":
+		r.ok("hook: not installed (run aicommit hook install)")
+	}
+
+	fmt.Fprintf(out, "\n%d failures, %d warnings\n", r.failures, r.warnings)
+	if r.failures > 0 {
+		return fmt.Errorf("doctor found %d problems", r.failures)
+	}
+	return nil
+}
+
+func doctorConfig(r *doctorReport, cfg *config) {
+	if len(cfg.Layers) == 0 {
+		r.ok("config: no config files (using defaults)")
+		return
+	}
+	for _, layer := range cfg.Layers {
+		problems := 0
+		keys := make([]string, 0, len(layer.Values))
+		for key := range layer.Values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			s, ok := findSetting(key)
+			if !ok {
+				r.warn("config %s: unknown key %q", layer.Path, key)
+				problems++
+				continue
+			}
+			if err := validateSetting(s, layer.Values[key]); err != nil {
+				r.fail("config %s: %v", layer.Path, err)
+				problems++
+			}
+			if s.Secret && strings.HasSuffix(layer.Path, repoConfigName) {
+				r.warn("config %s: %s is stored in the repository config", layer.Path, key)
+				problems++
+			}
+		}
+		for name := range layer.Aliases {
+			if _, ok := findCommand(name); ok {
+				r.warn("config %s: alias %q shadows a built-in command and is ignored", layer.Path, name)
+				problems++
+			}
+		}
+		if problems == 0 {
+			r.ok("config: %s (%d keys, %d scope mappings, %d type mappings, %d rules)", layer.Path, len(layer.Values), len(layer.ScopeMap), len(layer.TypeMap), len(layer.Rules))
+		}
+	}
+}
+
+func doctorEnv(r *doctorReport) {
+	known := map[string]bool{"AICOMMIT_CONFIG": true, "AICOMMIT_GITLAB_URL": true, "AICOMMIT_GITEA_URL": true, "AICOMMIT_GITLAB_TOKEN": true, "AICOMMIT_SCOPE_MAP": true, "AICOMMIT_TYPE_MAP": true, "AICOMMIT_SERVE_TOKEN": true}
+	for _, s := range settings {
+		if s.Env != "" {
+			known[s.Env] = true
+		}
+	}
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		current := name
+		if strings.HasPrefix(name, legacyEnvPrefix) {
+			current = envPrefix + strings.TrimPrefix(name, legacyEnvPrefix)
+		} else if !strings.HasPrefix(name, envPrefix) {
+			continue
+		}
+		if !known[current] {
+			r.warn("env: %s is not recognized (misspelled or removed)", name)
+			continue
+		}
+		if current != name {
+			r.warn("env: %s is deprecated, rename it to %s", name, current)
+		}
+		s := settingForEnv(current)
+		if err := validateSetting(s, strings.TrimSpace(value)); err != nil && strings.TrimSpace(value) != "" {
+			r.fail("env %s: %v", name, err)
+		}
+	}
+}
+
+func doctorConflicts(r *doctorReport, cfg *config) {
+	value := func(key string) string {
+		s, _ := findSetting(key)
+		return resolveSetting(s, cfg).Value
+	}
+	enabled := func(key string) bool {
+		v, _ := parseBool(value(key))
+		return v
+	}
+	conflicts := 0
+	if enabled("emoji") && value("format") == string(FormatPlain) {
+		r.warn("conflict: emoji is enabled with plain format")
+		conflicts++
+	}
+	if value("explain_format") == "json" && !enabled("explain") {
+		r.warn("conflict: explain_format is json but explain is disabled")
+		conflicts++
+	}
+	provider := value("llm.provider")
+	if provider != llm.ProviderOpenRouter && (value("llm.referer") != "" || (value("llm.title") != "" && value("llm.title") != "aicommit")) {
+		r.warn("conflict: llm.referer/llm.title only apply to the openrouter provider")
+		conflicts++
+	}
+	if enabled("llm.enabled") {
+		if provider == "" {
+			provider = llm.ProviderOpenAI
+		}
+		if oidc := oidcFromSettings(value); oidc.enabled() {
+			r.ok("llm: %s token from OIDC issuer %s (%s), model %s", provider, oidc.Issuer, oidc.flow(), value("llm.model"))
+		} else if resolveAPIKey(provider, value("llm.key")) == "" {
+			r.fail("llm: enabled but no API key found for %s", provider)
+			conflicts++
+		} else {
+			r.ok("llm: %s key found, model %s", provider, value("llm.model"))
+		}
+	}
+	if conflicts == 0 {
+		r.ok("settings: no conflicts")
+	}
+}
+
+func settingForEnv(env string) setting {
+	for _, s := range settings {
+		if s.Env == env {
+			return s
+		}
+	}
+	return setting{}
+}
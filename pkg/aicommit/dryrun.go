@@ -0,0 +1,61 @@
+package aicommit
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/skrashevich/aicommit/pkg/llm"
+)
+
+func printDryRun(w io.Writer, opts Options, gen *generation) {
+	fmt.Fprintln(w, "dry run: nothing will be sent or changed")
+	fmt.Fprintf(w, "mode: %s (%d files)\n", gen.Mode, len(gen.Changes))
+	for _, c := range gen.Changes {
+		if c.OldPath != "" {
+			fmt.Fprintf(w, "  %s %s -> %s\n", c.Status, c.OldPath, c.Path)
+		} else {
+			fmt.Fprintf(w, "  %s %s\n", c.Status, c.Path)
+		}
+	}
+
+	if opts.LLMEnabled {
+		provider := strings.ToLower(strings.TrimSpace(opts.LLMProvider))
+		if provider == "" {
+			provider = llm.ProviderOpenAI
+		}
+		key := "found"
+		if opts.LLMOIDC.enabled() {
+			key = "from OIDC " + opts.LLMOIDC.flow()
+		} else if resolveAPIKey(provider, opts.LLMKey) == "" {
+			key = "missing"
+		}
+		fmt.Fprintf(w, "llm: would call %s model %s at %s (~%d prompt tokens, up to %d completion tokens, api key %s)\n",
+			provider, opts.LLMModel, llm.Endpoint(provider, opts.LLMEndpoint), gen.PromptTokens, opts.LLMMaxTokens, key)
+	} else {
+		fmt.Fprintln(w, "llm: disabled, heuristic message only")
+	}
+
+	switch {
+	case opts.Commit || opts.Interactive:
+		staging := ""
+		if gen.Mode != ModeStaged {
+			staging = fmt.Sprintf(" after staging %d files", len(gen.Changes))
+		}
+		fmt.Fprintf(w, "commit: would create a commit%s\n", staging)
+	default:
+		fmt.Fprintln(w, "commit: no (message is only printed)")
+	}
+	if opts.Copy {
+		fmt.Fprintln(w, "clipboard: would copy the message")
+	}
+
+	fmt.Fprintln(w, "message:")
+	for _, line := range strings.Split(gen.Message, "\n") {
+		if line == "" {
+			fmt.Fprintln(w)
+			continue
+		}
+		fmt.Fprintln(w, "  "+line)
+	}
+}
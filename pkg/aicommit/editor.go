@@ -0,0 +1,64 @@
+package aicommit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+func resolveEditor(ctx context.Context) string {
+	for _, env := range []string{"GIT_EDITOR", "EDITOR", "VISUAL"} {
+		if value := strings.TrimSpace(os.Getenv(env)); value != "" {
+			return value
+		}
+	}
+	if editor, err := gitOutput(ctx, "var", "GIT_EDITOR"); err == nil && editor != "" {
+		return editor
+	}
+	return "vi"
+}
+
+func editMessage(ctx context.Context, message string) (string, error) {
+	file, err := os.CreateTemp("", "aicommit-*.txt")
+	if err != nil {
+		return "", err
+	}
+	path := file.Name()
+	defer os.Remove(path)
+
+	content := message + "\n\n# Edit the commit message. Lines starting with '#' are ignored.\n# An empty message aborts.\n"
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	cmd := exec.Command("sh", "-c", resolveEditor(ctx)+` "$1"`, "editor", path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t\r"))
+	}
+	edited := strings.TrimSpace(strings.Join(lines, "\n"))
+	if edited == "" {
+		return "", errors.New("empty message, aborting")
+	}
+	return edited, nil
+}
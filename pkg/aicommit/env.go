@@ -0,0 +1,55 @@
+package aicommit
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	envPrefix       = "AICOMMIT_"
+	legacyEnvPrefix = "COMMITGEN_"
+)
+
+func getenv(name string) (string, string) {
+	if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+		return value, name
+	}
+	if !strings.HasPrefix(name, envPrefix) {
+		return "", ""
+	}
+	legacy := legacyEnvPrefix + strings.TrimPrefix(name, envPrefix)
+	value := strings.TrimSpace(os.Getenv(legacy))
+	if value == "" {
+		return "", ""
+	}
+	return value, legacy
+}
+
+func warnLegacyEnv(w io.Writer) {
+	for _, kv := range os.Environ() {
+		legacy, value, _ := strings.Cut(kv, "=")
+		rest, ok := strings.CutPrefix(legacy, legacyEnvPrefix)
+		if !ok || strings.TrimSpace(value) == "" || strings.TrimSpace(os.Getenv(envPrefix+rest)) != "" {
+			continue
+		}
+		fmt.Fprintf(w, "warning: %s* environment variables are deprecated, rename %s to %s\n", legacyEnvPrefix, legacy, envPrefix+rest)
+		return
+	}
+}
+
+func envOrDefault(key, def string) string {
+	if val, _ := getenv(key); val != "" {
+		return val
+	}
+	return def
+}
+
+func envOrBool(key string, def bool) bool {
+	val, _ := getenv(key)
+	if parsed, ok := parseBool(val); ok {
+		return parsed
+	}
+	return def
+}
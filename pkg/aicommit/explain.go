@@ -0,0 +1,55 @@
+package aicommit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type explainInfo struct {
+	Mode               Mode             `json:"mode"`
+	Files              int              `json:"files"`
+	Type               string           `json:"type"`
+	TypeConfidence     float64          `json:"type_confidence"`
+	Reasons            []string         `json:"reasons,omitempty"`
+	Scope              string           `json:"scope,omitempty"`
+	ScopeConfidence    float64          `json:"scope_confidence"`
+	Breaking           bool             `json:"breaking"`
+	BreakingNote       string           `json:"breaking_note,omitempty"`
+	BreakingConfidence float64          `json:"breaking_confidence"`
+	Mixed              []categoryWeight `json:"mixed,omitempty"`
+	LLM                bool             `json:"llm"`
+	LLMRequestID       string           `json:"llm_request_id,omitempty"`
+	Format             Format           `json:"format"`
+	Body               BodyMode         `json:"body"`
+	Lang               string           `json:"lang"`
+}
+
+func printExplain(w io.Writer, info explainInfo, format string) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(info)
+	}
+	fmt.Fprintf(w, "mode: %s (%d files)\n", info.Mode, info.Files)
+	fmt.Fprintf(w, "type: %s (confidence %.2f)\n", info.Type, info.TypeConfidence)
+	if len(info.Reasons) > 0 {
+		fmt.Fprintf(w, "reasons: %s\n", strings.Join(info.Reasons, "; "))
+	}
+	if info.Scope != "" {
+		fmt.Fprintf(w, "scope: %s (confidence %.2f)\n", info.Scope, info.ScopeConfidence)
+	}
+	fmt.Fprintf(w, "breaking: %v (confidence %.2f)\n", info.Breaking, info.BreakingConfidence)
+	if len(info.Mixed) > 0 {
+		fmt.Fprintf(w, "mixed: %s\n", formatCategoryWeights(info.Mixed))
+	}
+	fmt.Fprintf(w, "llm: %v\n", info.LLM)
+	if info.LLMRequestID != "" {
+		fmt.Fprintf(w, "llm request id: %s\n", info.LLMRequestID)
+	}
+	fmt.Fprintf(w, "format: %s\n", info.Format)
+	fmt.Fprintf(w, "body: %s\n", info.Body)
+	fmt.Fprintf(w, "lang: %s\n", info.Lang)
+	return nil
+}
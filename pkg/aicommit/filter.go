@@ -0,0 +1,125 @@
+package aicommit
+
+import "strings"
+
+type globList struct {
+	values []string
+	set    bool
+}
+
+func (g *globList) String() string {
+	return strings.Join(g.values, ",")
+}
+
+func (g *globList) Set(value string) error {
+	if !g.set {
+		g.values = nil
+		g.set = true
+	}
+	g.values = append(g.values, splitList(value)...)
+	return nil
+}
+
+type pathFilter struct {
+	Include []string
+	Exclude []string
+}
+
+func (o Options) filter() pathFilter {
+	return pathFilter{Include: o.Include, Exclude: o.Exclude}
+}
+
+func (f pathFilter) active() bool {
+	return len(f.Include) > 0 || len(f.Exclude) > 0
+}
+
+func (f pathFilter) allows(path string) bool {
+	for _, pattern := range f.Exclude {
+		if matchGlob(pattern, path) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, pattern := range f.Include {
+		if matchGlob(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f pathFilter) changes(changes []Change) []Change {
+	if !f.active() {
+		return changes
+	}
+	var out []Change
+	for _, c := range changes {
+		if f.allows(c.Path) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (f pathFilter) stats(stats []FileStat) []FileStat {
+	if !f.active() {
+		return stats
+	}
+	var out []FileStat
+	for _, st := range stats {
+		if f.allows(st.Path) {
+			out = append(out, st)
+		}
+	}
+	return out
+}
+
+func (f pathFilter) diff(diff string) string {
+	if !f.active() || diff == "" {
+		return diff
+	}
+	var out []string
+	keep := true
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			keep = f.allows(pathFromDiffHeader(line))
+		}
+		if keep {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+func suppressSensitive(patterns []string, diff string) string {
+	if len(patterns) == 0 || diff == "" {
+		return diff
+	}
+	sensitive := pathFilter{Exclude: patterns}
+	var out []string
+	hidden, inHeader := false, false
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			path := pathFromDiffHeader(line)
+			hidden = !sensitive.allows(path)
+			if rest, ok := strings.CutPrefix(line, "diff --git a/"); ok && !hidden {
+				if old, _, ok := strings.Cut(rest, " b/"); ok {
+					hidden = !sensitive.allows(old)
+				}
+			}
+			inHeader = true
+			out = append(out, line)
+			continue
+		}
+		if hidden && inHeader && (strings.HasPrefix(line, "@@") || strings.HasPrefix(line, "Binary files ") || strings.HasPrefix(line, "GIT binary patch")) {
+			inHeader = false
+		}
+		if hidden && !inHeader {
+			continue
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,135 @@
+package aicommit
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	ForgeGitHub    = "github"
+	ForgeGitLab    = "gitlab"
+	ForgeBitbucket = "bitbucket"
+	ForgeGitea     = "gitea"
+)
+
+type forge struct {
+	Kind    string
+	Host    string
+	Project string
+	Web     string
+	API     string
+}
+
+func parseRemoteURL(raw string) (string, string, bool) {
+	raw = strings.TrimSpace(raw)
+	var host, path string
+	if u, err := url.Parse(raw); err == nil && u.Host != "" {
+		host, path = u.Hostname(), u.Path
+	} else if at, rest, ok := strings.Cut(raw, ":"); ok && !strings.Contains(at, "/") {
+		_, host, _ = strings.Cut(at, "@")
+		if host == "" {
+			host = at
+		}
+		path = rest
+	} else {
+		return "", "", false
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || path == "" {
+		return "", "", false
+	}
+	return host, path, true
+}
+
+func forgeKind(host string, hosts []PathMapping) string {
+	for _, m := range hosts {
+		if strings.EqualFold(m.Pattern, host) {
+			return strings.ToLower(m.Value)
+		}
+	}
+	lower := strings.ToLower(host)
+	switch {
+	case lower == "github.com" || host == os.Getenv("GH_HOST") || strings.HasSuffix(lower, ".ghe.com"):
+		return ForgeGitHub
+	case lower == "gitlab.com" || strings.EqualFold(hostOf(envValue("AICOMMIT_GITLAB_URL")), host):
+		return ForgeGitLab
+	case lower == "bitbucket.org":
+		return ForgeBitbucket
+	case lower == "codeberg.org" || strings.EqualFold(hostOf(envValue("AICOMMIT_GITEA_URL")), host):
+		return ForgeGitea
+	}
+	return ""
+}
+
+func envValue(name string) string {
+	value, _ := getenv(name)
+	return value
+}
+
+func hostOf(raw string) string {
+	if u, err := url.Parse(raw); err == nil {
+		return u.Hostname()
+	}
+	return ""
+}
+
+func parseForge(remote string, hosts []PathMapping) (forge, bool) {
+	host, project, ok := parseRemoteURL(remote)
+	if !ok {
+		return forge{}, false
+	}
+	f := forge{Kind: forgeKind(host, hosts), Host: host, Project: project, Web: "https://" + host}
+	switch f.Kind {
+	case ForgeGitHub:
+		f.API = "https://api.github.com"
+		if host != "github.com" {
+			f.API = f.Web + "/api/v3"
+		}
+	case ForgeGitLab:
+		if base := envValue("AICOMMIT_GITLAB_URL"); base != "" && hostOf(base) == host {
+			f.Web = strings.TrimSuffix(base, "/")
+		}
+		f.API = f.Web + "/api/v4"
+	case ForgeBitbucket:
+		f.API = "https://api.bitbucket.org/2.0"
+	case ForgeGitea:
+		if base := envValue("AICOMMIT_GITEA_URL"); base != "" && hostOf(base) == host {
+			f.Web = strings.TrimSuffix(base, "/")
+		}
+		f.API = f.Web + "/api/v1"
+	default:
+		return f, false
+	}
+	return f, true
+}
+
+func detectForge(ctx context.Context, remote string, hosts []PathMapping) (forge, bool) {
+	raw, err := gitOutput(ctx, "remote", "get-url", remote)
+	if err != nil {
+		return forge{}, false
+	}
+	return parseForge(raw, hosts)
+}
+
+func (f forge) repoURL() string {
+	return f.Web + "/" + f.Project
+}
+
+func (f forge) issueURL(num string) string {
+	if f.Kind == ForgeGitLab {
+		return f.repoURL() + "/-/issues/" + num
+	}
+	return f.repoURL() + "/issues/" + num
+}
+
+func (f forge) commitURL(sha string) string {
+	switch f.Kind {
+	case ForgeGitLab:
+		return f.repoURL() + "/-/commit/" + sha
+	case ForgeBitbucket:
+		return f.repoURL() + "/commits/" + sha
+	}
+	return f.repoURL() + "/commit/" + sha
+}
@@ -0,0 +1,24 @@
+package aicommit
+
+import "testing"
+
+func TestForgeKindRequiresKnownHost(t *testing.T) {
+	t.Setenv("AICOMMIT_GITLAB_URL", "")
+	t.Setenv("AICOMMIT_GITEA_URL", "https://git.corp.example")
+	tests := map[string]string{
+		"codeberg.org":            ForgeGitea,
+		"git.corp.example":        ForgeGitea,
+		"gitea.attacker.example":  "",
+		"forgejo.example.net":     "",
+		"gitlab.attacker.example": "",
+		"gitlab.com":              ForgeGitLab,
+	}
+	for host, want := range tests {
+		if got := forgeKind(host, nil); got != want {
+			t.Errorf("forgeKind(%q) = %q, want %q", host, got, want)
+		}
+	}
+	if got := forgeKind("code.internal", []PathMapping{{Pattern: "code.internal", Value: "gitea"}}); got != ForgeGitea {
+		t.Errorf("forgeKind with forge_hosts = %q, want gitea", got)
+	}
+}
@@ -0,0 +1,83 @@
+package aicommit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skrashevich/aicommit/internal/fixture"
+)
+
+func TestGenerateZeroOptions(t *testing.T) {
+	r := fixture.New(t)
+	r.Write("README.md", "# demo\n").Commit("initial commit")
+	r.Write("docs/guide.md", "# guide\n").Stage()
+	enterRepo(t, r)
+
+	m, err := Generate(context.Background(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Type != "docs" || m.Source != "heuristic" || m.Subject == "" {
+		t.Errorf("Generate(Options{}) = %+v", m.Message)
+	}
+}
+
+func TestGenerateRejectsInvalidOptions(t *testing.T) {
+	t.Chdir(fixture.New(t).Dir)
+	if _, err := Generate(context.Background(), Options{Mode: "bogus"}); err == nil {
+		t.Error("Generate with an unknown mode succeeded")
+	}
+}
+
+func enterRepo(t *testing.T, r *fixture.Repo) string {
+	t.Helper()
+	home := t.TempDir()
+	if err := os.WriteFile(filepath.Join(home, ".gitconfig"), []byte("[user]\n\tname = Fixture\n\temail = fixture@example.com\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, ".state"))
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(home, ".gitconfig"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("AICOMMIT_CONFIG", "")
+	t.Setenv("AICOMMIT_LANG", "en")
+	t.Chdir(r.Dir)
+	return home
+}
+
+func TestGenerateDir(t *testing.T) {
+	r := fixture.New(t)
+	r.Write("README.md", "# demo\n").Commit("initial commit")
+	r.Write("docs/guide.md", "# guide\n").Stage()
+	enterRepo(t, r)
+	t.Chdir(t.TempDir())
+
+	m, err := Generate(context.Background(), Options{Dir: r.Dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Type != "docs" {
+		t.Errorf("Generate(Options{Dir}) = %+v", m.Message)
+	}
+}
+
+func TestCollectDiffAllSharesLimit(t *testing.T) {
+	r := fixture.New(t)
+	r.Write("a.txt", "a\n").Write("b.txt", "b\n").Commit("initial commit")
+	r.Write("a.txt", strings.Repeat("staged line\n", 400)).Stage()
+	r.Write("b.txt", strings.Repeat("unstaged line\n", 400))
+	enterRepo(t, r)
+
+	const limit = 2000
+	diff, err := collectDiff(context.Background(), ModeAll, limit, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff) > limit+limit/4 {
+		t.Errorf("collectDiff(ModeAll) returned %d bytes, limit %d", len(diff), limit)
+	}
+}
@@ -0,0 +1,230 @@
+package aicommit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/skrashevich/aicommit/pkg/gitinfo"
+)
+
+func ensureGit() error {
+	_, err := exec.LookPath("git")
+	if err != nil {
+		return errors.New("git is not available in PATH")
+	}
+	return nil
+}
+
+func gitOutput(ctx context.Context, args ...string) (string, error) {
+	out, err := gitBytes(ctx, args...)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+func gitCommand(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = sessionOf(ctx).dir
+	cmd.WaitDelay = gitWaitDelay
+	return cmd
+}
+
+func gitBytes(ctx context.Context, args ...string) ([]byte, error) {
+	start := time.Now()
+	cmd := gitCommand(ctx, args...)
+	out, err := cmd.Output()
+	if err != nil && ctx.Err() != nil {
+		err = fmt.Errorf("git %s: %w", args[0], context.Cause(ctx))
+	}
+	if err != nil {
+		infof(ctx, "git %s (%s): %v", strings.Join(args, " "), since(start), err)
+	} else {
+		infof(ctx, "git %s (%s, %d bytes)", strings.Join(args, " "), since(start), len(out))
+	}
+	return out, err
+}
+
+const maxDiffLine = 4096
+
+const gitWaitDelay = time.Second
+
+func gitDiffBounded(ctx context.Context, limit int, args ...string) (string, error) {
+	counter := progressCounter(ctx)
+	if limit <= 0 {
+		out, err := gitOutput(ctx, args...)
+		if counter != nil {
+			counter.Add(int64(len(out)))
+		}
+		return out, err
+	}
+	start := time.Now()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cmd := gitCommand(ctx, args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	r := bufio.NewReaderSize(stdout, 64<<10)
+	hard := limit + limit/4
+	lineLen, truncated := 0, false
+	for {
+		chunk, err := r.ReadSlice('\n')
+		boundary := lineLen == 0 && (bytes.HasPrefix(chunk, []byte("@@")) || bytes.HasPrefix(chunk, []byte("diff --git ")))
+		if (b.Len() >= limit && boundary) || b.Len() >= hard {
+			truncated = true
+			break
+		}
+		keep := max(0, min(len(chunk), maxDiffLine-lineLen))
+		b.Write(chunk[:keep])
+		if counter != nil {
+			counter.Add(int64(keep))
+		}
+		lineLen += len(chunk)
+		if err == nil {
+			if keep < len(chunk) {
+				b.WriteByte('\n')
+			}
+			lineLen = 0
+		}
+		if err != nil && err != bufio.ErrBufferFull {
+			break
+		}
+	}
+	out := b.Bytes()
+	if truncated {
+		cancel()
+		cmd.Wait()
+		if i := bytes.LastIndexByte(out, '\n'); i != -1 {
+			out = out[:i+1]
+		}
+		infof(ctx, "git %s (%s): diff truncated at %d bytes", strings.Join(args, " "), since(start), len(out))
+		return strings.TrimRight(string(out), "\n"), nil
+	}
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			err = fmt.Errorf("git %s: %w", args[0], context.Cause(ctx))
+		}
+		infof(ctx, "git %s (%s): %v", strings.Join(args, " "), since(start), err)
+		return "", err
+	}
+	infof(ctx, "git %s (%s, %d bytes)", strings.Join(args, " "), since(start), len(out))
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+func collectDiff(ctx context.Context, mode Mode, limit int, paths []string) (string, error) {
+	var base []string
+	switch mode {
+	case ModeStaged:
+		base = []string{"diff", "--cached", "-U0"}
+	case ModeUnstaged:
+		base = []string{"diff", "-U0"}
+	case ModeAll:
+		unstaged, err := collectDiff(ctx, ModeUnstaged, limit, paths)
+		if err != nil {
+			return "", err
+		}
+		if limit > 0 {
+			if limit -= len(unstaged); limit <= 0 {
+				return unstaged, nil
+			}
+		}
+		staged, err := collectDiff(ctx, ModeStaged, limit, paths)
+		if err != nil {
+			return "", err
+		}
+		if unstaged == "" {
+			return staged, nil
+		}
+		if staged == "" {
+			return unstaged, nil
+		}
+		return unstaged + "\n" + staged, nil
+	default:
+		return "", nil
+	}
+	if len(paths) == 0 {
+		return gitDiffBounded(ctx, limit, base...)
+	}
+	var parts []string
+	for start := 0; start < len(paths); start += diffBatchSize {
+		batch := paths[start:min(start+diffBatchSize, len(paths))]
+		out, err := gitDiffBounded(ctx, limit, append(append(base, "--"), batch...)...)
+		if err != nil {
+			return "", err
+		}
+		if out != "" {
+			parts = append(parts, out)
+		}
+		if limit > 0 {
+			if limit -= len(out); limit <= 0 {
+				break
+			}
+		}
+	}
+	return strings.Join(parts, "\n"), nil
+}
+
+func commitChanges(ctx context.Context, message string, mode Mode, changes []Change) error {
+	var paths []string
+	for _, c := range changes {
+		if c.OldPath != "" {
+			paths = append(paths, c.OldPath)
+		}
+		paths = append(paths, c.Path)
+	}
+	only := true
+	if mode == ModeStaged {
+		staged, unstaged, err := gitinfo.CollectChanges(ctx, gitBytes, false)
+		if err != nil {
+			return err
+		}
+		selected := map[string]bool{}
+		for _, p := range paths {
+			selected[p] = true
+		}
+		only = slices.ContainsFunc(staged, func(c Change) bool {
+			return !selected[c.Path] || c.OldPath != "" && !selected[c.OldPath]
+		})
+		if only {
+			for _, c := range unstaged {
+				if selected[c.Path] {
+					return fmt.Errorf("%s is partially staged; stage it fully or use -mode all to commit it without the other staged changes", c.Path)
+				}
+			}
+		}
+	} else {
+		args := append([]string{"add", "-A", "--"}, paths...)
+		infof(ctx, "git %s", strings.Join(args, " "))
+		if out, err := gitCommand(ctx, args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("git add: %s", strings.TrimSpace(string(out)))
+		}
+	}
+	args := []string{"commit", "-F", "-"}
+	if only {
+		args = append(append(args, "--only", "--"), paths...)
+		infof(ctx, "git commit -F - --only (%d paths, %d bytes)", len(paths), len(message))
+	} else {
+		infof(ctx, "git commit -F - (%d bytes)", len(message))
+	}
+	cmd := gitCommand(ctx, args...)
+	cmd.Stdin = strings.NewReader(message + "\n")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git commit: %s", strings.TrimSpace(string(out)))
+	}
+	rememberCommit(ctx)
+	return nil
+}
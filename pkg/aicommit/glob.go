@@ -0,0 +1,75 @@
+package aicommit
+
+import (
+	"path"
+	"strings"
+)
+
+func matchGlob(pattern, name string) bool {
+	pattern = strings.Trim(strings.TrimSpace(pattern), "/")
+	name = strings.Trim(name, "/")
+	if pattern == "" {
+		return false
+	}
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			if len(rest) == 0 {
+				return true
+			}
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(rest, parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		ok, err := path.Match(pattern[0], parts[0])
+		if err != nil || !ok {
+			return false
+		}
+		pattern = pattern[1:]
+		parts = parts[1:]
+	}
+	return len(parts) == 0
+}
+
+func parseMappings(raw string) []PathMapping {
+	var out []PathMapping
+	entries := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == ';' || r == '\n'
+	})
+	for _, entry := range entries {
+		pattern, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		pattern = strings.TrimSpace(pattern)
+		value = strings.TrimSpace(value)
+		if pattern == "" || value == "" {
+			continue
+		}
+		out = append(out, PathMapping{Pattern: pattern, Value: value})
+	}
+	return out
+}
+
+func lookupMapping(mappings []PathMapping, name string) (string, bool) {
+	for _, m := range mappings {
+		if matchGlob(m.Pattern, name) {
+			return m.Value, true
+		}
+	}
+	return "", false
+}
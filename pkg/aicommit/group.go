@@ -0,0 +1,50 @@
+package aicommit
+
+import (
+	"context"
+	"sync"
+)
+
+type taskGroup struct {
+	wg     sync.WaitGroup
+	once   sync.Once
+	err    error
+	cancel context.CancelFunc
+	sem    chan struct{}
+}
+
+func newTaskGroup(ctx context.Context) (*taskGroup, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &taskGroup{cancel: cancel}, ctx
+}
+
+func (g *taskGroup) SetLimit(n int) {
+	if n > 0 {
+		g.sem = make(chan struct{}, n)
+	}
+}
+
+func (g *taskGroup) Go(f func() error) {
+	g.wg.Add(1)
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	go func() {
+		defer g.wg.Done()
+		if g.sem != nil {
+			defer func() { <-g.sem }()
+		}
+		if err := f(); err != nil {
+			g.once.Do(func() {
+				g.err = err
+				g.cancel()
+			})
+		}
+	}()
+}
+
+func (g *taskGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	return g.err
+}
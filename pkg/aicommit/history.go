@@ -0,0 +1,217 @@
+package aicommit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const historyLimit = 500
+
+type historyEntry struct {
+	Time      time.Time `json:"time"`
+	Repo      string    `json:"repo"`
+	Mode      Mode      `json:"mode"`
+	Format    Format    `json:"format"`
+	Lang      string    `json:"lang,omitempty"`
+	Model     string    `json:"model,omitempty"`
+	Paths     []string  `json:"paths,omitempty"`
+	Message   string    `json:"message"`
+	Committed bool      `json:"committed"`
+}
+
+func historyPath() string {
+	return filepath.Join(userStateDir(), "history.jsonl")
+}
+
+func readHistory(ctx context.Context) ([]historyEntry, error) {
+	data, err := os.ReadFile(historyPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []historyEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var e historyEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			debugf(ctx, "history: skipping bad line: %v", err)
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+func writeHistory(entries []historyEntry) error {
+	if len(entries) > historyLimit {
+		entries = entries[len(entries)-historyLimit:]
+	}
+	var b bytes.Buffer
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	path := historyPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b.Bytes(), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func recordHistory(ctx context.Context, opts Options, gen *generation, committed bool) {
+	if !opts.History {
+		return
+	}
+	root, _ := gitOutput(ctx, "rev-parse", "--show-toplevel")
+	entry := historyEntry{
+		Time:      time.Now().UTC().Truncate(time.Second),
+		Repo:      root,
+		Mode:      gen.Mode,
+		Format:    opts.Format,
+		Lang:      opts.Lang,
+		Message:   gen.Message,
+		Committed: committed,
+	}
+	if gen.Explain.LLM {
+		entry.Model = opts.LLMModel
+	}
+	for _, c := range gen.Changes {
+		if c.OldPath != "" {
+			entry.Paths = append(entry.Paths, c.OldPath)
+		}
+		entry.Paths = append(entry.Paths, c.Path)
+	}
+	entries, err := readHistory(ctx)
+	if err == nil {
+		err = writeHistory(append(entries, entry))
+	}
+	if err != nil {
+		fmt.Fprintln(stderr(ctx), "warning: history not saved:", err)
+		return
+	}
+	debugf(ctx, "history: saved entry to %s", historyPath())
+}
+
+func repoHistory(ctx context.Context, entries []historyEntry, all bool) []int {
+	root, _ := gitOutput(ctx, "rev-parse", "--show-toplevel")
+	var idx []int
+	for i := len(entries) - 1; i >= 0; i-- {
+		if all || entries[i].Repo == root {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+func runHistory(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	limit := fs.Int("n", 20, "number of entries to show")
+	all := fs.Bool("all", false, "show entries from all repositories")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr(ctx), "Usage: aicommit history [-n 20] [-all]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	entries, err := readHistory(ctx)
+	if err != nil {
+		return err
+	}
+	idx := repoHistory(ctx, entries, *all)
+	if len(idx) == 0 {
+		fmt.Fprintln(out, "no generated messages yet")
+		return nil
+	}
+	for n, i := range idx {
+		if *limit > 0 && n >= *limit {
+			break
+		}
+		e := entries[i]
+		mark := " "
+		if e.Committed {
+			mark = "✓"
+		}
+		subject, _, _ := strings.Cut(e.Message, "\n")
+		line := fmt.Sprintf("%3d  %s  %s  %s", n+1, e.Time.Local().Format("2006-01-02 15:04"), mark, subject)
+		if *all {
+			line += "  (" + filepath.Base(e.Repo) + ")"
+		}
+		fmt.Fprintln(out, line)
+	}
+	return nil
+}
+
+func runLast(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("last", flag.ContinueOnError)
+	commit := fs.Bool("commit", false, "commit with the recalled message")
+	copyFlag := fs.Bool("copy", false, "copy the recalled message to clipboard")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr(ctx), "Usage: aicommit last [-commit] [-copy] [N]")
+		fmt.Fprintln(stderr(ctx), "N is the entry number from 'aicommit history' (1 is the most recent).")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	n := 1
+	if fs.NArg() > 0 {
+		v, err := strconv.Atoi(fs.Arg(0))
+		if err != nil || v < 1 {
+			return fmt.Errorf("invalid entry number: %s", fs.Arg(0))
+		}
+		n = v
+	}
+	entries, err := readHistory(ctx)
+	if err != nil {
+		return err
+	}
+	idx := repoHistory(ctx, entries, false)
+	if n > len(idx) {
+		return fmt.Errorf("no history entry %d for this repository", n)
+	}
+	e := entries[idx[n-1]]
+	fmt.Fprintln(out, e.Message)
+
+	if *copyFlag {
+		if err := copyToClipboard(e.Message); err != nil {
+			fmt.Fprintln(stderr(ctx), "copy failed:", err)
+		}
+	}
+	if *commit {
+		changes := make([]Change, 0, len(e.Paths))
+		for _, p := range e.Paths {
+			changes = append(changes, Change{Path: p})
+		}
+		if err := commitChanges(ctx, e.Message, e.Mode, changes); err != nil {
+			return err
+		}
+		entries[idx[n-1]].Committed = true
+		if err := writeHistory(entries); err != nil {
+			fmt.Fprintln(stderr(ctx), "warning: history not updated:", err)
+		}
+	}
+	return nil
+}
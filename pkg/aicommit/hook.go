@@ -0,0 +1,198 @@
+package aicommit
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	hookMarker = "installed by aicommit"
+	hookBegin  = "# >>> installed by aicommit"
+	hookEnd    = "# <<< aicommit"
+)
+
+const hookBody = `# Fills the commit message with a generated one when no message was given.
+aicommit=$(command -v aicommit || echo %s)
+exec "$aicommit" hook run "$@"
+`
+
+const preCommitSnippet = `  - repo: https://github.com/skrashevich/aicommit
+    rev: v<version>
+    hooks:
+      - id: aicommit
+        stages: [prepare-commit-msg]`
+
+type hookTarget struct {
+	Path    string
+	Manager string
+}
+
+func hookBlock() string {
+	fallback := "aicommit"
+	if exe, err := os.Executable(); err == nil {
+		fallback = exe
+	}
+	return hookBegin + "\n" + fmt.Sprintf(hookBody, shellQuote(fallback)) + hookEnd + "\n"
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func hookPath(ctx context.Context, name string) (string, error) {
+	path, err := gitOutput(ctx, "rev-parse", "--git-path", "hooks/"+name)
+	if err != nil {
+		return "", errors.New("not a git repository")
+	}
+	return filepath.Abs(path)
+}
+
+func resolveHookTarget(ctx context.Context, name string) (hookTarget, error) {
+	hooksPath, _ := gitOutput(ctx, "config", "core.hooksPath")
+	if strings.Contains(filepath.ToSlash(hooksPath), ".husky") {
+		root, err := gitOutput(ctx, "rev-parse", "--show-toplevel")
+		if err != nil {
+			return hookTarget{}, errors.New("not a git repository")
+		}
+		return hookTarget{Path: filepath.Join(root, ".husky", name), Manager: "husky"}, nil
+	}
+	path, err := hookPath(ctx, name)
+	if err != nil {
+		return hookTarget{}, err
+	}
+	target := hookTarget{Path: path, Manager: "git"}
+	if data, err := os.ReadFile(path); err == nil && strings.Contains(string(data), "File generated by pre-commit") {
+		target.Manager = "pre-commit"
+	}
+	return target, nil
+}
+
+func installHook(ctx context.Context, appendForeign bool) (hookTarget, error) {
+	target, err := resolveHookTarget(ctx, "prepare-commit-msg")
+	if err != nil {
+		return target, err
+	}
+	data, err := os.ReadFile(target.Path)
+	existing := string(data)
+	var content string
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		content = "#!/bin/sh\n" + hookBlock()
+	case err != nil:
+		return target, err
+	case strings.Contains(existing, hookMarker):
+		rest, ok := stripHookBlock(existing)
+		if !ok {
+			content = "#!/bin/sh\n" + hookBlock()
+		} else {
+			content = strings.TrimRight(rest, "\n") + "\n\n" + hookBlock()
+		}
+	case target.Manager == "pre-commit":
+		return target, fmt.Errorf("%s is managed by pre-commit; add aicommit to .pre-commit-config.yaml instead:\n%s", target.Path, preCommitSnippet)
+	case target.Manager == "husky" || appendForeign:
+		content = strings.TrimRight(existing, "\n") + "\n\n" + hookBlock()
+	default:
+		return target, fmt.Errorf("%s already exists and was not installed by aicommit (use -append to add to it)", target.Path)
+	}
+	if err := os.MkdirAll(filepath.Dir(target.Path), 0o755); err != nil {
+		return target, err
+	}
+	if err := os.WriteFile(target.Path, []byte(content), 0o755); err != nil {
+		return target, err
+	}
+	return target, os.Chmod(target.Path, 0o755)
+}
+
+func uninstallHook(ctx context.Context) (hookTarget, error) {
+	target, err := resolveHookTarget(ctx, "prepare-commit-msg")
+	if err != nil {
+		return target, err
+	}
+	data, err := os.ReadFile(target.Path)
+	if err != nil || !strings.Contains(string(data), hookMarker) {
+		return target, fmt.Errorf("no aicommit hook in %s", target.Path)
+	}
+	rest, ok := stripHookBlock(string(data))
+	if leftover := strings.TrimSpace(rest); !ok || leftover == "" || leftover == "#!/bin/sh" {
+		return target, os.Remove(target.Path)
+	}
+	return target, os.WriteFile(target.Path, []byte(strings.TrimRight(rest, "\n")+"\n"), 0o755)
+}
+
+func stripHookBlock(content string) (string, bool) {
+	start := strings.Index(content, hookBegin)
+	if start == -1 {
+		return content, false
+	}
+	end := strings.Index(content[start:], hookEnd)
+	if end == -1 {
+		return content, false
+	}
+	end += start + len(hookEnd)
+	if nl := strings.IndexByte(content[end:], '\n'); nl != -1 {
+		end += nl + 1
+	} else {
+		end = len(content)
+	}
+	return strings.TrimRight(content[:start], "\n") + "\n" + content[end:], true
+}
+
+func hookStatus(ctx context.Context, name string) (string, string) {
+	target, err := resolveHookTarget(ctx, name)
+	if err != nil {
+		return "", "unavailable"
+	}
+	data, err := os.ReadFile(target.Path)
+	if err != nil {
+		return target.Path, "missing"
+	}
+	if strings.Contains(string(data), hookMarker) {
+		return target.Path, "installed"
+	}
+	return target.Path, "foreign"
+}
+
+func runHook(ctx context.Context, args []string, out io.Writer) error {
+	usage := "Usage: aicommit hook install [-append] | uninstall | status | run <commit-msg-file>"
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	if args[0] == "run" {
+		return runHookEntry(ctx, args[1:])
+	}
+	fs := flag.NewFlagSet("hook "+args[0], flag.ContinueOnError)
+	appendForeign := fs.Bool("append", false, "add aicommit to an existing hook that was not installed by aicommit")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	switch args[0] {
+	case "install":
+		target, err := installHook(ctx, *appendForeign)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "installed %s (%s)\n", target.Path, target.Manager)
+	case "uninstall":
+		target, err := uninstallHook(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "removed aicommit from %s\n", target.Path)
+	case "status":
+		target, err := resolveHookTarget(ctx, "prepare-commit-msg")
+		if err != nil {
+			return err
+		}
+		_, status := hookStatus(ctx, "prepare-commit-msg")
+		fmt.Fprintf(out, "prepare-commit-msg: %s\npath: %s\nmanager: %s\n", status, target.Path, target.Manager)
+	default:
+		return errors.New(usage)
+	}
+	return nil
+}
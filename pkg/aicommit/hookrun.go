@@ -0,0 +1,95 @@
+package aicommit
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	commitSources     = []string{"message", "template", "merge", "squash", "commit"}
+	objectNamePattern = regexp.MustCompile(`^[0-9a-f]{7,64}$|^HEAD$`)
+)
+
+func splitHookArgs(args []string) ([]string, string, string, error) {
+	n := len(args)
+	if n >= 2 && args[n-2] == "commit" && objectNamePattern.MatchString(args[n-1]) {
+		n -= 2
+		if n == 0 {
+			return nil, "", "", errors.New("hook run needs the commit message file")
+		}
+		return args[:n-1], args[n-1], "commit", nil
+	}
+	source := ""
+	if n >= 2 && slices.Contains(commitSources, args[n-1]) && !strings.HasPrefix(args[n-2], "-") {
+		source = args[n-1]
+		n--
+	}
+	if n == 0 || strings.HasPrefix(args[n-1], "-") {
+		return nil, "", "", errors.New("hook run needs the commit message file")
+	}
+	return args[:n-1], args[n-1], source, nil
+}
+
+func runHookEntry(ctx context.Context, args []string) error {
+	flags, file, source, err := splitHookArgs(args)
+	if err != nil {
+		fmt.Fprintln(stderr(ctx), "Usage: aicommit hook run [generation options] <commit-msg-file> [source [sha]]")
+		return err
+	}
+	if env := os.Getenv("PRE_COMMIT_COMMIT_MSG_SOURCE"); env != "" {
+		source = env
+	}
+	if source != "" && source != "template" {
+		debugf(ctx, "hook: message source %s, leaving %s alone", source, file)
+		return nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if source == "" && stripCommentLines(string(data)) != "" {
+		debugf(ctx, "hook: %s already has a message", file)
+		return nil
+	}
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	opts, err := parseFlags(ctx, cfg, append([]string{"-mode", "staged"}, flags...))
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+	opts.AssumeYes, opts.Interactive, opts.Edit, opts.Commit, opts.Copy, opts.DryRun = true, false, false, false, false, false
+	opts.Output, opts.Explain = "", false
+	if opts, err = normalizeOptions(ctx, opts); err != nil {
+		return err
+	}
+	st, err := collectState(ctx, opts)
+	if err != nil {
+		debugf(ctx, "hook: %v", err)
+		return nil
+	}
+	gen, err := generateFrom(ctx, opts, st)
+	if err != nil {
+		return fmt.Errorf("aicommit: %w", err)
+	}
+	rest := string(data)
+	if rest != "" && !strings.HasPrefix(rest, "\n") {
+		rest = "\n" + rest
+	}
+	if err := os.WriteFile(file, []byte(gen.Message+"\n"+rest), 0o644); err != nil {
+		return err
+	}
+	recordHistory(ctx, opts, gen, false)
+	infof(ctx, "hook: wrote %s", file)
+	return nil
+}
@@ -0,0 +1,82 @@
+package aicommit
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func (h *httpState) configure(cfg *config) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.client == nil {
+		h.cfg = cfg
+	}
+}
+
+func httpClient(ctx context.Context) *http.Client {
+	s := sessionOf(ctx)
+	if s.offline {
+		return &http.Client{Transport: offlineTransport{stderr: s.stderr}}
+	}
+	h := s.http
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.client == nil {
+		d := layeredDefaults{cfg: h.cfg, s: s}
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.MaxIdleConns = d.integer("http.max_idle_conns")
+		t.MaxIdleConnsPerHost = t.MaxIdleConns
+		t.TLSHandshakeTimeout = durationSetting(d, "http.tls_handshake_timeout")
+		t.IdleConnTimeout = durationSetting(d, "http.idle_conn_timeout")
+		debugf(ctx, "http: max idle conns %d, tls handshake timeout %s, idle timeout %s", t.MaxIdleConns, t.TLSHandshakeTimeout, t.IdleConnTimeout)
+		h.client = &http.Client{Transport: t}
+	}
+	return h.client
+}
+
+func clientCertHTTPClient(ctx context.Context, certFile, keyFile string) (*http.Client, error) {
+	if certFile == "" && keyFile == "" || sessionOf(ctx).offline {
+		return httpClient(ctx), nil
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("mutual TLS needs both -llm-client-cert and -llm-client-key")
+	}
+	base := httpClient(ctx)
+	h := sessionOf(ctx).http
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	id := certFile + "\x00" + keyFile
+	if c, ok := h.clients[id]; ok {
+		return c, nil
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("llm client certificate: %w", err)
+	}
+	t := base.Transport.(*http.Transport).Clone()
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	t.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	debugf(ctx, "http: client certificate %s", certFile)
+	if h.clients == nil {
+		h.clients = map[string]*http.Client{}
+	}
+	h.clients[id] = &http.Client{Transport: t}
+	return h.clients[id], nil
+}
+
+func durationSetting(d layeredDefaults, key string) time.Duration {
+	raw := d.str(key)
+	v, err := time.ParseDuration(raw)
+	if err != nil {
+		s, _ := findSetting(key)
+		fmt.Fprintf(d.s.stderr, "warning: %s %q is not a duration, using %s\n", key, raw, s.Default)
+		v, _ = time.ParseDuration(s.Default)
+	}
+	return v
+}
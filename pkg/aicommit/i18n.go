@@ -0,0 +1,174 @@
+package aicommit
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/skrashevich/aicommit/pkg/detect"
+	"github.com/skrashevich/aicommit/pkg/render"
+)
+
+const bodyLocales BodyMode = "locales"
+
+var localeRe = regexp.MustCompile(`^[a-z]{2}(?:[-_][A-Za-z]{2,4})?$`)
+
+type localeChange struct {
+	Lang    string
+	Files   int
+	Added   int
+	Deleted int
+}
+
+func localeFromPath(path string) string {
+	parts := strings.Split(path, "/")
+	last := len(parts) - 1
+	base := parts[last]
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	if localeRe.MatchString(base) {
+		return normalizeLocale(base)
+	}
+	for i := last - 1; i >= 0; i-- {
+		if localeRe.MatchString(parts[i]) {
+			return normalizeLocale(parts[i])
+		}
+	}
+	if idx := strings.Index(base, "_"); idx != -1 {
+		if suffix := base[idx+1:]; localeRe.MatchString(suffix) {
+			return normalizeLocale(suffix)
+		}
+	}
+	if idx := strings.LastIndex(base, "."); idx != -1 {
+		if suffix := base[idx+1:]; localeRe.MatchString(suffix) {
+			return normalizeLocale(suffix)
+		}
+	}
+	return ""
+}
+
+func normalizeLocale(raw string) string {
+	raw = strings.ReplaceAll(raw, "_", "-")
+	if idx := strings.Index(raw, "-"); idx != -1 {
+		return strings.ToLower(raw[:idx]) + "-" + strings.ToUpper(raw[idx+1:])
+	}
+	return strings.ToLower(raw)
+}
+
+func allI18n(changes []Change) bool {
+	if len(changes) == 0 {
+		return false
+	}
+	for _, ch := range changes {
+		if detect.CategorizePath(ch.Path) != detect.I18n {
+			return false
+		}
+	}
+	return true
+}
+
+func collectLocales(changes []Change) []localeChange {
+	byLang := map[string]*localeChange{}
+	for _, ch := range changes {
+		lang := localeFromPath(ch.Path)
+		if lang == "" {
+			continue
+		}
+		lc, ok := byLang[lang]
+		if !ok {
+			lc = &localeChange{Lang: lang}
+			byLang[lang] = lc
+		}
+		lc.Files++
+		switch ch.Status {
+		case "A", "U", "C":
+			lc.Added++
+		case "D":
+			lc.Deleted++
+		}
+	}
+	out := make([]localeChange, 0, len(byLang))
+	for _, lc := range byLang {
+		out = append(out, *lc)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Lang < out[j].Lang
+	})
+	return out
+}
+
+func i18nSubject(changes []Change, lang string) string {
+	var added, updated []string
+	for _, lc := range collectLocales(changes) {
+		if lc.Added == lc.Files {
+			added = append(added, lc.Lang)
+		} else {
+			updated = append(updated, lc.Lang)
+		}
+	}
+	if lang == "ru" {
+		switch {
+		case len(added) > 0:
+			return "Добавь переводы: " + strings.Join(added, ", ")
+		case len(updated) > 0:
+			return "Обнови переводы: " + strings.Join(updated, ", ")
+		default:
+			return "Обнови переводы"
+		}
+	}
+	switch {
+	case len(added) > 0:
+		return "Add " + strings.Join(added, ", ") + " translations"
+	case len(updated) > 0:
+		return "Update " + strings.Join(updated, ", ") + " translations"
+	default:
+		return "Update translations"
+	}
+}
+
+func buildLocaleLines(changes []Change, maxItems int, lang string) []string {
+	locales := collectLocales(changes)
+	if len(locales) == 0 {
+		return render.FileLines(changes, maxItems, lang)
+	}
+	limit := len(locales)
+	if maxItems > 0 && limit > maxItems {
+		limit = maxItems
+	}
+	var lines []string
+	for i := 0; i < limit; i++ {
+		lc := locales[i]
+		lines = append(lines, fmt.Sprintf("- %s (%s)", lc.Lang, localeStatus(lc, lang)))
+	}
+	if limit < len(locales) {
+		remaining := len(locales) - limit
+		if lang == "ru" {
+			lines = append(lines, fmt.Sprintf("- и еще %d", remaining))
+		} else {
+			lines = append(lines, fmt.Sprintf("- and %d more", remaining))
+		}
+	}
+	return lines
+}
+
+func localeStatus(lc localeChange, lang string) string {
+	if lang == "ru" {
+		switch {
+		case lc.Added == lc.Files:
+			return "добавлен"
+		case lc.Deleted == lc.Files:
+			return "удален"
+		default:
+			return "обновлен"
+		}
+	}
+	switch {
+	case lc.Added == lc.Files:
+		return "added"
+	case lc.Deleted == lc.Files:
+		return "removed"
+	default:
+		return "updated"
+	}
+}
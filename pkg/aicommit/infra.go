@@ -0,0 +1,73 @@
+package aicommit
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/skrashevich/aicommit/pkg/detect"
+)
+
+var infraGenericDirs = map[string]bool{
+	"deploy":       true,
+	"deployment":   true,
+	"deployments":  true,
+	"docker":       true,
+	"templates":    true,
+	"environments": true,
+	"envs":         true,
+	"env":          true,
+	"base":         true,
+	"overlays":     true,
+	"modules":      true,
+	"tasks":        true,
+	"handlers":     true,
+	"vars":         true,
+	"defaults":     true,
+	"group_vars":   true,
+	"host_vars":    true,
+	"inventory":    true,
+	"tf":           true,
+}
+
+func allInfra(changes []Change) bool {
+	if len(changes) == 0 {
+		return false
+	}
+	for _, ch := range changes {
+		if detect.CategorizePath(ch.Path) != detect.Infra {
+			return false
+		}
+	}
+	return true
+}
+
+func infraScope(changes []Change) string {
+	var scope string
+	for i, ch := range changes {
+		candidate := infraTarget(ch.Path)
+		if i == 0 {
+			scope = candidate
+			continue
+		}
+		if scope != candidate {
+			return ""
+		}
+	}
+	return detect.SanitizeScope(scope)
+}
+
+func infraTarget(path string) string {
+	parts := strings.Split(path, "/")
+	for _, part := range parts[:len(parts)-1] {
+		lower := strings.ToLower(part)
+		if detect.IsInfraDir(lower) || infraGenericDirs[lower] {
+			continue
+		}
+		return lower
+	}
+	base := strings.ToLower(filepath.Base(path))
+	if base == "dockerfile" || strings.HasPrefix(base, "dockerfile.") || strings.HasPrefix(base, "docker-compose") {
+		return "docker"
+	}
+	return ""
+}
@@ -0,0 +1,174 @@
+package aicommit
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/skrashevich/aicommit/pkg/llm"
+)
+
+func runInit(ctx context.Context, args []string, in io.Reader, out io.Writer) error {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	var assumeYes bool
+	fs.BoolVar(&assumeYes, "yes", envOrBool("AICOMMIT_YES", false), "accept defaults without prompting")
+	fs.BoolVar(&assumeYes, "no-input", envOrBool("AICOMMIT_YES", false), "alias for -yes")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	p := newPrompter(in, out)
+	p.assumeYes = assumeYes
+	fmt.Fprintln(out, "aicommit setup")
+	fmt.Fprintln(out)
+
+	values := map[string]string{}
+	format, err := p.choose("Commit format", []string{string(FormatConventional), string(FormatPlain), string(FormatGitmoji)}, string(FormatConventional))
+	if err != nil {
+		return err
+	}
+	values["format"] = format
+	lang, err := p.choose("Message language", []string{"auto", "en", "ru"}, "auto")
+	if err != nil {
+		return err
+	}
+	values["lang"] = lang
+
+	useLLM, err := p.confirm("Use an LLM to write messages", true)
+	if err != nil {
+		return err
+	}
+	keyStorage := "none"
+	if useLLM {
+		values["llm.enabled"] = "true"
+		provider, err := p.choose("LLM provider", []string{llm.ProviderOpenAI, llm.ProviderOpenRouter}, llm.ProviderOpenAI)
+		if err != nil {
+			return err
+		}
+		values["llm.provider"] = provider
+		model, err := p.ask("Model", "gpt-5-nano")
+		if err != nil {
+			return err
+		}
+		values["llm.model"] = model
+		keyStorage, err = p.choose("Where should the API key come from", []string{"env", "config"}, "env")
+		if err != nil {
+			return err
+		}
+		if keyStorage == "config" {
+			key, err := p.secret("API key")
+			if err != nil {
+				return err
+			}
+			values["llm.key"] = key
+		}
+	}
+
+	target := "user"
+	root, rootErr := gitOutput(ctx, "rev-parse", "--show-toplevel")
+	if rootErr == nil && keyStorage != "config" {
+		target, err = p.choose("Write settings to", []string{"user", "repo"}, "user")
+		if err != nil {
+			return err
+		}
+	}
+	path := userConfigPath()
+	if target == "repo" {
+		path = filepath.Join(root, repoConfigName)
+	}
+	if _, err := os.Stat(path); err == nil {
+		overwrite, err := p.confirm(path+" exists, overwrite", false)
+		if err != nil {
+			return err
+		}
+		if !overwrite {
+			return fmt.Errorf("aborted: %s left unchanged", path)
+		}
+	}
+	if err := writeConfigFile(path, values); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "wrote %s\n", path)
+
+	if rootErr == nil {
+		install, err := p.confirm("Install prepare-commit-msg hook in this repository", false)
+		if err != nil {
+			return err
+		}
+		if install {
+			hook, err := installHook(ctx, false)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "installed %s\n", hook.Path)
+		}
+	}
+	if useLLM && keyStorage == "env" {
+		env := "OPENAI_API_KEY"
+		if values["llm.provider"] == llm.ProviderOpenRouter {
+			env = "OPENROUTER_API_KEY"
+		}
+		fmt.Fprintf(out, "set %s (or AICOMMIT_LLM_KEY) in your shell profile to provide the API key\n", env)
+	}
+	return nil
+}
+
+func writeConfigFile(path string, values map[string]string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	perm := os.FileMode(0o644)
+	if _, ok := values["llm.key"]; ok {
+		perm = 0o600
+	}
+	return os.WriteFile(path, []byte(renderConfig(values)), perm)
+}
+
+func renderConfig(values map[string]string) string {
+	sections := map[string][]string{}
+	for key := range values {
+		section := ""
+		if idx := strings.LastIndex(key, "."); idx != -1 {
+			section = key[:idx]
+		}
+		sections[section] = append(sections[section], key)
+	}
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		keys := sections[name]
+		sort.Strings(keys)
+		if name != "" {
+			if b.Len() > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "[%s]\n", name)
+		}
+		for _, key := range keys {
+			fmt.Fprintf(&b, "%s = %s\n", strings.TrimPrefix(key, name+"."), tomlLiteral(values[key]))
+		}
+	}
+	return b.String()
+}
+
+func tomlLiteral(value string) string {
+	if value == "true" || value == "false" {
+		return value
+	}
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return value
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	return strconv.Quote(value)
+}
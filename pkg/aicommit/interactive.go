@@ -0,0 +1,136 @@
+package aicommit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+var bodyCycle = []BodyMode{BodyAuto, BodyFiles, BodyStats, BodySummary, BodyNone}
+
+func runInteractive(ctx context.Context, opts Options, gen *generation, in io.Reader, out io.Writer) error {
+	p := newPrompter(in, out)
+	for {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, strings.Repeat("─", 60))
+		fmt.Fprintln(out, gen.Message)
+		fmt.Fprintln(out, strings.Repeat("─", 60))
+		fmt.Fprintf(out, "[a]ccept  [e]dit  [r]egenerate  [b]ody: %s  [q]uit > ", opts.Body)
+
+		key, err := p.key()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out)
+		switch key {
+		case 'a', 'y', '\n', '\r':
+			if err := commitChanges(ctx, gen.Message, gen.Mode, gen.Changes); err != nil {
+				recordHistory(ctx, opts, gen, false)
+				return err
+			}
+			recordHistory(ctx, opts, gen, true)
+			notifyCommit(ctx, opts, gen)
+			subject, _, _ := strings.Cut(gen.Message, "\n")
+			fmt.Fprintln(out, "committed:", subject)
+			return nil
+		case 'e':
+			message, err := editInline(p, gen.Message)
+			if err != nil {
+				return err
+			}
+			gen.Message = message
+		case 'r':
+			next, err := generate(ctx, opts)
+			if err != nil {
+				fmt.Fprintln(out, "regenerate failed:", err)
+				continue
+			}
+			gen = next
+		case 'b':
+			opts.Body = nextBodyMode(opts.Body)
+			next, err := generate(ctx, opts)
+			if err != nil {
+				fmt.Fprintln(out, "regenerate failed:", err)
+				continue
+			}
+			gen = next
+		case 'q', 'n', 3, 27:
+			recordHistory(ctx, opts, gen, false)
+			return errors.New("aborted")
+		}
+	}
+}
+
+func nextBodyMode(current BodyMode) BodyMode {
+	for i, mode := range bodyCycle {
+		if mode == current {
+			return bodyCycle[(i+1)%len(bodyCycle)]
+		}
+	}
+	return BodyAuto
+}
+
+func editInline(p *prompter, message string) (string, error) {
+	subject, body, _ := strings.Cut(message, "\n")
+	subject, err := p.ask("Subject", subject)
+	if err != nil {
+		return message, err
+	}
+	edit, err := p.confirm("Replace body", false)
+	if err != nil {
+		return message, err
+	}
+	if !edit {
+		return strings.TrimRight(subject+"\n"+body, "\n"), nil
+	}
+	fmt.Fprintln(p.out, "Enter the new body, finish with a line containing a single '.':")
+	var lines []string
+	for {
+		line, err := p.in.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if line == "." || (err != nil && line == "") {
+			break
+		}
+		lines = append(lines, line)
+		if err != nil {
+			break
+		}
+	}
+	body = strings.TrimSpace(strings.Join(lines, "\n"))
+	if body == "" {
+		return subject, nil
+	}
+	return subject + "\n\n" + body, nil
+}
+
+func (p *prompter) key() (byte, error) {
+	if stty, err := exec.LookPath("stty"); err == nil {
+		save := exec.Command(stty, "-g")
+		save.Stdin = os.Stdin
+		if state, err := save.Output(); err == nil {
+			raw := exec.Command(stty, "-icanon", "-echo", "min", "1")
+			raw.Stdin = os.Stdin
+			if raw.Run() == nil {
+				defer func() {
+					restore := exec.Command(stty, strings.TrimSpace(string(state)))
+					restore.Stdin = os.Stdin
+					_ = restore.Run()
+				}()
+				return p.in.ReadByte()
+			}
+		}
+	}
+	line, err := p.in.ReadString('\n')
+	if err != nil && line == "" {
+		return 0, err
+	}
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return '\n', nil
+	}
+	return line[0], nil
+}
@@ -0,0 +1,216 @@
+package aicommit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	issueRefPattern    = regexp.MustCompile(`^#?(\d+)$`)
+	issueURLPattern    = regexp.MustCompile(`^https?://\S+/(?:issues/(\d+)|browse/([A-Z][A-Z0-9]+-\d+))$`)
+	branchIssuePattern = regexp.MustCompile(`(?i)(?:^|/)(?:issue-|issues-|gh-)?(\d+)(?:[-_]|$)`)
+)
+
+func currentBranch(ctx context.Context) string {
+	branch, err := gitOutput(ctx, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil || branch == "HEAD" {
+		return ""
+	}
+	return branch
+}
+
+func branchIssue(branch string) string {
+	if m := branchIssuePattern.FindStringSubmatch(branch); m != nil {
+		return "#" + m[1]
+	}
+	return ""
+}
+
+func issueID(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if m := issueRefPattern.FindStringSubmatch(ref); m != nil {
+		return "#" + m[1]
+	}
+	if item := azureWorkItem(ref); item != "" {
+		return item
+	}
+	if m := issueURLPattern.FindStringSubmatch(ref); m != nil {
+		if m[1] != "" {
+			return "#" + m[1]
+		}
+		return m[2]
+	}
+	if jiraKeyPattern.MatchString(ref) {
+		return strings.ToUpper(ref)
+	}
+	return ""
+}
+
+func forgeToken(kind string) string {
+	var names []string
+	switch kind {
+	case ForgeGitHub:
+		names = []string{"GH_TOKEN", "GITHUB_TOKEN"}
+	case ForgeGitLab:
+		return gitLabToken()
+	case ForgeGitea:
+		names = []string{"GITEA_TOKEN", "FORGEJO_TOKEN"}
+	}
+	for _, name := range names {
+		if token := strings.TrimSpace(os.Getenv(name)); token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+func resolveIssueTitles(ctx context.Context, refs []string, hosts []PathMapping) map[string]string {
+	f, ok := detectForge(ctx, "origin", hosts)
+	if !ok || f.Kind == ForgeBitbucket {
+		return nil
+	}
+	token := forgeToken(f.Kind)
+	if token == "" {
+		return nil
+	}
+	titles := map[string]string{}
+	for _, ref := range refs {
+		id := issueID(ref)
+		if !strings.HasPrefix(id, "#") || titles[id] != "" {
+			continue
+		}
+		title, err := fetchIssueTitle(ctx, f, id[1:], token)
+		if err != nil {
+			fmt.Fprintf(stderr(ctx), "warning: issue %s: %v\n", id, err)
+			continue
+		}
+		titles[id] = title
+	}
+	return titles
+}
+
+func fetchIssueTitle(ctx context.Context, f forge, num, token string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	endpoint := f.API + "/repos/" + f.Project + "/issues/" + num
+	if f.Kind == ForgeGitLab {
+		endpoint = f.API + "/projects/" + url.PathEscape(f.Project) + "/issues/" + num
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	switch f.Kind {
+	case ForgeGitHub:
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("Authorization", "Bearer "+token)
+	case ForgeGitLab:
+		req.Header.Set("PRIVATE-TOKEN", token)
+	default:
+		req.Header.Set("Authorization", "token "+token)
+	}
+	start := time.Now()
+	resp, err := httpClient(ctx).Do(req)
+	if err != nil {
+		infof(ctx, "%s: request failed after %s: %v", f.Kind, since(start), err)
+		return "", err
+	}
+	defer resp.Body.Close()
+	infof(ctx, "%s: GET issue #%s: http %d in %s", f.Kind, num, resp.StatusCode, since(start))
+	if resp.StatusCode >= 300 {
+		payload, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("%s http %d: %s", f.Kind, resp.StatusCode, strings.TrimSpace(string(payload)))
+	}
+	var issue struct {
+		Title string `json:"title"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&issue); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(issue.Title), nil
+}
+
+func issueFooterLines(keyword string, refs []string, titles map[string]string, withTitles bool) []string {
+	if len(refs) == 0 {
+		return nil
+	}
+	var lines, plain []string
+	for _, ref := range refs {
+		if item := azureWorkItem(ref); item != "" && keyword == "Closes" {
+			lines = append(lines, "Fixes "+item)
+			continue
+		}
+		if title := titles[issueID(ref)]; withTitles && title != "" {
+			lines = append(lines, fmt.Sprintf("%s %s: %s", keyword, issueID(ref), title))
+			continue
+		}
+		plain = append(plain, ref)
+	}
+	if len(plain) > 0 {
+		lines = append([]string{fmt.Sprintf("%s: %s", keyword, strings.Join(plain, ", "))}, lines...)
+	}
+	return lines
+}
+
+func resolveIssueRefs(ctx context.Context, opts Options) Options {
+	branch := currentBranch(ctx)
+	if opts.AzureBoards != "" {
+		opts = resolveAzureRefs(opts, branch)
+	} else if opts.BranchRefs && len(opts.Refs) == 0 && len(opts.Closes) == 0 {
+		if ref := branchIssue(branch); ref != "" {
+			opts.Refs = []string{ref}
+		}
+	}
+	jira := ""
+	if opts.JiraURL != "" {
+		jira = jiraKey(branch)
+	}
+	refs := append(append([]string{}, opts.Refs...), opts.Closes...)
+	if jira != "" && !slices.ContainsFunc(refs, func(ref string) bool { return issueID(ref) == jira }) {
+		opts.Refs = append(opts.Refs, jira)
+	}
+	if opts.LinkRefs {
+		opts.Refs, opts.Closes = linkRefs(ctx, opts, opts.Refs), linkRefs(ctx, opts, opts.Closes)
+	}
+	if opts.DryRun || (!opts.LLMEnabled && !opts.IssueTitles) {
+		return opts
+	}
+	opts.Issues = resolveIssueTitles(ctx, refs, opts.ForgeHosts)
+	if jira != "" && opts.JiraToken != "" {
+		summary, err := fetchJiraSummary(ctx, opts, jira)
+		if err != nil {
+			fmt.Fprintf(stderr(ctx), "warning: jira %s: %v\n", jira, err)
+		} else if summary != "" {
+			if opts.Issues == nil {
+				opts.Issues = map[string]string{}
+			}
+			opts.Issues[jira] = summary
+		}
+	}
+	return opts
+}
+
+func linkRefs(ctx context.Context, opts Options, refs []string) []string {
+	f, ok := detectForge(ctx, "origin", opts.ForgeHosts)
+	out := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		id := issueID(ref)
+		switch {
+		case ok && strings.HasPrefix(id, "#") && !strings.Contains(ref, "://"):
+			ref = f.issueURL(id[1:])
+		case opts.JiraURL != "" && jiraKeyPattern.MatchString(id) && !strings.Contains(ref, "://"):
+			ref = strings.TrimSuffix(opts.JiraURL, "/") + "/browse/" + id
+		}
+		out = append(out, ref)
+	}
+	return out
+}
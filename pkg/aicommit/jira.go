@@ -0,0 +1,67 @@
+package aicommit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	jiraKeyPattern       = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]{1,9}-\d+$`)
+	jiraBranchPattern    = regexp.MustCompile(`(?i)(?:^|[/_-])([a-z][a-z0-9]{1,9}-\d+)`)
+	jiraNonProjectPrefix = []string{"issue", "issues", "gh", "feat", "feature", "fix", "bugfix", "hotfix", "release", "v"}
+)
+
+func jiraKey(branch string) string {
+	for _, m := range jiraBranchPattern.FindAllStringSubmatch(branch, -1) {
+		project, _, _ := strings.Cut(strings.ToLower(m[1]), "-")
+		if !slices.Contains(jiraNonProjectPrefix, project) {
+			return strings.ToUpper(m[1])
+		}
+	}
+	return ""
+}
+
+func fetchJiraSummary(ctx context.Context, opts Options, key string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	endpoint := strings.TrimSuffix(opts.JiraURL, "/") + "/rest/api/2/issue/" + url.PathEscape(key) + "?fields=summary"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/json")
+	if opts.JiraUser != "" {
+		req.SetBasicAuth(opts.JiraUser, opts.JiraToken)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+opts.JiraToken)
+	}
+	start := time.Now()
+	resp, err := httpClient(ctx).Do(req)
+	if err != nil {
+		infof(ctx, "jira: request failed after %s: %v", since(start), err)
+		return "", err
+	}
+	defer resp.Body.Close()
+	infof(ctx, "jira: GET %s: http %d in %s", key, resp.StatusCode, since(start))
+	if resp.StatusCode >= 300 {
+		payload, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("jira http %d: %s", resp.StatusCode, strings.TrimSpace(string(payload)))
+	}
+	var issue struct {
+		Fields struct {
+			Summary string `json:"summary"`
+		} `json:"fields"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&issue); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(issue.Fields.Summary), nil
+}
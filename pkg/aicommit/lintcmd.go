@@ -0,0 +1,169 @@
+package aicommit
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func runLint(ctx context.Context, args []string, cfg *config, out io.Writer) error {
+	d := layeredDefaults{cfg: cfg, s: sessionOf(ctx)}
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	message := fs.String("m", "", "message to lint")
+	file := fs.String("F", "", "file with the message to lint ('-' for stdin), e.g. from a commit-msg hook")
+	format := fs.String("format", d.str("format"), "plain|conventional|gitmoji")
+	maxSubject := fs.Int("max-subject", d.integer("max_subject"), "max subject length")
+	presetName := fs.String("preset", d.str("preset"), "angular|conventionalcommits|atom|ember")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr(ctx), "Usage: aicommit lint [-m msg | -F file | <rev-range>]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	preset, err := lookupPreset(*presetName)
+	if err != nil {
+		return err
+	}
+	rules, err := lintRules(ctx, Format(*format), *maxSubject, preset, d)
+	if err != nil {
+		return err
+	}
+
+	type target struct {
+		source  string
+		message string
+	}
+	var targets []target
+	switch {
+	case *message != "":
+		targets = append(targets, target{source: "message", message: *message})
+	case *file != "":
+		var data []byte
+		if *file == "-" {
+			data, err = io.ReadAll(sessionOf(ctx).stdin)
+		} else {
+			data, err = os.ReadFile(*file)
+		}
+		if err != nil {
+			return err
+		}
+		targets = append(targets, target{source: *file, message: stripCommentLines(string(data))})
+	case fs.NArg() > 0:
+		commits, err := logCommits(ctx, fs.Args()...)
+		if err != nil {
+			return err
+		}
+		for _, c := range commits {
+			targets = append(targets, target{source: shortSHA(c.SHA), message: c.Message})
+		}
+	default:
+		fs.Usage()
+		return errors.New("nothing to lint")
+	}
+
+	errorsFound := 0
+	for _, t := range targets {
+		for _, v := range rules.check(t.message) {
+			fmt.Fprintf(out, "%s:%s\n", t.source, v)
+			if v.Level >= 2 {
+				errorsFound++
+			}
+		}
+	}
+	if errorsFound > 0 {
+		return fmt.Errorf("%d problems in %d messages", errorsFound, len(targets))
+	}
+	return nil
+}
+
+type lintRuleSet struct {
+	Lint       *commitlintConfig
+	Commitizen *commitizenConfig
+	Preset     *commitPreset
+}
+
+func (set lintRuleSet) check(message string) []lintViolation {
+	if strings.TrimSpace(message) == "" {
+		return []lintViolation{{Level: 2, Rule: "message-empty", Line: 1, Column: 1, Message: "message may not be empty"}}
+	}
+	out := lintMessage(message, set.Lint)
+	if set.Commitizen != nil && set.Commitizen.SchemaPattern != nil && !set.Commitizen.SchemaPattern.MatchString(message) {
+		out = append(out, lintViolation{Level: 2, Rule: "schema-pattern", Line: 1, Column: 1, Message: "message does not match commitizen schema_pattern"})
+	}
+	if set.Preset != nil {
+		header, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+		if !set.Preset.Pattern.MatchString(header) {
+			out = append(out, lintViolation{Level: 2, Rule: "header-format", Line: 1, Column: 1, Message: fmt.Sprintf("header does not follow the %s preset, e.g. %q", set.Preset.Name, set.Preset.Example)})
+		} else if t, _, _, _ := set.Preset.parse(header); t == "" {
+			out = append(out, lintViolation{Level: 2, Rule: "header-tag", Line: 1, Column: 1, Message: fmt.Sprintf("unknown %s tag in header", set.Preset.Name)})
+		}
+	}
+	return out
+}
+
+func lintRules(ctx context.Context, format Format, maxSubject int, preset *commitPreset, d layeredDefaults) (lintRuleSet, error) {
+	set := lintRuleSet{Preset: preset}
+	root, err := gitOutput(ctx, "rev-parse", "--show-toplevel")
+	if err == nil {
+		if d.boolean("commitlint") {
+			if set.Lint, err = loadCommitlint(ctx, root); err != nil {
+				return set, err
+			}
+		}
+		if d.boolean("commitizen") {
+			if set.Commitizen, err = loadCommitizen(ctx, root); err != nil {
+				return set, err
+			}
+		}
+	}
+	switch {
+	case set.Lint != nil || set.Commitizen != nil:
+	case preset != nil:
+		set.Lint = preset.lintConfig(maxSubject)
+	default:
+		set.Lint = builtinLintConfig(format, maxSubject)
+	}
+	if set.Commitizen != nil {
+		set.Lint = set.Commitizen.apply(set.Lint)
+	}
+	return set, nil
+}
+
+func builtinLintConfig(format Format, maxSubject int) *commitlintConfig {
+	c := &commitlintConfig{Path: "builtin", Rules: map[string]commitlintRule{
+		"subject-empty":      {Level: 2, When: "never"},
+		"body-leading-blank": {Level: 2, When: "always"},
+	}}
+	if maxSubject > 0 {
+		c.Rules["subject-max-length"] = commitlintRule{Level: 2, When: "always", Value: float64(maxSubject)}
+	}
+	if format == FormatConventional || format == FormatGitmoji {
+		types := append([]any{}, conventionalTypes...)
+		types = append(types, "infra")
+		c.Rules["type-empty"] = commitlintRule{Level: 2, When: "never"}
+		c.Rules["type-enum"] = commitlintRule{Level: 2, When: "always", Value: types}
+		c.Rules["type-case"] = commitlintRule{Level: 2, When: "always", Value: "lower-case"}
+		c.Rules["subject-full-stop"] = commitlintRule{Level: 1, When: "never", Value: "."}
+	}
+	return c
+}
+
+func stripCommentLines(message string) string {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if strings.HasPrefix(line, "# ------------------------ >8 ------------------------") {
+			break
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
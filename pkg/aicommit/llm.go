@@ -0,0 +1,279 @@
+package aicommit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/skrashevich/aicommit/pkg/llm"
+	"github.com/skrashevich/aicommit/pkg/render"
+)
+
+func generateWithLLM(ctx context.Context, opts Options, cs ChangeSet, commitType, scope string, breaking bool, breakingNote, heuristic string, reasons []string) (string, error) {
+	system, user := buildLLMPrompts(opts, cs, commitType, scope, breaking, breakingNote, heuristic, reasons)
+	content, err := completeChat(ctx, opts, system, user)
+	if err != nil {
+		return "", err
+	}
+	content = llm.CleanMessage(content)
+	if content == "" {
+		return "", errors.New("llm response content is empty")
+	}
+	return content, nil
+}
+
+var errLLMCancelled = errors.New("llm request cancelled")
+
+func completeChat(ctx context.Context, opts Options, system, user string) (string, error) {
+	sigCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	done := startProgress(ctx, opts, "querying "+strings.TrimSpace(opts.LLMModel), nil)
+	content, err := requestChat(sigCtx, opts, system, user)
+	done()
+	if err != nil && ctx.Err() != nil {
+		return "", fmt.Errorf("llm: %w", context.Cause(ctx))
+	}
+	if err != nil && sigCtx.Err() != nil {
+		return "", errLLMCancelled
+	}
+	return content, err
+}
+
+func requestChat(ctx context.Context, opts Options, system, user string) (string, error) {
+	provider := strings.ToLower(strings.TrimSpace(opts.LLMProvider))
+	if provider == "" {
+		provider = llm.ProviderOpenAI
+	}
+	switch provider {
+	case llm.ProviderOpenAI, llm.ProviderOpenRouter:
+	default:
+		return "", fmt.Errorf("unsupported llm provider: %s", provider)
+	}
+
+	model := strings.TrimSpace(opts.LLMModel)
+	if model == "" {
+		return "", errors.New("llm model is required (use -model or AICOMMIT_LLM_MODEL)")
+	}
+
+	if err := llm.CheckEndpoint(opts.LLMEndpoint, opts.AllowInsecure); err != nil {
+		return "", err
+	}
+	endpoint := llm.Endpoint(provider, opts.LLMEndpoint)
+	apiKey := resolveAPIKey(provider, opts.LLMKey)
+	if opts.LLMOIDC.enabled() {
+		token, err := oidcBearer(ctx, opts.LLMOIDC)
+		if err != nil {
+			return "", fmt.Errorf("oidc: %w", err)
+		}
+		apiKey = token
+	}
+	if apiKey == "" {
+		return "", errors.New("llm api key is required (use env or -llm-key)")
+	}
+
+	user, err := guardSecrets(ctx, opts, user)
+	if err != nil {
+		return "", err
+	}
+	client, err := clientCertHTTPClient(ctx, opts.LLMClientCert, opts.LLMClientKey)
+	if err != nil {
+		return "", err
+	}
+
+	var temp *float64
+	if opts.LLMTemperature >= 0 {
+		value := opts.LLMTemperature
+		temp = &value
+	}
+	infof(ctx, "llm: %s model %s, system prompt %d bytes, user prompt %d bytes (~%d tokens)", provider, model, len(system), len(user), llm.EstimateTokens(system+user))
+	requestID := opts.LLMRequestID
+	if requestID == "" {
+		requestID = llm.NewRequestID()
+	}
+	userAgent := opts.LLMUserAgent
+	if userAgent == "" {
+		userAgent = "aicommit/" + version
+	}
+	debugf(ctx, "llm: POST %s (request id %s, user agent %s)", endpoint, requestID, userAgent)
+
+	return llm.Complete(ctx, llm.Request{
+		Provider:    provider,
+		Endpoint:    opts.LLMEndpoint,
+		APIKey:      apiKey,
+		Model:       model,
+		System:      system,
+		User:        user,
+		Temperature: temp,
+		MaxTokens:   opts.LLMMaxTokens,
+		Gzip:        opts.LLMGzip,
+		RequestID:   requestID,
+		UserAgent:   userAgent,
+		Referer:     opts.LLMReferer,
+		Title:       opts.LLMTitle,
+		Client:      client,
+		Logf:        sessionOf(ctx).infof,
+		Debugf:      sessionOf(ctx).debugf,
+	})
+}
+
+func buildLLMPrompts(opts Options, cs ChangeSet, commitType, scope string, breaking bool, breakingNote, heuristic string, reasons []string) (string, string) {
+	system := strings.TrimSpace(opts.LLMSystem)
+	if system == "" {
+		system = defaultLLMSystemPrompt()
+	}
+	user := buildLLMUserPrompt(opts, cs, commitType, scope, breaking, breakingNote, heuristic, reasons)
+	if style := strings.TrimSpace(opts.StylePrompt); style != "" {
+		user = user + "\n\nTeam style guide:\n" + style
+	}
+	if extra := strings.TrimSpace(opts.LLMUser); extra != "" {
+		user = user + "\n\nExtra instructions:\n" + extra
+	}
+	return system, user
+}
+
+func resolveAPIKey(provider string, override string) string {
+	if strings.TrimSpace(override) != "" {
+		return override
+	}
+	if env, _ := getenv("AICOMMIT_LLM_KEY"); env != "" {
+		return env
+	}
+	switch provider {
+	case llm.ProviderOpenRouter:
+		return strings.TrimSpace(os.Getenv("OPENROUTER_API_KEY"))
+	default:
+		return strings.TrimSpace(os.Getenv("OPENAI_API_KEY"))
+	}
+}
+
+func defaultLLMSystemPrompt() string {
+	return strings.Join([]string{
+		"You are a commit message generator for git.",
+		"Return ONLY the commit message text.",
+		"No preface, no analysis, no markdown, no code fences, no surrounding quotes.",
+		"Follow the user's formatting and language requirements exactly.",
+		"Use only the provided context; do not invent changes.",
+	}, " ")
+}
+
+func buildLLMUserPrompt(opts Options, cs ChangeSet, commitType, scope string, breaking bool, breakingNote, heuristic string, reasons []string) string {
+	mode, changes, diff, stats := cs.Mode, cs.Changes, cs.Diff, cs.Stats
+	var b strings.Builder
+	fmt.Fprintf(&b, "Requirements:\n")
+	fmt.Fprintf(&b, "- Language: %s\n", opts.Lang)
+	fmt.Fprintf(&b, "- Format: %s\n", opts.Format)
+	if opts.Format == FormatConventional || opts.Format == FormatGitmoji {
+		fmt.Fprintf(&b, "- Use format: type(scope)!: subject (scope optional).\n")
+	}
+	if opts.Format == FormatPlain {
+		fmt.Fprintf(&b, "- Use a single-line subject without type prefix.\n")
+	}
+	fmt.Fprintf(&b, "- Subject max length: %d characters.\n", opts.MaxSubject)
+	if opts.commitizen != nil {
+		for _, line := range opts.commitizen.promptLines() {
+			fmt.Fprintf(&b, "- %s\n", line)
+		}
+	}
+	if opts.commitlint != nil {
+		for _, line := range opts.commitlint.promptLines() {
+			fmt.Fprintf(&b, "- %s\n", line)
+		}
+	}
+	if opts.preset != nil {
+		for _, line := range opts.preset.promptLines() {
+			fmt.Fprintf(&b, "- %s\n", line)
+		}
+	} else if opts.SemanticRelease {
+		for _, line := range semanticReleasePromptLines() {
+			fmt.Fprintf(&b, "- %s\n", line)
+		}
+	}
+	fmt.Fprintf(&b, "- Body mode: %s.\n", opts.Body)
+	fmt.Fprintf(&b, "- For body lists, use '- ' bullet per line.\n")
+	if opts.Body == BodyAuto {
+		fmt.Fprintf(&b, "- Auto body: if files <= %d, list files; otherwise provide a one-line summary.\n", opts.MaxItems)
+	}
+	if opts.Emoji || opts.Format == FormatGitmoji {
+		fmt.Fprintf(&b, "- Prepend gitmoji code that matches the type (e.g., :sparkles:, :bug:).\n")
+	}
+	for _, line := range issueFooterLines("Refs", opts.Refs, opts.Issues, opts.IssueTitles) {
+		fmt.Fprintf(&b, "- Include footer: %s\n", line)
+	}
+	for _, line := range issueFooterLines("Closes", opts.Closes, opts.Issues, opts.IssueTitles) {
+		fmt.Fprintf(&b, "- Include footer: %s\n", line)
+	}
+	if breaking {
+		if breakingNote == "" {
+			fmt.Fprintf(&b, "- Breaking change detected. Add 'BREAKING CHANGE: ...' footer.\n")
+		} else {
+			fmt.Fprintf(&b, "- Breaking change detected (%s). Add 'BREAKING CHANGE: %s' footer.\n", breakingNote, breakingNote)
+		}
+	} else {
+		fmt.Fprintf(&b, "- No breaking change detected; avoid BREAKING CHANGE unless diff clearly requires it.\n")
+	}
+
+	fmt.Fprintf(&b, "\nContext:\n")
+	fmt.Fprintf(&b, "- Mode: %s\n", mode)
+	fmt.Fprintf(&b, "- Heuristic suggestion: %s\n", oneLine(heuristic))
+	if commitType != "" {
+		fmt.Fprintf(&b, "- Heuristic type: %s\n", commitType)
+	}
+	if scope != "" {
+		fmt.Fprintf(&b, "- Heuristic scope: %s\n", scope)
+	}
+	if len(reasons) > 0 {
+		fmt.Fprintf(&b, "- Heuristic reasons: %s\n", strings.Join(reasons, "; "))
+	}
+	for _, ref := range append(append([]string{}, opts.Refs...), opts.Closes...) {
+		if title := opts.Issues[issueID(ref)]; title != "" {
+			fmt.Fprintf(&b, "- Referenced issue %s: %s\n", issueID(ref), title)
+		}
+	}
+
+	fmt.Fprintf(&b, "\nChanges:\n")
+	fileLines := render.FileLines(changes, minInt(opts.MaxItems, 20), opts.Lang)
+	if len(fileLines) == 0 {
+		fmt.Fprintf(&b, "- (no files)\n")
+	} else {
+		for _, line := range fileLines {
+			fmt.Fprintf(&b, "%s\n", line)
+		}
+	}
+
+	if len(stats) > 0 {
+		fmt.Fprintf(&b, "\nStats:\n")
+		for _, line := range render.StatLines(stats, minInt(opts.MaxItems, 20), opts.Lang) {
+			fmt.Fprintf(&b, "%s\n", line)
+		}
+	}
+
+	trimmedDiff, truncated := llm.TruncateDiff(diff, opts.LLMMaxDiff)
+	if strings.TrimSpace(trimmedDiff) != "" {
+		if truncated {
+			fmt.Fprintf(&b, "\nDiff (truncated to %d bytes):\n", opts.LLMMaxDiff)
+		} else {
+			fmt.Fprintf(&b, "\nDiff:\n")
+		}
+		fmt.Fprintln(&b, trimmedDiff)
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+func oneLine(s string) string {
+	return strings.TrimSpace(strings.ReplaceAll(s, "\n", " "))
+}
+
+func minInt(a, b int) int {
+	if a <= 0 {
+		return b
+	}
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,75 @@
+package aicommit
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+const (
+	levelWarn = iota
+	levelInfo
+	levelDebug
+)
+
+func parseLogLevel(raw string) (int, bool) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", "warn", "quiet":
+		return levelWarn, true
+	case "info", "verbose":
+		return levelInfo, true
+	case "debug", "trace":
+		return levelDebug, true
+	default:
+		return levelWarn, false
+	}
+}
+
+func setupLogging(args []string) int {
+	logLevel := levelWarn
+	if raw, _ := getenv("AICOMMIT_LOG_LEVEL"); raw != "" {
+		if level, ok := parseLogLevel(raw); ok {
+			logLevel = level
+		}
+	}
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		switch name {
+		case "v":
+			if hasValue {
+				if v, ok := parseBool(value); ok && !v {
+					continue
+				}
+			}
+			logLevel = max(logLevel, levelInfo)
+		case "vv":
+			logLevel = levelDebug
+		case "log-level":
+			if !hasValue && i+1 < len(args) {
+				value = args[i+1]
+			}
+			if level, ok := parseLogLevel(value); ok {
+				logLevel = level
+			}
+		}
+	}
+	return logLevel
+}
+
+func infof(ctx context.Context, format string, args ...any) {
+	sessionOf(ctx).infof(format, args...)
+}
+
+func debugf(ctx context.Context, format string, args ...any) {
+	sessionOf(ctx).debugf(format, args...)
+}
+
+func since(start time.Time) string {
+	return time.Since(start).Round(time.Millisecond).String()
+}
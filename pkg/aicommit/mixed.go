@@ -0,0 +1,67 @@
+package aicommit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/skrashevich/aicommit/pkg/detect"
+)
+
+const (
+	mixedMinLines = 10
+	mixedMinShare = 0.2
+	exitMixed     = 3
+)
+
+type categoryWeight struct {
+	Category string `json:"category"`
+	Weight   int    `json:"weight"`
+}
+
+func detectMixed(changes []Change, stats []FileStat, opts Options) []categoryWeight {
+	byPath := statsByPath(stats)
+	weights := map[string]int{}
+	total := 0
+	for _, ch := range changes {
+		cat := detect.CategorizePath(ch.Path)
+		if t, ok := lookupMapping(opts.TypeMap, ch.Path); ok {
+			cat = strings.ToLower(t)
+		}
+		weight := lineWeight(byPath, ch.Path)
+		weights[cat] += weight
+		total += weight
+	}
+	var strong []categoryWeight
+	for cat, weight := range weights {
+		if cat == detect.Test {
+			continue
+		}
+		if weight < mixedMinLines || float64(weight) < float64(total)*mixedMinShare {
+			continue
+		}
+		strong = append(strong, categoryWeight{Category: cat, Weight: weight})
+	}
+	if len(strong) < 2 {
+		return nil
+	}
+	sort.Slice(strong, func(i, j int) bool {
+		if strong[i].Weight != strong[j].Weight {
+			return strong[i].Weight > strong[j].Weight
+		}
+		return strong[i].Category < strong[j].Category
+	})
+	return strong
+}
+
+func formatCategoryWeights(weights []categoryWeight) string {
+	parts := make([]string, 0, len(weights))
+	for _, cw := range weights {
+		parts = append(parts, fmt.Sprintf("%s=%d", cw.Category, cw.Weight))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func mixedWarning(weights []categoryWeight) string {
+	return "changes span multiple areas (" + formatCategoryWeights(weights) + "); consider splitting the commit"
+}
@@ -0,0 +1,123 @@
+package aicommit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type mobAuthor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func (a mobAuthor) String() string {
+	return a.Name + " <" + a.Email + ">"
+}
+
+func coauthorsFile(ctx context.Context, root string) map[string]mobAuthor {
+	candidates := []string{os.Getenv("GITMOB_COAUTHORS_PATH")}
+	if root != "" {
+		candidates = append(candidates, filepath.Join(root, ".git-coauthors"))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, ".git-coauthors"))
+	}
+	for _, file := range candidates {
+		if file == "" {
+			continue
+		}
+		data, err := os.ReadFile(sessionOf(ctx).path(file))
+		if err != nil {
+			continue
+		}
+		var doc struct {
+			Coauthors map[string]mobAuthor `json:"coauthors"`
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			fmt.Fprintf(stderr(ctx), "warning: %s: %v\n", file, err)
+			continue
+		}
+		debugf(ctx, "mob: %d co-authors in %s", len(doc.Coauthors), file)
+		return doc.Coauthors
+	}
+	return nil
+}
+
+func mobSession(ctx context.Context, root string) []string {
+	if raw, err := gitOutput(ctx, "config", "--get-all", "git-mob.co-author"); err == nil && raw != "" {
+		return strings.Split(raw, "\n")
+	}
+	template := os.Getenv("GITMOB_MESSAGE_PATH")
+	if template == "" {
+		template, _ = gitOutput(ctx, "config", "--path", "commit.template")
+	}
+	if template == "" && root != "" {
+		template = filepath.Join(root, ".git", ".gitmessage")
+	}
+	data, err := os.ReadFile(sessionOf(ctx).path(template))
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), "Co-authored-by:"); ok {
+			out = append(out, strings.TrimSpace(value))
+		}
+	}
+	return out
+}
+
+func resolveCoauthors(ctx context.Context, mob, root string) ([]string, error) {
+	mob = strings.TrimSpace(mob)
+	var authors []string
+	switch strings.ToLower(mob) {
+	case "off", "none", "false":
+		return nil, nil
+	case "", "auto":
+		authors = mobSession(ctx, root)
+	default:
+		known := coauthorsFile(ctx, root)
+		for _, initials := range splitList(mob) {
+			a, ok := known[initials]
+			if !ok {
+				return nil, fmt.Errorf("unknown co-author %q (add it to .git-coauthors)", initials)
+			}
+			authors = append(authors, a.String())
+		}
+	}
+	self, _ := gitOutput(ctx, "config", "user.email")
+	var out []string
+	for _, a := range authors {
+		if a != "" && (self == "" || !strings.Contains(strings.ToLower(a), "<"+strings.ToLower(self)+">")) {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func addTrailers(message, key string, values []string) string {
+	if len(values) == 0 {
+		return message
+	}
+	p := parseCommitMessage(message)
+	var add []string
+	for _, v := range values {
+		line := key + ": " + v
+		if !strings.Contains(message, line) {
+			add = append(add, line)
+		}
+	}
+	at := len(p.Footer)
+	for i, line := range p.Footer {
+		if breakingNotePattern.MatchString(line) {
+			at = i
+			break
+		}
+	}
+	p.Footer = append(p.Footer[:at], append(add, p.Footer[at:]...)...)
+	return p.render()
+}
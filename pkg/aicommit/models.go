@@ -0,0 +1,122 @@
+package aicommit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skrashevich/aicommit/pkg/llm"
+)
+
+type modelList struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
+
+func runModels(ctx context.Context, args []string, cfg *config, out io.Writer) error {
+	value := func(key string) string {
+		s, _ := findSetting(key)
+		return resolveSetting(s, cfg).Value
+	}
+	fs := flag.NewFlagSet("models", flag.ContinueOnError)
+	provider := fs.String("provider", value("llm.provider"), "openai|openrouter")
+	endpoint := fs.String("endpoint", value("llm.endpoint"), "override LLM endpoint URL")
+	insecureDefault, _ := strconv.ParseBool(value("llm.allow_insecure_endpoint"))
+	allowInsecure := fs.Bool("allow-insecure-endpoint", insecureDefault, "allow a plain-HTTP -endpoint on a non-local host")
+	clientCert := fs.String("llm-client-cert", value("llm.client_cert"), "PEM client certificate for mutual TLS")
+	clientKey := fs.String("llm-client-key", value("llm.client_key"), "PEM private key for -llm-client-cert")
+	filter := fs.String("filter", "", "only list models containing this substring")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	name := strings.ToLower(strings.TrimSpace(*provider))
+	if name == "" {
+		name = llm.ProviderOpenAI
+	}
+	if name != llm.ProviderOpenAI && name != llm.ProviderOpenRouter {
+		return fmt.Errorf("unsupported llm provider: %s", name)
+	}
+	if err := llm.CheckEndpoint(*endpoint, *allowInsecure); err != nil {
+		return err
+	}
+	apiKey := resolveAPIKey(name, value("llm.key"))
+	if oidc := oidcFromSettings(value); oidc.enabled() {
+		token, err := oidcBearer(ctx, oidc)
+		if err != nil {
+			return fmt.Errorf("oidc: %w", err)
+		}
+		apiKey = token
+	}
+	if apiKey == "" && name == llm.ProviderOpenAI {
+		return errors.New("llm api key is required (use env or config llm.key)")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, modelsEndpoint(name, *endpoint), nil)
+	if err != nil {
+		return err
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	userAgent := value("llm.user_agent")
+	if userAgent == "" {
+		userAgent = "aicommit/" + version
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("X-Request-Id", llm.NewRequestID())
+	client, err := clientCertHTTPClient(ctx, *clientCert, *clientKey)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		infof(ctx, "models: request failed after %s: %v", since(start), err)
+		return err
+	}
+	infof(ctx, "models: http %d in %s", resp.StatusCode, since(start))
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		payload, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("models http %d: %s", resp.StatusCode, strings.TrimSpace(string(payload)))
+	}
+
+	var list modelList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(list.Data))
+	for _, m := range list.Data {
+		if *filter == "" || strings.Contains(m.ID, *filter) {
+			ids = append(ids, m.ID)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		fmt.Fprintln(out, id)
+	}
+	return nil
+}
+
+func modelsEndpoint(provider, override string) string {
+	if override = strings.TrimSpace(override); override != "" {
+		return strings.TrimSuffix(strings.TrimSuffix(override, "/"), "/chat/completions") + "/models"
+	}
+	switch provider {
+	case llm.ProviderOpenRouter:
+		return "https://openrouter.ai/api/v1/models"
+	default:
+		return "https://api.openai.com/v1/models"
+	}
+}
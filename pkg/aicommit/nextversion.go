@@ -0,0 +1,161 @@
+package aicommit
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type semver struct {
+	Prefix string
+	Major  int
+	Minor  int
+	Patch  int
+}
+
+func parseSemver(tag string) (semver, bool) {
+	var v semver
+	core := tag
+	if strings.HasPrefix(core, "v") || strings.HasPrefix(core, "V") {
+		v.Prefix, core = core[:1], core[1:]
+	}
+	if i := strings.IndexAny(core, "-+"); i != -1 {
+		core = core[:i]
+	}
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return v, false
+	}
+	nums := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		nums[i] = n
+	}
+	v.Major, v.Minor, v.Patch = nums[0], nums[1], nums[2]
+	return v, true
+}
+
+func (v semver) String() string {
+	return fmt.Sprintf("%s%d.%d.%d", v.Prefix, v.Major, v.Minor, v.Patch)
+}
+
+func (v semver) bump(level string) semver {
+	switch level {
+	case "major":
+		return semver{Prefix: v.Prefix, Major: v.Major + 1}
+	case "minor":
+		return semver{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor + 1}
+	case "patch":
+		return semver{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	}
+	return v
+}
+
+type versionSuggestion struct {
+	Current string `json:"current"`
+	Next    string `json:"next"`
+	Bump    string `json:"bump"`
+	Reason  string `json:"reason"`
+	Commits int    `json:"commits"`
+}
+
+func runNextVersion(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("next-version", flag.ContinueOnError)
+	format := fs.String("format", "text", "text|json")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr(ctx), "Usage: aicommit next-version [-format json]")
+		fmt.Fprintln(stderr(ctx), "Prints the next version on stdout and the reason on stderr.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := ensureGit(); err != nil {
+		return err
+	}
+	s, err := suggestVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if *format == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	}
+	fmt.Fprintln(out, s.Next)
+	fmt.Fprintln(stderr(ctx), s.Bump+":", s.Reason)
+	return nil
+}
+
+func suggestVersion(ctx context.Context) (versionSuggestion, error) {
+	tag, _ := gitOutput(ctx, "describe", "--tags", "--abbrev=0", "HEAD")
+	current := semver{Prefix: "v"}
+	rng := "HEAD"
+	if tag != "" {
+		v, ok := parseSemver(tag)
+		if !ok {
+			return versionSuggestion{}, fmt.Errorf("latest tag %s is not a semantic version", tag)
+		}
+		current = v
+		rng = tag + "..HEAD"
+	}
+	commits, err := logCommits(ctx, "--no-merges", rng)
+	if err != nil {
+		return versionSuggestion{}, err
+	}
+	s := versionSuggestion{Current: current.String(), Commits: len(commits)}
+	since := "since " + tag
+	if tag == "" {
+		since = "with no previous tag"
+	}
+
+	diffRange := ""
+	if tag != "" {
+		diffRange = rng
+	}
+	s.Bump, s.Reason = classifyBump(ctx, commits, diffRange, since)
+	bump := s.Bump
+	if bump == "major" && current.Major == 0 {
+		bump = "minor"
+		s.Reason += " (0.x: breaking changes bump minor)"
+	}
+	s.Next = current.bump(bump).String()
+	return s, nil
+}
+
+func classifyBump(ctx context.Context, commits []loggedCommit, diffRange, since string) (string, string) {
+	counts := map[string]int{}
+	for _, c := range commits {
+		switch {
+		case len(breakingNotes(c)) > 0:
+			counts["major"]++
+		case c.Parsed.Type == "feat":
+			counts["minor"]++
+		case c.Parsed.Type == "fix" || c.Parsed.Type == "perf" || c.Parsed.Type == "revert":
+			counts["patch"]++
+		}
+	}
+	bump, reason := "none", fmt.Sprintf("no feat, fix or breaking commits %s", since)
+	switch {
+	case counts["major"] > 0:
+		return "major", fmt.Sprintf("%d breaking commits %s", counts["major"], since)
+	case counts["minor"] > 0:
+		bump, reason = "minor", fmt.Sprintf("%d feat commits %s", counts["minor"], since)
+	case counts["patch"] > 0:
+		bump, reason = "patch", fmt.Sprintf("%d fix/perf/revert commits %s", counts["patch"], since)
+	}
+	if diffRange != "" {
+		diff, _ := gitOutput(ctx, "diff", "-U0", diffRange)
+		if breaking, note, _ := detectBreaking(ChangeSet{Diff: diff}, Options{}); breaking && note != "" {
+			return "major", "diff analysis: " + note
+		}
+	}
+	return bump, reason
+}
@@ -0,0 +1,63 @@
+package aicommit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type offlineTransport struct {
+	stderr io.Writer
+}
+
+func (t offlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	err := fmt.Errorf("offline: blocked connection to %s", req.URL.Host)
+	fmt.Fprintln(t.stderr, "error:", err)
+	return nil, err
+}
+
+func cutOfflineFlag(args []string) ([]string, bool, error) {
+	offline := false
+	if raw, name := getenv("AICOMMIT_OFFLINE"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: invalid boolean %q", name, raw)
+		}
+		offline = v
+	}
+	end := globalFlagsEnd(args)
+	out := make([]string, 0, len(args))
+	for _, arg := range args[:end] {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "offline" {
+			out = append(out, arg)
+			continue
+		}
+		if !hasValue {
+			offline = true
+			continue
+		}
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid boolean value %q for -offline", value)
+		}
+		offline = v
+	}
+	return append(out, args[end:]...), offline, nil
+}
+
+func applyOffline(ctx context.Context, opts Options) Options {
+	if !sessionOf(ctx).offline {
+		return opts
+	}
+	if opts.LLMEnabled || opts.IssueTitles || opts.WebhookURL != "" {
+		infof(ctx, "offline: LLM, issue titles and webhook disabled")
+	}
+	opts.LLMEnabled = false
+	opts.IssueTitles = false
+	opts.WebhookURL = ""
+	return opts
+}
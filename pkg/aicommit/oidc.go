@@ -0,0 +1,247 @@
+package aicommit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type OIDCConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scope        string
+	Audience     string
+}
+
+type oidcToken struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+type oidcResponse struct {
+	AccessToken     string `json:"access_token"`
+	RefreshToken    string `json:"refresh_token"`
+	ExpiresIn       int    `json:"expires_in"`
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	CompleteURI     string `json:"verification_uri_complete"`
+	Interval        int    `json:"interval"`
+	Error           string `json:"error"`
+	Description     string `json:"error_description"`
+}
+
+type oidcDiscovery struct {
+	TokenEndpoint  string `json:"token_endpoint"`
+	DeviceEndpoint string `json:"device_authorization_endpoint"`
+}
+
+func oidcFromSettings(get func(string) string) OIDCConfig {
+	return OIDCConfig{
+		Issuer:       strings.TrimRight(strings.TrimSpace(get("llm.oidc.issuer")), "/"),
+		ClientID:     strings.TrimSpace(get("llm.oidc.client_id")),
+		ClientSecret: strings.TrimSpace(get("llm.oidc.client_secret")),
+		Scope:        strings.TrimSpace(get("llm.oidc.scope")),
+		Audience:     strings.TrimSpace(get("llm.oidc.audience")),
+	}
+}
+
+func (c OIDCConfig) enabled() bool {
+	return c.Issuer != ""
+}
+
+func (c OIDCConfig) flow() string {
+	if c.ClientSecret != "" {
+		return "client credentials"
+	}
+	return "device flow"
+}
+
+func oidcBearer(ctx context.Context, c OIDCConfig) (string, error) {
+	if c.ClientID == "" {
+		return "", errors.New("llm.oidc.client_id is required with llm.oidc.issuer")
+	}
+	cache := oidcCachePath(c)
+	var cached oidcToken
+	if data, err := os.ReadFile(cache); err == nil {
+		json.Unmarshal(data, &cached)
+	}
+	if cached.AccessToken != "" && time.Until(cached.Expiry) > time.Minute {
+		debugf(ctx, "oidc: cached token valid until %s", cached.Expiry.Format(time.DateTime))
+		return cached.AccessToken, nil
+	}
+	disc, err := discoverOIDC(ctx, c.Issuer)
+	if err != nil {
+		return "", err
+	}
+	var tok oidcToken
+	if cached.RefreshToken != "" {
+		form := url.Values{"grant_type": {"refresh_token"}, "refresh_token": {cached.RefreshToken}}
+		if tok, err = oidcExchange(ctx, c, disc.TokenEndpoint, form); err != nil {
+			infof(ctx, "oidc: refresh failed, starting %s: %v", c.flow(), err)
+		} else if tok.RefreshToken == "" {
+			tok.RefreshToken = cached.RefreshToken
+		}
+	}
+	if tok.AccessToken == "" {
+		if c.ClientSecret != "" {
+			tok, err = oidcExchange(ctx, c, disc.TokenEndpoint, url.Values{"grant_type": {"client_credentials"}})
+		} else {
+			tok, err = oidcDeviceFlow(ctx, c, disc)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(cache), 0o700); err == nil {
+		data, _ := json.Marshal(tok)
+		if err := os.WriteFile(cache, data, 0o600); err != nil {
+			debugf(ctx, "oidc: cannot cache token: %v", err)
+		}
+	}
+	return tok.AccessToken, nil
+}
+
+func oidcCachePath(c OIDCConfig) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{c.Issuer, c.ClientID, c.Scope, c.Audience}, "\x00")))
+	return filepath.Join(userStateDir(), "oidc", hex.EncodeToString(sum[:8])+".json")
+}
+
+func discoverOIDC(ctx context.Context, issuer string) (oidcDiscovery, error) {
+	var disc oidcDiscovery
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return disc, err
+	}
+	resp, err := httpClient(ctx).Do(req)
+	if err != nil {
+		return disc, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return disc, fmt.Errorf("discovery http %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&disc); err != nil {
+		return disc, fmt.Errorf("discovery: %w", err)
+	}
+	if disc.TokenEndpoint == "" {
+		return disc, errors.New("discovery: issuer has no token_endpoint")
+	}
+	return disc, nil
+}
+
+func oidcPost(ctx context.Context, c OIDCConfig, endpoint string, form url.Values) (oidcResponse, error) {
+	var r oidcResponse
+	form.Set("client_id", c.ClientID)
+	if c.ClientSecret != "" {
+		form.Set("client_secret", c.ClientSecret)
+	}
+	if c.Scope != "" && form.Get("grant_type") != "refresh_token" && form.Get("device_code") == "" {
+		form.Set("scope", c.Scope)
+	}
+	if c.Audience != "" {
+		form.Set("audience", c.Audience)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return r, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := httpClient(ctx).Do(req)
+	if err != nil {
+		return r, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err := json.Unmarshal(body, &r); err != nil && resp.StatusCode < 300 {
+		return r, fmt.Errorf("token response: %w", err)
+	}
+	if r.Error == "" && resp.StatusCode >= 300 {
+		r.Error = fmt.Sprintf("http %d", resp.StatusCode)
+	}
+	return r, nil
+}
+
+func oidcExchange(ctx context.Context, c OIDCConfig, endpoint string, form url.Values) (oidcToken, error) {
+	r, err := oidcPost(ctx, c, endpoint, form)
+	if err != nil {
+		return oidcToken{}, err
+	}
+	if r.Error != "" {
+		return oidcToken{}, oidcError(r)
+	}
+	return r.token()
+}
+
+func (r oidcResponse) token() (oidcToken, error) {
+	if r.AccessToken == "" {
+		return oidcToken{}, errors.New("token response has no access_token")
+	}
+	expiry := time.Now().Add(time.Hour)
+	if r.ExpiresIn > 0 {
+		expiry = time.Now().Add(time.Duration(r.ExpiresIn) * time.Second)
+	}
+	return oidcToken{AccessToken: r.AccessToken, RefreshToken: r.RefreshToken, Expiry: expiry}, nil
+}
+
+func oidcError(r oidcResponse) error {
+	if r.Description != "" {
+		return fmt.Errorf("%s: %s", r.Error, r.Description)
+	}
+	return errors.New(r.Error)
+}
+
+func oidcDeviceFlow(ctx context.Context, c OIDCConfig, disc oidcDiscovery) (oidcToken, error) {
+	if disc.DeviceEndpoint == "" {
+		return oidcToken{}, errors.New("issuer does not support the device flow; set llm.oidc.client_secret for client credentials")
+	}
+	start, err := oidcPost(ctx, c, disc.DeviceEndpoint, url.Values{})
+	if err != nil {
+		return oidcToken{}, err
+	}
+	if start.Error != "" {
+		return oidcToken{}, oidcError(start)
+	}
+	if start.CompleteURI != "" {
+		fmt.Fprintf(stderr(ctx), "To sign in to the LLM gateway, open %s\n(code %s)\n", start.CompleteURI, start.UserCode)
+	} else {
+		fmt.Fprintf(stderr(ctx), "To sign in to the LLM gateway, open %s and enter the code %s\n", start.VerificationURI, start.UserCode)
+	}
+	interval := time.Duration(max(start.Interval, 5)) * time.Second
+	deadline := time.Now().Add(time.Duration(max(start.ExpiresIn, 60)) * time.Second)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return oidcToken{}, ctx.Err()
+		case <-time.After(interval):
+		}
+		r, err := oidcPost(ctx, c, disc.TokenEndpoint, url.Values{"grant_type": {"urn:ietf:params:oauth:grant-type:device_code"}, "device_code": {start.DeviceCode}})
+		if err != nil {
+			return oidcToken{}, err
+		}
+		switch r.Error {
+		case "":
+			fmt.Fprintln(stderr(ctx), "signed in")
+			return r.token()
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		default:
+			return oidcToken{}, oidcError(r)
+		}
+	}
+	return oidcToken{}, errors.New("device code expired before sign-in completed")
+}
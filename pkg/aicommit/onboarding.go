@@ -0,0 +1,50 @@
+package aicommit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const onboardingGuide = `aicommit: LLM mode needs an API key and no configuration was found.
+
+Quick setup:
+  aicommit init                  interactive setup wizard
+  export OPENAI_API_KEY=...      OpenAI (default provider)
+  export OPENROUTER_API_KEY=...  OpenRouter (with -provider openrouter)
+  export AICOMMIT_LLM_KEY=...    key for any provider
+
+Other settings can be set with AICOMMIT_* variables (see aicommit doctor).
+Run without -llm to use the built-in heuristic generator, which needs no key.
+`
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func needsOnboarding(cfg *config, opts Options) bool {
+	if !opts.LLMEnabled || opts.DryRun || len(cfg.Layers) > 0 {
+		return false
+	}
+	provider := strings.ToLower(strings.TrimSpace(opts.LLMProvider))
+	return resolveAPIKey(provider, opts.LLMKey) == "" && !opts.LLMOIDC.enabled()
+}
+
+func onboard(ctx context.Context, in io.Reader, out io.Writer, assumeYes bool) error {
+	f, ok := in.(*os.File)
+	if assumeYes || !ok || !isTerminal(f) || !sessionOf(ctx).terminal() {
+		fmt.Fprint(out, onboardingGuide)
+		return errors.New("llm api key is required")
+	}
+	fmt.Fprintln(out, "No configuration or API key found; starting aicommit init.")
+	fmt.Fprintln(out)
+	if err := runInit(ctx, nil, in, out); err != nil {
+		return err
+	}
+	fmt.Fprintln(out)
+	return nil
+}
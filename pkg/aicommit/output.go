@@ -0,0 +1,50 @@
+package aicommit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/skrashevich/aicommit/pkg/render"
+)
+
+func writeMessage(ctx context.Context, w io.Writer, message string, opts Options) error {
+	if opts.Output == "" || opts.Output == "-" {
+		end := "\n"
+		if opts.Print0 {
+			end = "\x00"
+		} else if opts.Pretty && os.Getenv("NO_COLOR") == "" {
+			message = colorizeMessage(message)
+		}
+		_, err := io.WriteString(w, message+end)
+		return err
+	}
+	path := resolveOutputPath(ctx, opts.Output)
+	if err := os.WriteFile(path, []byte(message+"\n"), 0o644); err != nil {
+		return fmt.Errorf("write message: %w", err)
+	}
+	infof(ctx, "message written to %s", path)
+	return nil
+}
+
+func writeJSONMessage(w io.Writer, m render.Message) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+func resolveOutputPath(ctx context.Context, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	if rest, ok := strings.CutPrefix(filepath.ToSlash(path), ".git/"); ok {
+		if resolved, err := gitOutput(ctx, "rev-parse", "--git-path", rest); err == nil && resolved != "" {
+			return resolved
+		}
+	}
+	return path
+}
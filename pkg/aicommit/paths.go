@@ -0,0 +1,49 @@
+package aicommit
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+func userConfigPath() string {
+	if path, _ := getenv("AICOMMIT_CONFIG"); path != "" {
+		return path
+	}
+	home, _ := os.UserHomeDir()
+	legacy := filepath.Join(home, ".config", "aicommit", "config.toml")
+	if runtime.GOOS == "darwin" && os.Getenv("XDG_CONFIG_HOME") == "" {
+		if _, err := os.Stat(legacy); err == nil {
+			return legacy
+		}
+	}
+	if os.Getenv("XDG_CONFIG_HOME") != "" && runtime.GOOS != "windows" {
+		return filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "aicommit", "config.toml")
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return filepath.Join(".config", "aicommit", "config.toml")
+	}
+	return filepath.Join(dir, "aicommit", "config.toml")
+}
+
+func userStateDir() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" && runtime.GOOS != "windows" {
+		return filepath.Join(dir, "aicommit")
+	}
+	switch runtime.GOOS {
+	case "windows":
+		if dir := os.Getenv("LocalAppData"); dir != "" {
+			return filepath.Join(dir, "aicommit")
+		}
+	case "darwin":
+		if dir, err := os.UserConfigDir(); err == nil {
+			return filepath.Join(dir, "aicommit")
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".local", "state", "aicommit")
+	}
+	return filepath.Join(home, ".local", "state", "aicommit")
+}
@@ -0,0 +1,53 @@
+package aicommit
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const (
+	benchFiles = 5000
+	benchLines = 200000
+)
+
+func syntheticChangeSet(files, lines int) ChangeSet {
+	cs := ChangeSet{Root: "/repo", Mode: ModeStaged}
+	perFile := max(1, lines/max(1, files))
+	var b strings.Builder
+	for i := range files {
+		path := fmt.Sprintf("src/module%d/service_%d.go", i/100, i)
+		cs.Changes = append(cs.Changes, Change{Path: path, Status: "M", Source: ModeStaged})
+		cs.Stats = append(cs.Stats, FileStat{Path: path, Added: perFile, Deleted: perFile / 2})
+		fmt.Fprintf(&b, "diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n@@ -1,%d +1,%d @@\n", path, path, path, path, perFile/2, perFile)
+		for j := range perFile {
+			switch j % 4 {
+			case 0:
+				fmt.Fprintf(&b, "+func Handle%d_%d(ctx context.Context, req *Request) (*Response, error) {\n", i, j)
+			case 1:
+				fmt.Fprintf(&b, "-type Legacy%d_%d struct{}\n", i, j)
+			default:
+				fmt.Fprintf(&b, "+\tvalue := compute(%d, %d)\n", i, j)
+			}
+		}
+	}
+	cs.Diff = b.String()
+	return cs
+}
+
+func BenchmarkBuildLLMUserPrompt(b *testing.B) {
+	cs := syntheticChangeSet(benchFiles, benchLines)
+	opts := Options{Lang: "en", Format: FormatConventional, Body: BodyAuto, MaxItems: 8, MaxSubject: 72, LLMMaxDiff: 20000}
+	b.ReportAllocs()
+	for b.Loop() {
+		buildLLMUserPrompt(opts, cs, "feat", "api", false, "", "feat(api): add handlers", []string{"new code or exported symbols"})
+	}
+}
+
+func BenchmarkSelectDiffPaths(b *testing.B) {
+	cs := syntheticChangeSet(benchFiles, benchLines)
+	b.ReportAllocs()
+	for b.Loop() {
+		selectDiffPaths(b.Context(), cs.Changes, cs.Stats, 8<<20)
+	}
+}
@@ -0,0 +1,247 @@
+package aicommit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+var typeLabels = map[string]string{
+	"feat": "feature",
+	"fix":  "bug",
+	"docs": "documentation",
+	"perf": "performance",
+}
+
+type mergeRequest struct {
+	Source string
+	Target string
+	Title  string
+	Body   string
+	Labels []string
+}
+
+func runPR(ctx context.Context, args []string, cfg *config, out io.Writer) error {
+	d := layeredDefaults{cfg: cfg, s: sessionOf(ctx)}
+	fs := flag.NewFlagSet("pr", flag.ContinueOnError)
+	remote := fs.String("remote", "origin", "remote the branch is pushed to")
+	base := fs.String("base", "", "target branch (default: the remote's default branch)")
+	create := fs.Bool("create", false, "create the merge request via the GitLab API")
+	draft := fs.Bool("draft", false, "mark the created merge request as draft")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr(ctx), "Usage: aicommit pr [-base main] [-create [-draft]]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := ensureGit(); err != nil {
+		return err
+	}
+
+	branch := currentBranch(ctx)
+	if branch == "" {
+		return errors.New("not on a branch")
+	}
+	target, rev := baseBranch(ctx, *remote, *base)
+	commits, err := logCommits(ctx, rev+"..HEAD")
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		return fmt.Errorf("no commits between %s and %s", rev, branch)
+	}
+	mr := describeMR(branch, target, commits)
+
+	if !*create {
+		fmt.Fprintln(out, mr.Title)
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, mr.Body)
+		if len(mr.Labels) > 0 {
+			fmt.Fprintf(out, "\nLabels: %s\n", strings.Join(mr.Labels, ", "))
+		}
+		return nil
+	}
+
+	remoteURL, err := gitOutput(ctx, "remote", "get-url", *remote)
+	if err != nil {
+		return fmt.Errorf("remote %s not found", *remote)
+	}
+	f, ok := parseForge(remoteURL, parseMappings(d.str("forge_hosts")))
+	if !ok || f.Kind != ForgeGitLab {
+		return fmt.Errorf("%s is not a GitLab remote (set AICOMMIT_GITLAB_URL or forge_hosts for self-hosted instances)", remoteURL)
+	}
+	if *draft {
+		mr.Title = "Draft: " + mr.Title
+	}
+	webURL, err := createGitLabMR(ctx, f.API, f.Project, mr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, webURL)
+	return nil
+}
+
+func baseBranch(ctx context.Context, remote, base string) (string, string) {
+	if base != "" {
+		if _, err := gitOutput(ctx, "rev-parse", "--verify", "-q", remote+"/"+base); err == nil {
+			return base, remote + "/" + base
+		}
+		return base, base
+	}
+	if ref, err := gitOutput(ctx, "symbolic-ref", "--short", "refs/remotes/"+remote+"/HEAD"); err == nil && ref != "" {
+		return strings.TrimPrefix(ref, remote+"/"), ref
+	}
+	for _, name := range []string{"main", "master"} {
+		if _, err := gitOutput(ctx, "rev-parse", "--verify", "-q", remote+"/"+name); err == nil {
+			return name, remote + "/" + name
+		}
+		if _, err := gitOutput(ctx, "rev-parse", "--verify", "-q", name); err == nil {
+			return name, name
+		}
+	}
+	return "main", "main"
+}
+
+func describeMR(branch, target string, commits []loggedCommit) mergeRequest {
+	mr := mergeRequest{Source: branch, Target: target}
+	groups := groupCommits(commits)
+
+	var b strings.Builder
+	var breaking []string
+	for _, c := range commits {
+		breaking = append(breaking, breakingNotes(c)...)
+	}
+	if len(breaking) > 0 {
+		b.WriteString("### ⚠ Breaking Changes\n\n")
+		for _, note := range breaking {
+			fmt.Fprintf(&b, "- %s\n", note)
+		}
+		b.WriteString("\n")
+	}
+	for _, g := range groups {
+		fmt.Fprintf(&b, "### %s\n\n", g.Title)
+		for _, c := range g.Commits {
+			if c.Parsed.Scope != "" {
+				fmt.Fprintf(&b, "- **%s:** %s\n", c.Parsed.Scope, c.Parsed.Subject)
+			} else {
+				fmt.Fprintf(&b, "- %s\n", c.Parsed.Subject)
+			}
+		}
+		b.WriteString("\n")
+		if g.Type == "" {
+			continue
+		}
+		label := typeLabels[g.Type]
+		if label == "" {
+			label = g.Type
+		}
+		mr.Labels = append(mr.Labels, label)
+	}
+	if len(breaking) > 0 {
+		mr.Labels = append(mr.Labels, "breaking change")
+	}
+	if footers := issueFooters(commits); len(footers) > 0 {
+		b.WriteString(strings.Join(footers, "\n") + "\n")
+	}
+	mr.Body = strings.TrimSpace(b.String())
+	mr.Title = mrTitle(branch, commits, groups)
+	return mr
+}
+
+func mrTitle(branch string, commits []loggedCommit, groups []commitGroup) string {
+	if len(commits) == 1 {
+		return commits[0].Parsed.Header
+	}
+	var top commitGroup
+	for _, g := range groups {
+		if g.Type != "" && len(g.Commits) > len(top.Commits) {
+			top = g
+		}
+	}
+	subject := branch
+	if i := strings.LastIndex(subject, "/"); i != -1 {
+		subject = subject[i+1:]
+	}
+	subject = strings.TrimSpace(strings.NewReplacer("-", " ", "_", " ").Replace(subject))
+	if subject == "" && len(top.Commits) > 0 {
+		subject = top.Commits[len(top.Commits)-1].Parsed.Subject
+	}
+	if top.Type == "" {
+		return subject
+	}
+	scope := top.Commits[0].Parsed.Scope
+	for _, c := range commits {
+		if c.Parsed.Scope != scope {
+			scope = ""
+			break
+		}
+	}
+	if scope != "" {
+		return fmt.Sprintf("%s(%s): %s", top.Type, scope, subject)
+	}
+	return fmt.Sprintf("%s: %s", top.Type, subject)
+}
+
+func gitLabToken() string {
+	if token, _ := getenv("AICOMMIT_GITLAB_TOKEN"); token != "" {
+		return token
+	}
+	return strings.TrimSpace(os.Getenv("GITLAB_TOKEN"))
+}
+
+func createGitLabMR(ctx context.Context, api, project string, mr mergeRequest) (string, error) {
+	token := gitLabToken()
+	if token == "" {
+		return "", errors.New("gitlab token is required (set GITLAB_TOKEN or AICOMMIT_GITLAB_TOKEN)")
+	}
+	body, err := json.Marshal(map[string]string{
+		"source_branch": mr.Source,
+		"target_branch": mr.Target,
+		"title":         mr.Title,
+		"description":   mr.Body,
+		"labels":        strings.Join(mr.Labels, ","),
+	})
+	if err != nil {
+		return "", err
+	}
+	endpoint := api + "/projects/" + url.PathEscape(project) + "/merge_requests"
+	debugf(ctx, "gitlab: POST %s", endpoint)
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("PRIVATE-TOKEN", token)
+	start := time.Now()
+	resp, err := httpClient(ctx).Do(req)
+	if err != nil {
+		infof(ctx, "gitlab: request failed after %s: %v", since(start), err)
+		return "", err
+	}
+	defer resp.Body.Close()
+	infof(ctx, "gitlab: http %d in %s", resp.StatusCode, since(start))
+	if resp.StatusCode >= 300 {
+		payload, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("gitlab http %d: %s", resp.StatusCode, strings.TrimSpace(string(payload)))
+	}
+	var created struct {
+		WebURL string `json:"web_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", err
+	}
+	return created.WebURL, nil
+}
@@ -0,0 +1,180 @@
+package aicommit
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/skrashevich/aicommit/pkg/llm"
+)
+
+type pushRange struct {
+	Label   string
+	Revs    []string
+	Commits []loggedCommit
+	Base    string
+	Stat    string
+}
+
+func runPrepush(ctx context.Context, args []string, cfg *config, in io.Reader, out io.Writer) error {
+	fs := flag.NewFlagSet("prepush", flag.ContinueOnError)
+	fromHook := fs.Bool("stdin", false, "read the refs being pushed from stdin (pre-push hook input)")
+	narrative := fs.Bool("llm", false, "add a short narrative of the changes written by the configured LLM")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr(ctx), "Usage: aicommit prepush [-llm] [-stdin]")
+		fmt.Fprintln(stderr(ctx), "Summarizes the commits a push will send.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := ensureGit(); err != nil {
+		return err
+	}
+
+	var ranges []pushRange
+	var err error
+	if *fromHook {
+		ranges, err = hookPushRanges(in)
+	} else {
+		ranges = []pushRange{branchPushRange(ctx)}
+	}
+	if err != nil {
+		return err
+	}
+	total := 0
+	for i := range ranges {
+		r := &ranges[i]
+		if r.Commits, err = logCommits(ctx, r.Revs...); err != nil {
+			return err
+		}
+		if len(r.Commits) == 0 {
+			continue
+		}
+		total += len(r.Commits)
+		oldest := r.Commits[len(r.Commits)-1].SHA
+		if r.Base, err = gitOutput(ctx, "rev-parse", "--verify", "-q", oldest+"^"); err != nil {
+			r.Base, _ = gitOutput(ctx, "hash-object", "-t", "tree", "/dev/null")
+		}
+		r.Stat, _ = gitOutput(ctx, "diff", "--shortstat", r.Base, r.Commits[0].SHA)
+	}
+	if total == 0 {
+		fmt.Fprintln(out, "nothing to push")
+		return nil
+	}
+
+	for _, r := range ranges {
+		if len(r.Commits) > 0 {
+			fmt.Fprint(out, renderPushRange(r))
+		}
+	}
+	if *narrative {
+		opts, err := parseFlags(ctx, cfg, nil)
+		if err == nil {
+			var text string
+			if text, err = pushNarrative(ctx, opts, ranges); err == nil {
+				fmt.Fprintf(out, "\n%s\n", text)
+			}
+		}
+		if err != nil {
+			fmt.Fprintln(stderr(ctx), "llm narrative skipped:", err)
+		}
+	}
+	return nil
+}
+
+func branchPushRange(ctx context.Context) pushRange {
+	branch := currentBranch(ctx)
+	if branch == "" {
+		branch = "HEAD"
+	}
+	if upstream, err := gitOutput(ctx, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}"); err == nil && upstream != "" {
+		return pushRange{Label: branch + " → " + upstream, Revs: []string{upstream + "..HEAD"}}
+	}
+	return pushRange{Label: branch + " (no upstream)", Revs: []string{"HEAD", "--not", "--remotes"}}
+}
+
+func hookPushRanges(in io.Reader) ([]pushRange, error) {
+	var ranges []pushRange
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 || strings.Trim(fields[1], "0") == "" {
+			continue
+		}
+		localRef, localSHA, remoteRef, remoteSHA := fields[0], fields[1], fields[2], fields[3]
+		r := pushRange{Label: strings.TrimPrefix(localRef, "refs/heads/") + " → " + strings.TrimPrefix(remoteRef, "refs/heads/")}
+		if strings.Trim(remoteSHA, "0") == "" {
+			r.Revs = []string{localSHA, "--not", "--remotes"}
+		} else {
+			r.Revs = []string{remoteSHA + ".." + localSHA}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, scanner.Err()
+}
+
+func renderPushRange(r pushRange) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d unpushed commits on %s\n", len(r.Commits), r.Label)
+	var wip []string
+	for _, c := range r.Commits {
+		marker := " "
+		if len(breakingNotes(c)) > 0 {
+			marker = "!"
+		}
+		fmt.Fprintf(&b, " %s %s %s\n", marker, shortSHA(c.SHA), c.Parsed.Header)
+		if isWIPCommit(c.Parsed.Header) {
+			wip = append(wip, shortSHA(c.SHA))
+		}
+	}
+	if r.Stat != "" {
+		fmt.Fprintf(&b, "%s\n", strings.TrimSpace(r.Stat))
+	}
+	if len(wip) > 0 {
+		fmt.Fprintf(&b, "warning: fixup/WIP commits: %s\n", strings.Join(wip, ", "))
+	}
+	return b.String()
+}
+
+func isWIPCommit(header string) bool {
+	lower := strings.ToLower(header)
+	for _, prefix := range []string{"fixup!", "squash!", "amend!", "wip"} {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func pushNarrative(ctx context.Context, opts Options, ranges []pushRange) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Requirements:\n")
+	fmt.Fprintf(&b, "- Language: %s\n", opts.Lang)
+	fmt.Fprintf(&b, "- In 2-4 sentences, describe what this push changes and anything a reviewer should double-check.\n")
+	for _, r := range ranges {
+		if len(r.Commits) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\nCommits (%s):\n", r.Label)
+		for _, c := range r.Commits {
+			fmt.Fprintf(&b, "- %s\n", c.Parsed.Header)
+		}
+		if r.Stat != "" {
+			fmt.Fprintf(&b, "Stats: %s\n", strings.TrimSpace(r.Stat))
+		}
+		diff, _ := gitOutput(ctx, "diff", "-U0", r.Base, r.Commits[0].SHA)
+		if trimmed, _ := llm.TruncateDiff(diff, opts.LLMMaxDiff); strings.TrimSpace(trimmed) != "" {
+			fmt.Fprintf(&b, "Diff:\n%s\n", trimmed)
+		}
+	}
+	system := "You summarize a git push for its author before it is pushed. Return plain text only, no lists or headings. Use only the provided commits and diff."
+	text, err := completeChat(ctx, opts, system, strings.TrimSpace(b.String()))
+	if err != nil {
+		return "", err
+	}
+	return llm.CleanMessage(text), nil
+}
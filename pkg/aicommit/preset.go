@@ -0,0 +1,234 @@
+package aicommit
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/skrashevich/aicommit/pkg/render"
+)
+
+type presetTag struct {
+	Type string
+	Tag  string
+}
+
+type commitPreset struct {
+	Name       string
+	Types      []string
+	Bang       bool
+	Tags       []presetTag
+	Capitalize bool
+	Visible    []string
+	Pattern    *regexp.Regexp
+	Example    string
+}
+
+var commitPresets = []*commitPreset{
+	{
+		Name:    "angular",
+		Types:   []string{"build", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test", "chore"},
+		Visible: []string{"feat", "fix", "perf", "revert"},
+		Pattern: regexp.MustCompile(`^(\w*)(?:\((.*)\))?: (.*)$`),
+		Example: "fix(parser): handle empty input",
+	},
+	{
+		Name:    "conventionalcommits",
+		Types:   []string{"feat", "fix", "perf", "revert", "docs", "style", "chore", "refactor", "test", "build", "ci"},
+		Bang:    true,
+		Visible: []string{"feat", "fix", "perf", "revert"},
+		Pattern: regexp.MustCompile(`^(\w*)(?:\((.*)\))?!?: (.*)$`),
+		Example: "feat(api)!: drop the v1 endpoints",
+	},
+	{
+		Name: "atom",
+		Tags: []presetTag{
+			{"feat", ":sparkles:"}, {"fix", ":bug:"}, {"perf", ":racehorse:"}, {"docs", ":memo:"},
+			{"test", ":white_check_mark:"}, {"ci", ":green_heart:"}, {"build", ":arrow_up:"}, {"style", ":shirt:"},
+			{"refactor", ":art:"}, {"revert", ":rewind:"}, {"chore", ":wrench:"}, {"infra", ":construction_worker:"},
+		},
+		Capitalize: true,
+		Pattern:    regexp.MustCompile(`^(:.*?:) (.*)$`),
+		Example:    ":bug: Fix crash on empty input",
+	},
+	{
+		Name: "ember",
+		Tags: []presetTag{
+			{"feat", "FEATURE"}, {"fix", "BUGFIX"}, {"perf", "BUGFIX"}, {"docs", "DOC"}, {"refactor", "CLEANUP"},
+			{"chore", "CLEANUP"}, {"style", "CLEANUP"}, {"test", "CLEANUP"}, {"build", "CLEANUP"}, {"ci", "CLEANUP"},
+			{"infra", "CLEANUP"}, {"revert", "CLEANUP"},
+		},
+		Capitalize: true,
+		Pattern:    regexp.MustCompile(`^\[(.*) (.*)] (.*)$`),
+		Example:    "[BUGFIX release] Fix crash on empty input",
+	},
+}
+
+func presetNames() []string {
+	names := []string{""}
+	for _, p := range commitPresets {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func lookupPreset(name string) (*commitPreset, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return nil, nil
+	}
+	for _, p := range commitPresets {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown preset %q (angular|conventionalcommits|atom|ember)", name)
+}
+
+func (p *commitPreset) conventional() bool {
+	return len(p.Tags) == 0
+}
+
+func (p *commitPreset) tag(commitType string) string {
+	for _, t := range p.Tags {
+		if t.Type == commitType {
+			return t.Tag
+		}
+	}
+	return p.Tags[len(p.Tags)-1].Tag
+}
+
+func (p *commitPreset) typeFor(commitType string) string {
+	commitType = strings.ToLower(commitType)
+	switch {
+	case slices.Contains(p.Types, commitType):
+		return commitType
+	case commitType == "infra" && slices.Contains(p.Types, "build"):
+		return "build"
+	}
+	return "chore"
+}
+
+func (p *commitPreset) header(commitType, scope, subject string, breaking bool) string {
+	if p.Capitalize {
+		subject = upperFirst(subject)
+	} else {
+		subject = render.LowerFirst(subject)
+	}
+	switch p.Name {
+	case "atom":
+		return p.tag(commitType) + " " + subject
+	case "ember":
+		if scope == "" {
+			scope = "release"
+		}
+		return "[" + p.tag(commitType) + " " + scope + "] " + subject
+	}
+	header := p.typeFor(commitType)
+	if scope != "" {
+		header += "(" + scope + ")"
+	}
+	if breaking && p.Bang {
+		header += "!"
+	}
+	return header + ": " + subject
+}
+
+func (p *commitPreset) parse(header string) (string, string, string, bool) {
+	m := p.Pattern.FindStringSubmatch(header)
+	if m == nil {
+		return "", "", "", false
+	}
+	if p.conventional() {
+		return m[1], m[2], m[3], true
+	}
+	tag, scope, subject := strings.ToUpper(m[1]), "", m[len(m)-1]
+	if p.Name == "atom" {
+		tag = m[1]
+	}
+	if p.Name == "ember" && m[2] != "release" && m[2] != "beta" && m[2] != "canary" {
+		scope = m[2]
+	}
+	for _, t := range p.Tags {
+		if t.Tag == tag {
+			return t.Type, scope, subject, true
+		}
+	}
+	return "", scope, subject, true
+}
+
+func (p *commitPreset) normalize(message string, breaking bool, note, lang string) string {
+	head, rest, _ := strings.Cut(message, "\n")
+	if p.conventional() {
+		parsed := parseCommitMessage(message)
+		if parsed.Type != "" {
+			head = p.header(parsed.Type, parsed.Scope, parsed.Subject, parsed.Breaking || breaking)
+			message = strings.TrimRight(head+"\n"+rest, "\n")
+		}
+		if !p.Bang {
+			return semanticReleaseMessage(message, breaking, note, lang)
+		}
+		return message
+	}
+	if p.Pattern.MatchString(head) {
+		return message
+	}
+	if parsed := parseCommitMessage(message); parsed.Type != "" {
+		head = p.header(parsed.Type, parsed.Scope, parsed.Subject, false)
+	}
+	return strings.TrimRight(head+"\n"+rest, "\n")
+}
+
+func (p *commitPreset) promptLines() []string {
+	lines := []string{fmt.Sprintf("Follow the %s commit convention, e.g. %q.", p.Name, p.Example)}
+	if p.conventional() {
+		lines = append(lines, "Allowed types: "+strings.Join(p.Types, ", ")+".")
+		if !p.Bang {
+			lines = append(lines, "Never put '!' in the header; describe breaking changes only in a 'BREAKING CHANGE: ' footer.")
+		}
+		return lines
+	}
+	var tags []string
+	for _, t := range p.Tags {
+		tags = append(tags, t.Type+" -> "+t.Tag)
+	}
+	return append(lines, "Header tags by change type: "+strings.Join(tags, ", ")+".", "Start the subject with a capital letter.")
+}
+
+func (p *commitPreset) lintConfig(maxSubject int) *commitlintConfig {
+	if !p.conventional() {
+		return builtinLintConfig(FormatPlain, maxSubject)
+	}
+	c := builtinLintConfig(FormatConventional, maxSubject)
+	types := make([]any, 0, len(p.Types))
+	for _, t := range p.Types {
+		types = append(types, t)
+	}
+	c.Rules["type-enum"] = commitlintRule{Level: 2, When: "always", Value: types}
+	return c
+}
+
+func (p *commitPreset) applyTo(commits []loggedCommit) []loggedCommit {
+	out := make([]loggedCommit, 0, len(commits))
+	for _, c := range commits {
+		if !p.conventional() {
+			if t, scope, subject, ok := p.parse(c.Parsed.Header); ok {
+				c.Parsed.Type, c.Parsed.Scope, c.Parsed.Subject = t, scope, subject
+			}
+		}
+		if p.Visible == nil || slices.Contains(p.Visible, c.Parsed.Type) || len(breakingNotes(c)) > 0 {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func upperFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return strings.ToUpper(string(r)) + s[size:]
+}
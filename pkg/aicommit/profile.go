@@ -0,0 +1,54 @@
+package aicommit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime/pprof"
+	"strings"
+)
+
+func cutProfileFlag(args []string) ([]string, string, error) {
+	file, _ := getenv("AICOMMIT_PROFILE")
+	end := globalFlagsEnd(args)
+	out := make([]string, 0, len(args))
+	for i := 0; i < end; i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "profile" {
+			out = append(out, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 >= end {
+				return nil, "", fmt.Errorf("flag needs an argument: -profile")
+			}
+			i++
+			value = args[i]
+		}
+		file = value
+	}
+	return append(out, args[end:]...), file, nil
+}
+
+func startProfile(ctx context.Context, file string) (func(), error) {
+	if file == "" {
+		return func() {}, nil
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return nil, fmt.Errorf("profile: %w", err)
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("profile: %w", err)
+	}
+	return func() {
+		pprof.StopCPUProfile()
+		if err := f.Close(); err != nil {
+			fmt.Fprintln(stderr(ctx), "warning: profile:", err)
+			return
+		}
+		infof(ctx, "profile: wrote %s", file)
+	}, nil
+}
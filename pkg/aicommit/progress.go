@@ -0,0 +1,64 @@
+package aicommit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+type progressKey struct{}
+
+func withProgressCounter(ctx context.Context, counter *atomic.Int64) context.Context {
+	return context.WithValue(ctx, progressKey{}, counter)
+}
+
+func progressCounter(ctx context.Context) *atomic.Int64 {
+	counter, _ := ctx.Value(progressKey{}).(*atomic.Int64)
+	return counter
+}
+
+func progressEnabled(ctx context.Context, opts Options) bool {
+	return opts.Pretty && !opts.Quiet && sessionOf(ctx).logLevel.Load() == levelWarn && sessionOf(ctx).terminal()
+}
+
+func startProgress(ctx context.Context, opts Options, label string, counter *atomic.Int64) func() {
+	if !progressEnabled(ctx, opts) {
+		return func() {}
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	start := time.Now()
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		drawn := false
+		for frame := 0; ; frame++ {
+			select {
+			case <-stop:
+				if drawn {
+					fmt.Fprint(stderr(ctx), "\r\x1b[K")
+				}
+				return
+			case <-ticker.C:
+			}
+			elapsed := time.Since(start)
+			if elapsed < 300*time.Millisecond {
+				continue
+			}
+			detail := fmt.Sprintf("%ds", int(elapsed.Seconds()))
+			if counter != nil && counter.Load() > 0 {
+				detail = strings.TrimPrefix(formatSizeDelta(counter.Load()), "+")
+			}
+			fmt.Fprintf(stderr(ctx), "\r\x1b[K%s %s… %s", spinnerFrames[frame%len(spinnerFrames)], label, detail)
+			drawn = true
+		}
+	}()
+	return func() {
+		close(stop)
+		<-done
+	}
+}
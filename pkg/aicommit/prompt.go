@@ -0,0 +1,93 @@
+package aicommit
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+type prompter struct {
+	in        *bufio.Reader
+	out       io.Writer
+	assumeYes bool
+}
+
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{in: bufio.NewReader(in), out: out}
+}
+
+func (p *prompter) ask(question, def string) (string, error) {
+	if p.assumeYes {
+		return def, nil
+	}
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return def, nil
+	}
+	return line, nil
+}
+
+func (p *prompter) choose(question string, choices []string, def string) (string, error) {
+	for {
+		answer, err := p.ask(question+" ("+strings.Join(choices, "/")+")", def)
+		if err != nil {
+			return "", err
+		}
+		for _, c := range choices {
+			if strings.EqualFold(answer, c) {
+				return c, nil
+			}
+		}
+		fmt.Fprintf(p.out, "please choose one of: %s\n", strings.Join(choices, ", "))
+	}
+}
+
+func (p *prompter) confirm(question string, def bool) (bool, error) {
+	if p.assumeYes {
+		return def, nil
+	}
+	defAnswer := "y/N"
+	if def {
+		defAnswer = "Y/n"
+	}
+	answer, err := p.ask(question+" ("+defAnswer+")", "")
+	if err != nil {
+		return false, err
+	}
+	if answer == "" {
+		return def, nil
+	}
+	val, ok := parseBool(answer)
+	if !ok {
+		return def, nil
+	}
+	return val, nil
+}
+
+func (p *prompter) secret(question string) (string, error) {
+	if stty, err := exec.LookPath("stty"); err == nil {
+		off := exec.Command(stty, "-echo")
+		off.Stdin = os.Stdin
+		if off.Run() == nil {
+			defer func() {
+				on := exec.Command(stty, "echo")
+				on.Stdin = os.Stdin
+				_ = on.Run()
+				fmt.Fprintln(p.out)
+			}()
+		}
+	}
+	return p.ask(question, "")
+}
@@ -0,0 +1,155 @@
+package aicommit
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/skrashevich/aicommit/pkg/llm"
+)
+
+func runReleaseNotes(ctx context.Context, args []string, cfg *config, out io.Writer) error {
+	opts, err := parseFlags(ctx, cfg, nil)
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("release-notes", flag.ContinueOnError)
+	lang := fs.String("lang", opts.Lang, "auto|en|ru")
+	maxDiff := fs.Int("max-diff", max(opts.LLMMaxDiff, 20000), "max diff bytes to send to the LLM")
+	heuristic := fs.Bool("no-llm", false, "build the notes from commit subjects only")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr(ctx), "Usage: aicommit release-notes [-lang ru] [-no-llm] [A..B]")
+		fmt.Fprintln(stderr(ctx), "Without a range, notes cover the commits since the latest tag.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := ensureGit(); err != nil {
+		return err
+	}
+	opts.Lang = *lang
+	if opts.Lang == "auto" || opts.Lang == "" {
+		opts.Lang = detectLang()
+	}
+
+	rng := fs.Arg(0)
+	if rng == "" {
+		r := resolveChangelogRange(ctx, "", "HEAD", "")
+		rng = "HEAD"
+		if r.Since != "" {
+			rng = r.Since + "..HEAD"
+		}
+	}
+	commits, err := logCommits(ctx, "--no-merges", rng)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		return fmt.Errorf("no commits in %s", rng)
+	}
+
+	if !*heuristic {
+		notes, err := releaseNotesWithLLM(ctx, opts, rng, commits, *maxDiff)
+		if err == nil {
+			fmt.Fprintln(out, notes)
+			return nil
+		}
+		if opts.LLMStrict {
+			return err
+		}
+		fmt.Fprintln(stderr(ctx), "llm failed, using commit subjects:", err)
+	}
+	fmt.Fprint(out, heuristicReleaseNotes(commits, opts.Lang))
+	return nil
+}
+
+func releaseNotesWithLLM(ctx context.Context, opts Options, rng string, commits []loggedCommit, maxDiff int) (string, error) {
+	diffRange := rng
+	if !strings.Contains(rng, "..") {
+		empty, _ := gitOutput(ctx, "hash-object", "-t", "tree", "/dev/null")
+		diffRange = empty + ".." + rng
+	}
+	stat, _ := gitOutput(ctx, "diff", "--stat", diffRange)
+	diff, _ := gitOutput(ctx, "diff", "-U0", diffRange)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Requirements:\n")
+	fmt.Fprintf(&b, "- Language: %s\n", opts.Lang)
+	fmt.Fprintf(&b, "- Write Markdown release notes for %s with the sections Highlights, Fixes and Breaking Changes (omit empty sections).\n", rng)
+	fmt.Fprintf(&b, "- Describe user-visible effects, group related commits, skip internal chores.\n")
+	fmt.Fprintf(&b, "\nCommits:\n")
+	for _, c := range commits {
+		fmt.Fprintf(&b, "- %s\n", c.Parsed.Header)
+		for _, note := range breakingNotes(c) {
+			fmt.Fprintf(&b, "  BREAKING CHANGE: %s\n", note)
+		}
+	}
+	if stat != "" {
+		fmt.Fprintf(&b, "\nStats:\n%s\n", stat)
+	}
+	trimmed, truncated := llm.TruncateDiff(diff, maxDiff)
+	if strings.TrimSpace(trimmed) != "" {
+		if truncated {
+			fmt.Fprintf(&b, "\nDiff (truncated to %d bytes):\n", maxDiff)
+		} else {
+			fmt.Fprintf(&b, "\nDiff:\n")
+		}
+		fmt.Fprintln(&b, trimmed)
+	}
+
+	system := strings.Join([]string{
+		"You write release notes for a software project.",
+		"Return ONLY the Markdown release notes, no preface.",
+		"Use only the provided commits and diff; do not invent changes.",
+	}, " ")
+	opts.LLMMaxTokens = max(opts.LLMMaxTokens, 1500)
+	notes, err := completeChat(ctx, opts, system, strings.TrimSpace(b.String()))
+	if err != nil {
+		return "", err
+	}
+	notes = llm.CleanMessage(notes)
+	if notes == "" {
+		return "", fmt.Errorf("llm response content is empty")
+	}
+	return notes, nil
+}
+
+func heuristicReleaseNotes(commits []loggedCommit, lang string) string {
+	titles := []string{"Highlights", "Fixes", "Breaking Changes"}
+	if lang == "ru" {
+		titles = []string{"Главное", "Исправления", "Несовместимые изменения"}
+	}
+	var sections [3][]string
+	for _, c := range commits {
+		switch c.Parsed.Type {
+		case "feat", "perf":
+			sections[0] = append(sections[0], changelogEntry(c))
+		case "fix":
+			sections[1] = append(sections[1], changelogEntry(c))
+		}
+		sections[2] = append(sections[2], breakingNotes(c)...)
+	}
+	var b strings.Builder
+	for i, entries := range sections {
+		if len(entries) == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "## %s\n\n", titles[i])
+		for _, entry := range entries {
+			fmt.Fprintf(&b, "- %s\n", entry)
+		}
+	}
+	if b.Len() == 0 {
+		fmt.Fprintf(&b, "## %s\n\n", titles[0])
+		for _, c := range commits {
+			fmt.Fprintf(&b, "- %s\n", changelogEntry(c))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,158 @@
+package aicommit
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+const bodyRenames BodyMode = "renames"
+
+type renameGroup struct {
+	From  string
+	To    string
+	Count int
+}
+
+func allRenames(changes []Change) bool {
+	if len(changes) == 0 {
+		return false
+	}
+	for _, ch := range changes {
+		if ch.Status != "R" || ch.OldPath == "" || ch.Similarity < 100 {
+			return false
+		}
+	}
+	return true
+}
+
+func groupRenames(changes []Change) []renameGroup {
+	byKey := map[string]*renameGroup{}
+	for _, ch := range changes {
+		from := path.Dir(ch.OldPath)
+		to := path.Dir(ch.Path)
+		key := from + "\x00" + to
+		g, ok := byKey[key]
+		if !ok {
+			g = &renameGroup{From: from, To: to}
+			byKey[key] = g
+		}
+		g.Count++
+	}
+	out := make([]renameGroup, 0, len(byKey))
+	for _, g := range byKey {
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].From < out[j].From
+	})
+	return out
+}
+
+func renameSubject(changes []Change, lang string) string {
+	if len(changes) == 1 {
+		ch := changes[0]
+		if path.Dir(ch.OldPath) == path.Dir(ch.Path) {
+			if lang == "ru" {
+				return fmt.Sprintf("Переименуй %s в %s", path.Base(ch.OldPath), path.Base(ch.Path))
+			}
+			return fmt.Sprintf("Rename %s to %s", path.Base(ch.OldPath), path.Base(ch.Path))
+		}
+		if lang == "ru" {
+			return fmt.Sprintf("Перенеси %s в %s", path.Base(ch.OldPath), displayDir(path.Dir(ch.Path)))
+		}
+		return fmt.Sprintf("Move %s into %s", path.Base(ch.OldPath), displayDir(path.Dir(ch.Path)))
+	}
+	groups := groupRenames(changes)
+	if len(groups) == 1 {
+		g := groups[0]
+		if g.From == g.To {
+			if lang == "ru" {
+				return fmt.Sprintf("Переименуй файлы в %s", displayDir(g.From))
+			}
+			return fmt.Sprintf("Rename files in %s", displayDir(g.From))
+		}
+		if lang == "ru" {
+			return fmt.Sprintf("Перенеси %s в %s", displayDir(g.From), displayDir(g.To))
+		}
+		return fmt.Sprintf("Move %s into %s", displayDir(g.From), displayDir(g.To))
+	}
+	target := commonDir(changes)
+	if lang == "ru" {
+		if target == "" {
+			return "Реорганизуй структуру каталогов"
+		}
+		return "Реорганизуй " + displayDir(target)
+	}
+	if target == "" {
+		return "Restructure directories"
+	}
+	return "Restructure " + displayDir(target)
+}
+
+func buildRenameLines(changes []Change, maxItems int, lang string) []string {
+	groups := groupRenames(changes)
+	limit := len(groups)
+	if maxItems > 0 && limit > maxItems {
+		limit = maxItems
+	}
+	var lines []string
+	for i := 0; i < limit; i++ {
+		g := groups[i]
+		if lang == "ru" {
+			lines = append(lines, fmt.Sprintf("- перемещено файлов: %d из %s в %s", g.Count, displayDir(g.From), displayDir(g.To)))
+		} else {
+			lines = append(lines, fmt.Sprintf("- moved %d %s from %s to %s", g.Count, pluralFiles(g.Count), displayDir(g.From), displayDir(g.To)))
+		}
+	}
+	if limit < len(groups) {
+		remaining := len(groups) - limit
+		if lang == "ru" {
+			lines = append(lines, fmt.Sprintf("- и еще %d", remaining))
+		} else {
+			lines = append(lines, fmt.Sprintf("- and %d more", remaining))
+		}
+	}
+	return lines
+}
+
+func commonDir(changes []Change) string {
+	var parts []string
+	for i, ch := range changes {
+		for j, p := range []string{ch.OldPath, ch.Path} {
+			dirParts := strings.Split(path.Dir(p), "/")
+			if i == 0 && j == 0 {
+				parts = dirParts
+				continue
+			}
+			n := 0
+			for n < len(parts) && n < len(dirParts) && parts[n] == dirParts[n] {
+				n++
+			}
+			parts = parts[:n]
+		}
+	}
+	dir := strings.Join(parts, "/")
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
+
+func displayDir(dir string) string {
+	if dir == "." || dir == "" {
+		return "./"
+	}
+	return dir + "/"
+}
+
+func pluralFiles(n int) string {
+	if n == 1 {
+		return "file"
+	}
+	return "files"
+}
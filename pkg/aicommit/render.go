@@ -0,0 +1,192 @@
+package aicommit
+
+import (
+	"os"
+	"strings"
+
+	"github.com/skrashevich/aicommit/pkg/detect"
+	"github.com/skrashevich/aicommit/pkg/render"
+)
+
+func validFormat(format Format) bool {
+	switch format {
+	case FormatConventional, FormatPlain, FormatGitmoji:
+		return true
+	default:
+		return false
+	}
+}
+
+func validBody(body BodyMode) bool {
+	switch body {
+	case BodyAuto, BodyNone, BodyFiles, BodyStats, BodySummary:
+		return true
+	default:
+		return false
+	}
+}
+
+func validMode(mode Mode) bool {
+	switch mode {
+	case ModeAuto, ModeStaged, ModeUnstaged, ModeAll:
+		return true
+	default:
+		return false
+	}
+}
+
+func detectLang() string {
+	for _, key := range []string{"LC_ALL", "LANG"} {
+		val := strings.ToLower(strings.TrimSpace(os.Getenv(key)))
+		if val == "" {
+			continue
+		}
+		if strings.HasPrefix(val, "ru") || strings.Contains(val, "_ru") {
+			return "ru"
+		}
+		return "en"
+	}
+	return "en"
+}
+
+func buildSubject(commitType, scope string, changes []Change, diff string, assets []assetChange, opts Options) string {
+	if allRenames(changes) {
+		return renameSubject(changes, opts.Lang)
+	}
+	if allI18n(changes) {
+		return i18nSubject(changes, opts.Lang)
+	}
+	if allConfig(changes) {
+		return configSubject(changes, diff, opts.Lang)
+	}
+	if allContracts(changes) {
+		return contractSubject(changes, diff, opts.Lang)
+	}
+	if len(assets) > 0 && len(assets) == len(changes) {
+		return assetSubject(assets, opts.Lang)
+	}
+	verb, defaultTarget := render.Verb(commitType, opts.Lang)
+	target := inferTarget(changes, scope)
+	if target == "" {
+		target = defaultTarget
+	}
+	if target == "" {
+		if opts.Lang == "ru" {
+			target = "изменения"
+		} else {
+			target = "changes"
+		}
+	}
+	subject := strings.TrimSpace(verb + " " + target)
+	return subject
+}
+
+func inferTarget(changes []Change, scope string) string {
+	if len(changes) == 1 {
+		return detect.PrimaryArea(changes[0].Path)
+	}
+	if scope != "" {
+		return scope
+	}
+	counts := map[string]int{}
+	for _, ch := range changes {
+		area := detect.PrimaryArea(ch.Path)
+		if area != "" {
+			counts[area]++
+		}
+	}
+	if len(counts) == 0 {
+		return ""
+	}
+	best := ""
+	bestCount := 0
+	tie := false
+	for area, count := range counts {
+		if count > bestCount {
+			best = area
+			bestCount = count
+			tie = false
+			continue
+		}
+		if count == bestCount {
+			tie = true
+		}
+	}
+	if tie {
+		return ""
+	}
+	return best
+}
+
+func formatMessage(commitType, scope, subject, body string, opts Options, breaking bool) string {
+	msg := render.Header(commitType, scope, subject, opts.Format, opts.Emoji, breaking, opts.MaxSubject)
+	if opts.preset != nil {
+		msg = opts.preset.header(commitType, scope, render.Subject(subject, opts.Format, opts.MaxSubject), breaking)
+	}
+	if body != "" {
+		msg += "\n\n" + body
+	}
+	return msg
+}
+
+func buildBody(cs ChangeSet, assets []assetChange, opts Options, breaking bool, breakingNote string) string {
+	changes, diff, stats := cs.Changes, cs.Diff, cs.Stats
+	bodyMode := opts.Body
+	if bodyMode == BodyAuto {
+		if len(changes) == 0 {
+			bodyMode = BodyNone
+		} else if allRenames(changes) {
+			bodyMode = bodyRenames
+		} else if allI18n(changes) {
+			bodyMode = bodyLocales
+		} else if allConfig(changes) {
+			bodyMode = bodyConfig
+		} else if len(withoutAssets(changes, assets)) <= opts.MaxItems {
+			bodyMode = BodyFiles
+		} else {
+			bodyMode = BodySummary
+		}
+	}
+
+	var content []string
+	switch bodyMode {
+	case BodyFiles:
+		content = render.FileLines(withoutAssets(changes, assets), opts.MaxItems, opts.Lang)
+		content = append(content, buildAssetLines(assets, opts.Lang)...)
+	case bodyRenames:
+		content = buildRenameLines(changes, opts.MaxItems, opts.Lang)
+	case bodyLocales:
+		content = buildLocaleLines(changes, opts.MaxItems, opts.Lang)
+	case bodyConfig:
+		content = buildConfigLines(changes, diff, opts.MaxItems, opts.Lang)
+	case BodyStats:
+		if len(stats) == 0 {
+			content = []string{render.SummaryLine(changes, opts.Lang)}
+		} else {
+			content = render.StatLines(stats, opts.MaxItems, opts.Lang)
+		}
+	case BodySummary:
+		content = []string{render.SummaryLine(changes, opts.Lang)}
+	}
+
+	if bodyMode != BodyNone {
+		content = append(content, buildTodoLines(findResolvedTodos(diff), opts.MaxItems, opts.Lang)...)
+		content = append(content, buildBenchLines(collectBenchResults(diff), opts.MaxItems)...)
+	}
+
+	var footers []string
+	if breaking {
+		footers = append(footers, render.BreakingFooter(breakingNote, opts.Lang))
+	}
+	footers = append(footers, issueFooterLines("Refs", opts.Refs, opts.Issues, opts.IssueTitles)...)
+	footers = append(footers, issueFooterLines("Closes", opts.Closes, opts.Issues, opts.IssueTitles)...)
+
+	lines := content
+	if len(footers) > 0 {
+		if len(content) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, footers...)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,190 @@
+package aicommit
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func runReword(ctx context.Context, args []string, cfg *config, out io.Writer) error {
+	fs := flag.NewFlagSet("reword", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "print the new message without rewriting history")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr(ctx), "Usage: aicommit reword [-dry-run] <commit>")
+		fmt.Fprintln(stderr(ctx), "Regenerates the message of an unpushed commit from its diff and rewrites the branch.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("reword needs exactly one commit")
+	}
+	if err := ensureGit(); err != nil {
+		return err
+	}
+	sha, err := gitOutput(ctx, "rev-parse", "--verify", "-q", fs.Arg(0)+"^{commit}")
+	if err != nil {
+		return fmt.Errorf("unknown commit %s", fs.Arg(0))
+	}
+	opts, err := parseFlags(ctx, cfg, nil)
+	if err == nil {
+		opts, err = normalizeOptions(ctx, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	message, err := commitMessageFor(ctx, opts, sha)
+	if err != nil {
+		return err
+	}
+	old, _ := gitOutput(ctx, "log", "-1", "--format=%B", sha)
+	if message, err = keepTrailers(ctx, old, message); err != nil {
+		return err
+	}
+	if *dryRun {
+		fmt.Fprintf(out, "%s\n--- old\n%s\n+++ new\n%s\n", shortSHA(sha), strings.TrimSpace(old), message)
+		return nil
+	}
+	chain, err := rewritableChain(ctx, sha)
+	if err != nil {
+		return err
+	}
+	head, err := replayCommits(ctx, chain, map[string]string{sha: message}, "aicommit: reword")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "reworded %s (HEAD is now %s)\n%s\n", shortSHA(sha), shortSHA(head), message)
+	return nil
+}
+
+func commitMessageFor(ctx context.Context, opts Options, sha string) (string, error) {
+	if parents, _ := gitOutput(ctx, "rev-list", "--parents", "-n", "1", sha); len(strings.Fields(parents)) > 2 {
+		return "", fmt.Errorf("%s is a merge commit", shortSHA(sha))
+	}
+	diff, err := gitOutput(ctx, "show", "--format=", "-U0", "-M", sha)
+	if err != nil {
+		return "", err
+	}
+	st, err := diffState(ctx, opts, diff)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", shortSHA(sha), err)
+	}
+	gen, err := generateFrom(ctx, opts, st)
+	if err != nil {
+		return "", err
+	}
+	return gen.Message, nil
+}
+
+func keepTrailers(ctx context.Context, old, message string) (string, error) {
+	parse := gitCommand(ctx, "interpret-trailers", "--parse")
+	parse.Stdin = strings.NewReader(old)
+	raw, err := parse.Output()
+	if err != nil {
+		return "", fmt.Errorf("git interpret-trailers: %w", err)
+	}
+	args := []string{"interpret-trailers", "--if-exists", "addIfDifferent"}
+	for _, line := range strings.Split(string(raw), "\n") {
+		if line != "" {
+			args = append(args, "--trailer", line)
+		}
+	}
+	if len(args) == 3 {
+		return message, nil
+	}
+	cmd := gitCommand(ctx, args...)
+	cmd.Stdin = strings.NewReader(strings.TrimRight(message, "\n") + "\n")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git interpret-trailers: %w", err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+func remoteRefsContaining(ctx context.Context, sha string) (string, error) {
+	remotes, err := gitOutput(ctx, "for-each-ref", "--contains", sha, "--format=%(refname:short)", "refs/remotes")
+	return strings.ReplaceAll(remotes, "\n", ", "), err
+}
+
+func rewritableChain(ctx context.Context, oldest string) ([]string, error) {
+	if err := gitCommand(ctx, "merge-base", "--is-ancestor", oldest, "HEAD").Run(); err != nil {
+		return nil, fmt.Errorf("%s is not an ancestor of HEAD", shortSHA(oldest))
+	}
+	remotes, err := remoteRefsContaining(ctx, oldest)
+	if err != nil {
+		return nil, err
+	}
+	if remotes != "" {
+		return nil, fmt.Errorf("%s was already pushed (%s); refusing to rewrite", shortSHA(oldest), remotes)
+	}
+	revs := []string{"rev-list", "--reverse", "--parents", "HEAD"}
+	if _, err := gitOutput(ctx, "rev-parse", "--verify", "-q", oldest+"^"); err == nil {
+		revs = append(revs, "^"+oldest+"^")
+	}
+	raw, err := gitOutput(ctx, revs...)
+	if err != nil {
+		return nil, err
+	}
+	var chain []string
+	for _, line := range strings.Split(raw, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("merge commit %s in the range; rewrite it with git rebase", shortSHA(fields[0]))
+		}
+		if len(fields) > 0 {
+			chain = append(chain, fields[0])
+		}
+	}
+	return chain, nil
+}
+
+func replayCommits(ctx context.Context, chain []string, messages map[string]string, reason string) (string, error) {
+	if len(chain) == 0 {
+		return "", errors.New("nothing to rewrite")
+	}
+	oldHead := chain[len(chain)-1]
+	parent, _ := gitOutput(ctx, "rev-parse", "--verify", "-q", chain[0]+"^")
+	for _, sha := range chain {
+		info, err := gitOutput(ctx, "log", "-1", "--date=raw", "--format=%T%x00%an%x00%ae%x00%ad", sha)
+		if err != nil {
+			return "", err
+		}
+		fields := strings.Split(info, "\x00")
+		if len(fields) != 4 {
+			return "", fmt.Errorf("cannot read %s", shortSHA(sha))
+		}
+		message, ok := messages[sha]
+		if !ok {
+			raw, err := gitOutput(ctx, "cat-file", "commit", sha)
+			if err != nil {
+				return "", err
+			}
+			_, message, _ = strings.Cut(raw, "\n\n")
+		}
+		args := []string{"commit-tree", fields[0], "-F", "-"}
+		if parent != "" {
+			args = append(args, "-p", parent)
+		}
+		cmd := gitCommand(ctx, args...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME="+fields[1], "GIT_AUTHOR_EMAIL="+fields[2], "GIT_AUTHOR_DATE="+fields[3])
+		cmd.Stdin = strings.NewReader(strings.TrimRight(message, "\n") + "\n")
+		infof(ctx, "git %s (%s)", strings.Join(args, " "), shortSHA(sha))
+		created, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("git commit-tree %s: %w", shortSHA(sha), err)
+		}
+		parent = strings.TrimSpace(string(created))
+	}
+	infof(ctx, "git update-ref HEAD %s %s", parent, oldHead)
+	if output, err := gitCommand(ctx, "update-ref", "-m", reason, "HEAD", parent, oldHead).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("git update-ref: %s", strings.TrimSpace(string(output)))
+	}
+	return parent, nil
+}
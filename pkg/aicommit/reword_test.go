@@ -0,0 +1,63 @@
+package aicommit
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/skrashevich/aicommit/internal/fixture"
+)
+
+func TestRewordKeepsTrailers(t *testing.T) {
+	r := fixture.New(t)
+	r.Write("README.md", "# demo\n").Commit("initial commit")
+	r.Write("docs/guide.md", "# guide\n").Stage()
+	r.Git("commit", "-q", "-s", "-m", "wip", "-m", "Change-Id: I0123456789abcdef")
+	enterRepo(t, r)
+	ctx := context.Background()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runReword(ctx, []string{"HEAD"}, cfg, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	message := r.Git("log", "-1", "--format=%B")
+	if strings.HasPrefix(message, "wip") {
+		t.Fatalf("message was not reworded:\n%s", message)
+	}
+	for _, trailer := range []string{"Signed-off-by: Fixture <fixture@example.com>", "Change-Id: I0123456789abcdef"} {
+		if strings.Count(message, trailer) != 1 {
+			t.Errorf("reworded message does not keep %q once:\n%s", trailer, message)
+		}
+	}
+}
+
+func TestRewriteKeepsTrailers(t *testing.T) {
+	r := fixture.New(t)
+	r.Write("README.md", "# demo\n").Commit("initial commit")
+	base := r.Git("rev-parse", "HEAD")
+	for i, path := range []string{"docs/a.md", "docs/b.md"} {
+		r.Write(path, "# doc\n").Stage()
+		r.Git("commit", "-q", "-m", "wip", "-m", "Change-Id: I00"+strconv.Itoa(i))
+	}
+	enterRepo(t, r)
+	ctx := context.Background()
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runRewrite(ctx, []string{base + "..HEAD"}, cfg, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	for i, rev := range []string{"HEAD~1", "HEAD"} {
+		message := r.Git("log", "-1", "--format=%B", rev)
+		if strings.HasPrefix(message, "wip") || !strings.HasSuffix(message, "Change-Id: I00"+strconv.Itoa(i)) {
+			t.Errorf("%s was not rewritten with its Change-Id:\n%s", rev, message)
+		}
+	}
+}
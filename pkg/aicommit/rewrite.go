@@ -0,0 +1,194 @@
+package aicommit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/skrashevich/aicommit/pkg/llm"
+)
+
+type rewriteEntry struct {
+	SHA     string
+	Old     string
+	New     string
+	Prompts [2]string
+}
+
+func runRewrite(ctx context.Context, args []string, cfg *config, out io.Writer) error {
+	fs := flag.NewFlagSet("rewrite", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "print the new messages without rewriting history")
+	batch := fs.Int("batch", 5, "commits per LLM request")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr(ctx), "Usage: aicommit rewrite [-dry-run] [-batch 5] <base>..HEAD")
+		fmt.Fprintln(stderr(ctx), "Regenerates the messages of every unpushed commit after <base> and rewrites the branch.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("rewrite needs a range like main..HEAD")
+	}
+	base, head, _ := strings.Cut(fs.Arg(0), "..")
+	if head != "" && head != "HEAD" {
+		return errors.New("rewrite only works on ranges ending at HEAD")
+	}
+	if err := ensureGit(); err != nil {
+		return err
+	}
+	opts, err := parseFlags(ctx, cfg, nil)
+	if err == nil {
+		opts, err = normalizeOptions(ctx, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	raw, err := gitOutput(ctx, "rev-list", "--reverse", base+"..HEAD")
+	if err != nil {
+		return fmt.Errorf("unknown range %s", fs.Arg(0))
+	}
+	if raw == "" {
+		return fmt.Errorf("no commits in %s..HEAD", base)
+	}
+	shas := strings.Split(raw, "\n")
+	chain, err := rewritableChain(ctx, shas[0])
+	if err != nil {
+		return err
+	}
+	if len(chain) != len(shas) {
+		return fmt.Errorf("%s..HEAD is not a linear history; rewrite it with git rebase", base)
+	}
+
+	entries, err := draftRewrites(ctx, opts, shas)
+	if err != nil {
+		return err
+	}
+	if opts.LLMEnabled {
+		if err := rewriteBatches(ctx, opts, entries, max(*batch, 1)); err != nil {
+			if opts.LLMStrict {
+				return err
+			}
+			fmt.Fprintf(stderr(ctx), "llm failed, using heuristic:\n%v\n", err)
+		}
+	}
+
+	messages := map[string]string{}
+	for i, e := range entries {
+		if e.New != e.Old {
+			if e.New, err = keepTrailers(ctx, e.Old, e.New); err != nil {
+				return err
+			}
+			entries[i].New = e.New
+		}
+		fmt.Fprintf(out, "%s %s\n    -> %s\n", shortSHA(e.SHA), firstLine(e.Old), firstLine(e.New))
+		messages[e.SHA] = e.New
+	}
+	if *dryRun {
+		return nil
+	}
+	newHead, err := replayCommits(ctx, chain, messages, "aicommit: rewrite")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "rewrote %d commits (HEAD is now %s; previous HEAD in HEAD@{1})\n", len(entries), shortSHA(newHead))
+	return nil
+}
+
+func draftRewrites(ctx context.Context, opts Options, shas []string) ([]rewriteEntry, error) {
+	heuristic := opts
+	heuristic.LLMEnabled = false
+	entries := make([]rewriteEntry, 0, len(shas))
+	for _, sha := range shas {
+		old, _ := gitOutput(ctx, "log", "-1", "--format=%B", sha)
+		diff, err := gitOutput(ctx, "show", "--format=", "-U0", "-M", sha)
+		if err != nil {
+			return nil, err
+		}
+		st, err := diffState(ctx, heuristic, diff)
+		if err != nil {
+			entries = append(entries, rewriteEntry{SHA: sha, Old: old, New: old})
+			continue
+		}
+		gen, err := generateFrom(ctx, heuristic, st)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", shortSHA(sha), err)
+		}
+		e := gen.Explain
+		system, user := buildLLMPrompts(opts, st, e.Type, e.Scope, e.Breaking, e.BreakingNote, gen.Message, e.Reasons)
+		entries = append(entries, rewriteEntry{SHA: sha, Old: old, New: gen.Message, Prompts: [2]string{system, user}})
+	}
+	return entries, nil
+}
+
+func rewriteBatches(ctx context.Context, opts Options, entries []rewriteEntry, size int) error {
+	var parts [][]rewriteEntry
+	for i := 0; i < len(entries); i += size {
+		parts = append(parts, entries[i:min(i+size, len(entries))])
+	}
+	stop := startProgress(ctx, opts, fmt.Sprintf("querying %s for %d batches", opts.LLMModel, len(parts)), nil)
+	defer stop()
+	quiet := opts
+	quiet.Quiet = true
+	errs := make([]error, len(parts))
+	g, ctx := newTaskGroup(ctx)
+	g.SetLimit(opts.LLMParallel)
+	for i, part := range parts {
+		g.Go(func() error {
+			if err := rewriteWithLLM(ctx, quiet, part); err != nil {
+				errs[i] = fmt.Errorf("commits %s..%s: %w", shortSHA(part[0].SHA), shortSHA(part[len(part)-1].SHA), err)
+			}
+			return nil
+		})
+	}
+	g.Wait()
+	return errors.Join(errs...)
+}
+
+func rewriteWithLLM(ctx context.Context, opts Options, entries []rewriteEntry) error {
+	var b strings.Builder
+	var targets []*rewriteEntry
+	for i := range entries {
+		e := &entries[i]
+		if e.Prompts[1] == "" {
+			continue
+		}
+		targets = append(targets, e)
+		fmt.Fprintf(&b, "### Commit %d\n\nCurrent message:\n%s\n\n%s\n\n", len(targets), strings.TrimSpace(e.Old), e.Prompts[1])
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+	system := targets[0].Prompts[0] + fmt.Sprintf("\n\nYou receive %d commits of one branch. Write a new message for each, using its diff; the current message only hints at intent. Return ONLY a JSON array of %d strings, in the same order.", len(targets), len(targets))
+	opts.LLMMaxTokens = max(opts.LLMMaxTokens, 300) * len(targets)
+	reply, err := completeChat(ctx, opts, system, strings.TrimSpace(b.String()))
+	if err != nil {
+		return err
+	}
+	reply = strings.TrimSpace(reply)
+	reply = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(reply, "```json"), "```"), "```")
+	var messages []string
+	if err := json.Unmarshal([]byte(strings.TrimSpace(reply)), &messages); err != nil {
+		return fmt.Errorf("llm response is not a JSON array: %w", err)
+	}
+	if len(messages) != len(targets) {
+		return fmt.Errorf("llm returned %d messages for %d commits", len(messages), len(targets))
+	}
+	for i, e := range targets {
+		if message := llm.CleanMessage(messages[i]); message != "" {
+			e.New = message
+		}
+	}
+	return nil
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
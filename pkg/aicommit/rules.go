@@ -0,0 +1,196 @@
+package aicommit
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/skrashevich/aicommit/pkg/detect"
+)
+
+type Rule struct {
+	Path   *regexp.Regexp
+	Diff   *regexp.Regexp
+	Type   string
+	Scope  string
+	Reason string
+}
+
+func loadRulesFile(file string) ([]Rule, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var rules []Rule
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule, err := parseRuleLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", file, lineNo, err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func parseRuleLine(line string) (Rule, error) {
+	match, action, ok := strings.Cut(line, "=>")
+	if !ok {
+		return Rule{}, fmt.Errorf("rule must have the form '<path|diff> <regex> => key=value ...'")
+	}
+	target, pattern, ok := strings.Cut(strings.TrimSpace(match), " ")
+	if !ok {
+		return Rule{}, fmt.Errorf("rule is missing a regex")
+	}
+	fields := map[string]string{strings.TrimSpace(target): strings.TrimSpace(pattern)}
+	for _, kv := range splitRuleActions(action) {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			return Rule{}, fmt.Errorf("invalid rule action %q", kv)
+		}
+		fields[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	return newRule(fields)
+}
+
+func newRule(fields map[string]string) (Rule, error) {
+	var rule Rule
+	for key, value := range fields {
+		var err error
+		switch key {
+		case "path":
+			rule.Path, err = regexp.Compile(value)
+		case "diff":
+			rule.Diff, err = regexp.Compile(value)
+		case "type":
+			rule.Type = strings.ToLower(value)
+		case "scope":
+			rule.Scope = value
+		case "reason":
+			rule.Reason = value
+		default:
+			return Rule{}, fmt.Errorf("unknown rule key %q", key)
+		}
+		if err != nil {
+			return Rule{}, fmt.Errorf("invalid %s regex: %w", key, err)
+		}
+	}
+	if rule.Path == nil && rule.Diff == nil {
+		return Rule{}, fmt.Errorf("rule needs a path or diff regex")
+	}
+	if rule.Type == "" && rule.Scope == "" {
+		return Rule{}, fmt.Errorf("rule needs a type or scope")
+	}
+	return rule, nil
+}
+
+func splitRuleActions(raw string) []string {
+	var out []string
+	var cur strings.Builder
+	quoted := false
+	for _, r := range raw {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			cur.WriteRune(r)
+		case (r == ' ' || r == '\t') && !quoted:
+			if cur.Len() > 0 {
+				out = append(out, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if cur.Len() > 0 {
+		out = append(out, cur.String())
+	}
+	return out
+}
+
+func (r Rule) matches(changes []Change, diffLines []string) bool {
+	if r.Path != nil {
+		if len(changes) == 0 {
+			return false
+		}
+		for _, ch := range changes {
+			if !r.Path.MatchString(ch.Path) {
+				return false
+			}
+		}
+	}
+	if r.Diff != nil {
+		found := false
+		for _, line := range diffLines {
+			if r.Diff.MatchString(line) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (r Rule) describe() string {
+	if r.Reason != "" {
+		return r.Reason
+	}
+	if r.Path != nil {
+		return "path " + r.Path.String()
+	}
+	return "diff " + r.Diff.String()
+}
+
+func changedLines(diff string) []string {
+	var out []string
+	for _, line := range strings.Split(diff, "\n") {
+		if line == "" || detect.IsDiffHeader(line) {
+			continue
+		}
+		if line[0] == '+' || line[0] == '-' {
+			out = append(out, line[1:])
+		}
+	}
+	return out
+}
+
+func ruleType(rules []Rule, changes []Change, diff string) (string, string, bool) {
+	if len(rules) == 0 {
+		return "", "", false
+	}
+	lines := changedLines(diff)
+	for _, r := range rules {
+		if r.Type != "" && r.matches(changes, lines) {
+			return r.Type, r.describe(), true
+		}
+	}
+	return "", "", false
+}
+
+func ruleScope(rules []Rule, changes []Change, diff string) (string, bool) {
+	if len(rules) == 0 {
+		return "", false
+	}
+	lines := changedLines(diff)
+	for _, r := range rules {
+		if r.Scope != "" && r.matches(changes, lines) {
+			return r.Scope, true
+		}
+	}
+	return "", false
+}
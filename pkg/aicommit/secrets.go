@@ -0,0 +1,100 @@
+package aicommit
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var secretPatterns = []struct {
+	Kind string
+	Re   *regexp.Regexp
+}{
+	{"private key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+	{"AWS access key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b|\bgithub_pat_[A-Za-z0-9_]{22,}`)},
+	{"GitLab token", regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"OpenAI key", regexp.MustCompile(`\bsk-(?:proj-|or-v1-)?[A-Za-z0-9_-]{20,}`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"Stripe key", regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{24,}`)},
+	{"JWT", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{"credential assignment", regexp.MustCompile(`(?i)\b(?:password|passwd|secret|api[_-]?key|access[_-]?token|auth[_-]?token|client[_-]?secret)\b["']?\s*[:=]\s*["'][^"'\s]{8,}["']`)},
+}
+
+type secretFinding struct {
+	Path string
+	Kind string
+}
+
+type secretsError struct {
+	Findings []secretFinding
+}
+
+func (e *secretsError) Error() string {
+	byPath := map[string][]string{}
+	for _, f := range e.Findings {
+		if !strings.Contains(strings.Join(byPath[f.Path], ","), f.Kind) {
+			byPath[f.Path] = append(byPath[f.Path], f.Kind)
+		}
+	}
+	paths := make([]string, 0, len(byPath))
+	for p := range byPath {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	var b strings.Builder
+	b.WriteString("possible secrets in content for the LLM, request not sent:")
+	for _, p := range paths {
+		fmt.Fprintf(&b, "\n  %s: %s", p, strings.Join(byPath[p], ", "))
+	}
+	b.WriteString("\nremove them, exclude the files with -exclude, or pass -allow-secrets to send anyway")
+	return b.String()
+}
+
+func scanSecrets(text string) []secretFinding {
+	var out []secretFinding
+	path := "(prompt)"
+	for _, line := range strings.Split(text, "\n") {
+		if rest, ok := strings.CutPrefix(line, "diff --git a/"); ok {
+			if _, b, ok := strings.Cut(rest, " b/"); ok {
+				path = b
+			}
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "+++ b/"); ok {
+			path = rest
+			continue
+		}
+		for _, p := range secretPatterns {
+			if p.Re.MatchString(line) {
+				out = append(out, secretFinding{Path: path, Kind: p.Kind})
+			}
+		}
+	}
+	return out
+}
+
+func redactSecrets(text string) string {
+	for _, p := range secretPatterns {
+		text = p.Re.ReplaceAllString(text, "[REDACTED]")
+	}
+	return text
+}
+
+func guardSecrets(ctx context.Context, opts Options, text string) (string, error) {
+	if opts.Secrets == "off" {
+		return text, nil
+	}
+	findings := scanSecrets(text)
+	if len(findings) == 0 {
+		return text, nil
+	}
+	if opts.Secrets == "block" && !opts.AllowSecrets {
+		return "", &secretsError{Findings: findings}
+	}
+	infof(ctx, "secrets: redacted %d possible secrets", len(findings))
+	return redactSecrets(text), nil
+}
@@ -0,0 +1,76 @@
+package aicommit
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/skrashevich/aicommit/pkg/render"
+)
+
+var breakingNotePattern = regexp.MustCompile(`(?i)^BREAKING[ -]CHANGES?:\s*(.*)$`)
+
+func semanticReleaseTypes() []string {
+	types := make([]string, 0, len(conventionalTypes))
+	for _, t := range conventionalTypes {
+		types = append(types, t.(string))
+	}
+	return types
+}
+
+func semanticReleaseType(commitType string) string {
+	commitType = strings.ToLower(commitType)
+	switch {
+	case slices.Contains(semanticReleaseTypes(), commitType):
+		return commitType
+	case commitType == "infra":
+		return "build"
+	}
+	return "chore"
+}
+
+func semanticReleaseMessage(message string, breaking bool, note, lang string) string {
+	p := parseCommitMessage(message)
+	if p.Type == "" {
+		return message
+	}
+	header := semanticReleaseType(p.Type)
+	if p.Scope != "" {
+		header += "(" + p.Scope + ")"
+	}
+	header += ": " + p.Subject
+
+	var rest, notes []string
+	lines := strings.Split(strings.TrimRight(message, "\n"), "\n")[1:]
+	for _, line := range lines {
+		if m := breakingNotePattern.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			if m[1] != "" {
+				notes = append(notes, m[1])
+			}
+			continue
+		}
+		rest = append(rest, line)
+	}
+	if (breaking || p.Breaking) && len(notes) == 0 {
+		notes = append(notes, strings.TrimPrefix(render.BreakingFooter(note, lang), "BREAKING CHANGE: "))
+	}
+
+	out := header
+	if body := strings.Trim(strings.Join(rest, "\n"), "\n"); body != "" {
+		out += "\n\n" + body
+	}
+	for i, n := range notes {
+		if i == 0 {
+			out += "\n"
+		}
+		out += "\nBREAKING CHANGE: " + n
+	}
+	return out
+}
+
+func semanticReleasePromptLines() []string {
+	return []string{
+		"semantic-release: use only these types: " + strings.Join(semanticReleaseTypes(), ", ") + ".",
+		"semantic-release: never put '!' in the header; describe breaking changes only in a last footer paragraph that starts with exactly 'BREAKING CHANGE: '.",
+	}
+}
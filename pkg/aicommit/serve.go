@@ -0,0 +1,231 @@
+package aicommit
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type serveRequest struct {
+	Repo   string `json:"repo"`
+	Diff   string `json:"diff"`
+	Mode   string `json:"mode"`
+	Lang   string `json:"lang"`
+	Format string `json:"format"`
+}
+
+type serveResponse struct {
+	Message  string   `json:"message,omitempty"`
+	Subject  string   `json:"subject,omitempty"`
+	Body     string   `json:"body,omitempty"`
+	Footers  []string `json:"footers,omitempty"`
+	Mode     Mode     `json:"mode,omitempty"`
+	Files    int      `json:"files,omitempty"`
+	Type     string   `json:"type,omitempty"`
+	Scope    string   `json:"scope,omitempty"`
+	Breaking bool     `json:"breaking,omitempty"`
+	Source   string   `json:"source,omitempty"`
+	LLM      bool     `json:"llm,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+type server struct {
+	token string
+	cop   *http.CrossOriginProtection
+	mu    sync.Mutex
+}
+
+func runServe(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	listen := fs.String("listen", "127.0.0.1:8787", "address to listen on")
+	token, _ := getenv("AICOMMIT_SERVE_TOKEN")
+	fs.StringVar(&token, "token", token, "require this bearer token on requests (default: generated at startup)")
+	stdio := fs.Bool("stdio", false, "speak line-delimited JSON-RPC 2.0 on stdin/stdout instead of HTTP")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr(ctx), "Usage: aicommit serve [-listen :8787] [-token secret] | aicommit serve -stdio")
+		fmt.Fprintln(stderr(ctx), `POST /generate {"repo": "/path", "mode": "staged"} or {"diff": "..."}; GET /healthz`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := ensureGit(); err != nil {
+		return err
+	}
+	if timeout := sessionOf(ctx).timeout; timeout > 0 {
+		ctx = context.WithoutCancel(ctx)
+		infof(ctx, "timeout: %s per request", timeout)
+	}
+	if *stdio {
+		return serveStdio(ctx, sessionOf(ctx).stdin, out)
+	}
+	if token == "" {
+		token = rand.Text()
+		fmt.Fprintf(out, "bearer token: %s\n", token)
+	}
+	s := &server{token: token, cop: http.NewCrossOriginProtection()}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/generate", s.handleGenerate)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	srv := &http.Server{
+		Addr:              *listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	fmt.Fprintf(out, "aicommit %s listening on %s\n", version, *listen)
+	return srv.ListenAndServe()
+}
+
+func (s *server) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeServeJSON(ctx, w, http.StatusMethodNotAllowed, serveResponse{Error: "use POST"})
+		return
+	}
+	if err := s.cop.Check(r); err != nil {
+		writeServeJSON(ctx, w, http.StatusForbidden, serveResponse{Error: err.Error()})
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+s.token)) != 1 {
+		writeServeJSON(ctx, w, http.StatusUnauthorized, serveResponse{Error: "invalid or missing bearer token"})
+		return
+	}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		writeServeJSON(ctx, w, http.StatusUnsupportedMediaType, serveResponse{Error: "Content-Type must be application/json"})
+		return
+	}
+	var req serveRequest
+	dec := json.NewDecoder(io.LimitReader(r.Body, 32<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeServeJSON(ctx, w, http.StatusBadRequest, serveResponse{Error: "invalid JSON: " + err.Error()})
+		return
+	}
+	if req.Repo == "" && req.Diff == "" {
+		writeServeJSON(ctx, w, http.StatusBadRequest, serveResponse{Error: "repo or diff is required"})
+		return
+	}
+	start := time.Now()
+	ctx, cancel := requestContext(ctx)
+	defer cancel()
+	gen, status, err := s.generate(ctx, req)
+	infof(ctx, "serve: POST /generate: %d in %s", status, since(start))
+	if err != nil {
+		writeServeJSON(ctx, w, status, serveResponse{Error: err.Error()})
+		return
+	}
+	writeServeJSON(ctx, w, status, generationResponse(gen))
+}
+
+func generationResponse(gen *generation) serveResponse {
+	m := gen.structured()
+	return serveResponse{
+		Message:  m.Text,
+		Subject:  m.Subject,
+		Body:     m.Body,
+		Footers:  m.Footers,
+		Mode:     gen.Mode,
+		Files:    len(gen.Changes),
+		Type:     m.Type,
+		Scope:    m.Scope,
+		Breaking: m.Breaking,
+		Source:   m.Source,
+		LLM:      gen.Explain.LLM,
+	}
+}
+
+func (s *server) generate(ctx context.Context, req serveRequest) (*generation, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ctx, err := inRepo(ctx, req.Repo)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	opts, err := serveOptions(ctx, req)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	st, err := requestState(ctx, opts, req)
+	if err != nil {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+	gen, err := generateFrom(ctx, opts, st)
+	if err != nil {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+	if req.Repo != "" {
+		recordHistory(ctx, opts, gen, false)
+	}
+	return gen, http.StatusOK, nil
+}
+
+func inRepo(ctx context.Context, repo string) (context.Context, error) {
+	if repo == "" {
+		return ctx, nil
+	}
+	dir, err := filepath.Abs(sessionOf(ctx).path(repo))
+	if err != nil {
+		return ctx, fmt.Errorf("repo: %w", err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return ctx, fmt.Errorf("repo: %w", err)
+	}
+	if !info.IsDir() {
+		return ctx, fmt.Errorf("repo: %s is not a directory", repo)
+	}
+	return withSession(ctx, sessionOf(ctx).derive(dir)), nil
+}
+
+func serveOptions(ctx context.Context, req serveRequest) (Options, error) {
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return Options{}, fmt.Errorf("config: %w", err)
+	}
+	var args []string
+	for _, kv := range [][2]string{{"-mode", req.Mode}, {"-lang", req.Lang}, {"-format", req.Format}} {
+		if kv[1] != "" {
+			args = append(args, kv[0], kv[1])
+		}
+	}
+	opts, err := parseFlags(ctx, cfg, args)
+	if err == nil && opts.LLMUser == "-" {
+		err = errors.New("llm.user = - reads stdin and cannot be used by serve")
+	}
+	if err == nil {
+		opts, err = normalizeOptions(ctx, opts)
+	}
+	opts.Commit, opts.Interactive, opts.Edit, opts.Copy, opts.DryRun = false, false, false, false, false
+	return opts, err
+}
+
+func requestState(ctx context.Context, opts Options, req serveRequest) (ChangeSet, error) {
+	if req.Diff != "" {
+		return diffState(ctx, opts, req.Diff)
+	}
+	return collectState(ctx, opts)
+}
+
+func writeServeJSON(ctx context.Context, w http.ResponseWriter, status int, resp serveResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		debugf(ctx, "serve: write response: %v", err)
+	}
+}
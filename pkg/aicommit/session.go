@@ -0,0 +1,95 @@
+package aicommit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type session struct {
+	dir      string
+	offline  bool
+	timeout  time.Duration
+	stdout   io.Writer
+	stderr   io.Writer
+	stdin    io.Reader
+	logLevel atomic.Int32
+	http     *httpState
+}
+
+type httpState struct {
+	mu      sync.Mutex
+	cfg     *config
+	client  *http.Client
+	clients map[string]*http.Client
+}
+
+type sessionKey struct{}
+
+func newSession(stdin io.Reader, stdout, stderr io.Writer) *session {
+	return &session{stdin: stdin, stdout: stdout, stderr: stderr, http: &httpState{}}
+}
+
+func withSession(ctx context.Context, s *session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, s)
+}
+
+func sessionOf(ctx context.Context) *session {
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+		return s
+	}
+	return newSession(eofReader{}, io.Discard, io.Discard)
+}
+
+func (s *session) derive(dir string) *session {
+	d := &session{dir: s.dir, offline: s.offline, timeout: s.timeout, stdout: s.stdout, stderr: s.stderr, stdin: s.stdin, http: s.http}
+	d.logLevel.Store(s.logLevel.Load())
+	if dir != "" {
+		d.dir = dir
+	}
+	return d
+}
+
+func (s *session) path(p string) string {
+	if p == "" || s.dir == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(s.dir, p)
+}
+
+func (s *session) terminal() bool {
+	f, ok := s.stderr.(*os.File)
+	return ok && isTerminal(f)
+}
+
+func (s *session) infof(format string, args ...any) {
+	if s.logLevel.Load() >= levelInfo {
+		fmt.Fprintf(s.stderr, "[info] "+format+"\n", args...)
+	}
+}
+
+func (s *session) debugf(format string, args ...any) {
+	if s.logLevel.Load() >= levelDebug {
+		fmt.Fprintf(s.stderr, "[debug] "+format+"\n", args...)
+	}
+}
+
+func stdout(ctx context.Context) io.Writer {
+	return sessionOf(ctx).stdout
+}
+
+func stderr(ctx context.Context) io.Writer {
+	return sessionOf(ctx).stderr
+}
+
+type eofReader struct{}
+
+func (eofReader) Read([]byte) (int, error) {
+	return 0, io.EOF
+}
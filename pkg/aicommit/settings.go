@@ -0,0 +1,196 @@
+package aicommit
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type setting struct {
+	Key     string
+	Env     string
+	Flag    string
+	Default string
+	Kind    string
+	Choices []string
+	Secret  bool
+	User    bool
+}
+
+const (
+	kindString = ""
+	kindBool   = "bool"
+	kindInt    = "int"
+	kindFloat  = "float"
+)
+
+var settings = []setting{
+	{Key: "mode", Env: "AICOMMIT_MODE", Flag: "mode", Default: string(ModeAuto), Choices: []string{"auto", "staged", "unstaged", "all"}},
+	{Key: "format", Env: "AICOMMIT_FORMAT", Flag: "format", Default: string(FormatConventional), Choices: []string{"conventional", "plain", "gitmoji"}},
+	{Key: "lang", Env: "AICOMMIT_LANG", Flag: "lang", Default: "auto", Choices: []string{"auto", "en", "ru"}},
+	{Key: "type", Env: "AICOMMIT_TYPE", Flag: "type"},
+	{Key: "scope", Env: "AICOMMIT_SCOPE", Flag: "scope"},
+	{Key: "breaking", Flag: "breaking", Default: "false", Kind: kindBool},
+	{Key: "body", Env: "AICOMMIT_BODY", Flag: "body", Default: string(BodyAuto), Choices: []string{"auto", "none", "files", "stats", "summary"}},
+	{Key: "max_items", Env: "AICOMMIT_MAX_ITEMS", Flag: "max-items", Default: "8", Kind: kindInt},
+	{Key: "max_subject", Env: "AICOMMIT_MAX_SUBJECT", Flag: "max-subject", Default: "72", Kind: kindInt},
+	{Key: "max_body_lines", Env: "AICOMMIT_MAX_BODY_LINES", Flag: "max-body-lines", Default: "0", Kind: kindInt},
+	{Key: "max_body_bytes", Env: "AICOMMIT_MAX_BODY_BYTES", Flag: "max-body-bytes", Default: "0", Kind: kindInt},
+	{Key: "max_diff_bytes", Env: "AICOMMIT_MAX_DIFF_BYTES", Flag: "max-diff-bytes", Default: "8388608", Kind: kindInt},
+	{Key: "refs", Env: "AICOMMIT_REFS", Flag: "refs"},
+	{Key: "closes", Env: "AICOMMIT_CLOSES", Flag: "closes"},
+	{Key: "rules_file", Env: "AICOMMIT_RULES", Flag: "rules"},
+	{Key: "emoji", Flag: "emoji", Default: "false", Kind: kindBool},
+	{Key: "explain", Flag: "explain", Default: "false", Kind: kindBool},
+	{Key: "explain_format", Env: "AICOMMIT_EXPLAIN_FORMAT", Flag: "explain-format", Default: "text", Choices: []string{"text", "json"}},
+	{Key: "copy", Flag: "copy", Default: "false", Kind: kindBool},
+	{Key: "quiet", Env: "AICOMMIT_QUIET", Flag: "quiet", Default: "false", Kind: kindBool},
+	{Key: "pretty", Env: "AICOMMIT_PRETTY", Flag: "pretty", Default: "auto", Choices: []string{"auto", "on", "off"}},
+	{Key: "assume_yes", Env: "AICOMMIT_YES", Flag: "yes", Default: "false", Kind: kindBool},
+	{Key: "log_level", Env: "AICOMMIT_LOG_LEVEL", Flag: "log-level", Default: "warn", Choices: []string{"warn", "info", "debug"}},
+	{Key: "include", Env: "AICOMMIT_INCLUDE", Flag: "include"},
+	{Key: "exclude", Env: "AICOMMIT_EXCLUDE", Flag: "exclude"},
+	{Key: "sensitive_paths", Env: "AICOMMIT_SENSITIVE_PATHS", Flag: "sensitive"},
+	{Key: "link_refs", Env: "AICOMMIT_LINK_REFS", Flag: "link-refs", Default: "false", Kind: kindBool},
+	{Key: "forge_hosts", Env: "AICOMMIT_FORGE_HOSTS", User: true},
+	{Key: "branch_refs", Env: "AICOMMIT_BRANCH_REFS", Flag: "branch-refs", Default: "false", Kind: kindBool},
+	{Key: "issue_titles", Env: "AICOMMIT_ISSUE_TITLES", Flag: "issue-titles", Default: "false", Kind: kindBool},
+	{Key: "no_untracked", Env: "AICOMMIT_NO_UNTRACKED", Flag: "no-untracked", Default: "false", Kind: kindBool},
+	{Key: "untracked_max_bytes", Env: "AICOMMIT_UNTRACKED_MAX_BYTES", Flag: "untracked-max-bytes", Default: "16384", Kind: kindInt},
+	{Key: "history", Env: "AICOMMIT_HISTORY", Flag: "history", Default: "true", Kind: kindBool},
+	{Key: "commitlint", Env: "AICOMMIT_COMMITLINT", Flag: "commitlint", Default: "true", Kind: kindBool},
+	{Key: "commitizen", Env: "AICOMMIT_COMMITIZEN", Flag: "commitizen", Default: "true", Kind: kindBool},
+	{Key: "preset", Env: "AICOMMIT_PRESET", Flag: "preset", Choices: presetNames()},
+	{Key: "semantic_release", Env: "AICOMMIT_SEMANTIC_RELEASE", Flag: "semantic-release", Default: "false", Kind: kindBool},
+	{Key: "style_guide.url", Env: "AICOMMIT_STYLE_GUIDE", User: true},
+	{Key: "style_guide.ttl", Env: "AICOMMIT_STYLE_GUIDE_TTL", Default: "24h"},
+	{Key: "style_guide.prompt"},
+	{Key: "strict_split", Env: "AICOMMIT_STRICT_SPLIT", Flag: "strict-split", Default: "false", Kind: kindBool},
+	{Key: "jira.url", Env: "AICOMMIT_JIRA_URL", Flag: "jira-url", User: true},
+	{Key: "jira.user", Env: "AICOMMIT_JIRA_USER", Flag: "jira-user"},
+	{Key: "jira.token", Env: "AICOMMIT_JIRA_TOKEN", Flag: "jira-token", Secret: true},
+	{Key: "azure_boards", Env: "AICOMMIT_AZURE_BOARDS", Flag: "azure-boards", Choices: []string{"", "footer", "subject"}},
+	{Key: "change_id", Env: "AICOMMIT_CHANGE_ID", Flag: "change-id", Default: "false", Kind: kindBool},
+	{Key: "mob", Env: "AICOMMIT_MOB", Flag: "mob", Default: "auto"},
+	{Key: "webhook.url", Env: "AICOMMIT_WEBHOOK_URL", Flag: "webhook", Secret: true, User: true},
+	{Key: "webhook.format", Env: "AICOMMIT_WEBHOOK_FORMAT", Flag: "webhook-format", Default: "json", Choices: []string{"json", "slack"}},
+	{Key: "http.max_idle_conns", Env: "AICOMMIT_HTTP_MAX_IDLE_CONNS", Default: "16", Kind: kindInt},
+	{Key: "http.tls_handshake_timeout", Env: "AICOMMIT_HTTP_TLS_TIMEOUT", Default: "10s"},
+	{Key: "http.idle_conn_timeout", Env: "AICOMMIT_HTTP_IDLE_TIMEOUT", Default: "90s"},
+	{Key: "llm.enabled", Env: "AICOMMIT_LLM", Flag: "llm", Default: "false", Kind: kindBool},
+	{Key: "llm.provider", Env: "AICOMMIT_LLM_PROVIDER", Flag: "provider", Choices: []string{"", "openai", "openrouter"}, User: true},
+	{Key: "llm.model", Env: "AICOMMIT_LLM_MODEL", Flag: "model", Default: "gpt-5-nano"},
+	{Key: "llm.endpoint", Env: "AICOMMIT_LLM_ENDPOINT", Flag: "endpoint", User: true},
+	{Key: "llm.user_agent", Env: "AICOMMIT_LLM_USER_AGENT", Flag: "llm-user-agent"},
+	{Key: "llm.oidc.issuer", Env: "AICOMMIT_LLM_OIDC_ISSUER", User: true},
+	{Key: "llm.oidc.client_id", Env: "AICOMMIT_LLM_OIDC_CLIENT_ID"},
+	{Key: "llm.oidc.client_secret", Env: "AICOMMIT_LLM_OIDC_CLIENT_SECRET", Secret: true},
+	{Key: "llm.oidc.scope", Env: "AICOMMIT_LLM_OIDC_SCOPE"},
+	{Key: "llm.oidc.audience", Env: "AICOMMIT_LLM_OIDC_AUDIENCE"},
+	{Key: "llm.client_cert", Env: "AICOMMIT_LLM_CLIENT_CERT", Flag: "llm-client-cert"},
+	{Key: "llm.client_key", Env: "AICOMMIT_LLM_CLIENT_KEY", Flag: "llm-client-key"},
+	{Key: "llm.allow_insecure_endpoint", Env: "AICOMMIT_LLM_ALLOW_INSECURE_ENDPOINT", Flag: "allow-insecure-endpoint", Default: "false", Kind: kindBool, User: true},
+	{Key: "llm.key", Env: "AICOMMIT_LLM_KEY", Flag: "llm-key", Secret: true},
+	{Key: "llm.temperature", Env: "AICOMMIT_LLM_TEMPERATURE", Flag: "temperature", Default: "1", Kind: kindFloat},
+	{Key: "llm.max_tokens", Env: "AICOMMIT_LLM_MAX_TOKENS", Flag: "max-tokens", Default: "300", Kind: kindInt},
+	{Key: "llm.max_diff", Env: "AICOMMIT_LLM_MAX_DIFF", Flag: "llm-max-diff", Default: "20000", Kind: kindInt},
+	{Key: "llm.gzip", Env: "AICOMMIT_LLM_GZIP", Flag: "llm-gzip", Default: "auto", Choices: []string{"auto", "on", "off"}},
+	{Key: "llm.secrets", Env: "AICOMMIT_LLM_SECRETS", Flag: "secrets", Default: "redact", Choices: []string{"redact", "block", "off"}, User: true},
+	{Key: "allow_secrets", Env: "AICOMMIT_ALLOW_SECRETS", Flag: "allow-secrets", Default: "false", Kind: kindBool, User: true},
+	{Key: "llm.strict", Env: "AICOMMIT_LLM_STRICT", Flag: "llm-strict", Default: "false", Kind: kindBool},
+	{Key: "llm.parallel", Env: "AICOMMIT_LLM_PARALLEL", Flag: "llm-parallel", Default: "4", Kind: kindInt},
+	{Key: "llm.on_cancel", Env: "AICOMMIT_LLM_ON_CANCEL", Flag: "llm-on-cancel", Default: "abort", Choices: []string{"abort", "heuristic"}},
+	{Key: "llm.system", Env: "AICOMMIT_LLM_SYSTEM", Flag: "llm-system"},
+	{Key: "llm.user", Env: "AICOMMIT_LLM_USER", Flag: "llm-user"},
+	{Key: "llm.referer", Env: "AICOMMIT_OPENROUTER_REFERER", Flag: "llm-referer"},
+	{Key: "llm.title", Env: "AICOMMIT_OPENROUTER_TITLE", Flag: "llm-title", Default: "aicommit"},
+}
+
+func findSetting(key string) (setting, bool) {
+	for _, s := range settings {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+func userOnly(key, value string) bool {
+	s, ok := findSetting(key)
+	if !ok || !s.User {
+		return false
+	}
+	switch {
+	case s.Kind == kindBool:
+		v, _ := parseBool(value)
+		return v
+	case key == "llm.secrets":
+		return value == "off"
+	}
+	return true
+}
+
+func validateSetting(s setting, value string) error {
+	switch s.Kind {
+	case kindBool:
+		if _, ok := parseBool(value); !ok {
+			return fmt.Errorf("%s: expected a boolean, got %q", s.Key, value)
+		}
+	case kindInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%s: expected an integer, got %q", s.Key, value)
+		}
+	case kindFloat:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%s: expected a number, got %q", s.Key, value)
+		}
+	}
+	if len(s.Choices) > 0 && !slices.Contains(s.Choices, value) {
+		return fmt.Errorf("%s: expected one of %s, got %q", s.Key, strings.Join(s.Choices, "|"), value)
+	}
+	return nil
+}
+
+type layeredDefaults struct {
+	cfg *config
+	s   *session
+}
+
+func (d layeredDefaults) resolve(key string) resolvedSetting {
+	s, ok := findSetting(key)
+	if !ok {
+		panic("unknown setting: " + key)
+	}
+	r := resolveSetting(s, d.cfg)
+	if r.Source != "default" {
+		d.s.debugf("setting %s = %q (from %s)", key, displayValue(r), r.Source)
+	}
+	if r.Value != s.Default {
+		if err := validateSetting(s, r.Value); err != nil {
+			fmt.Fprintf(d.s.stderr, "warning: %v (from %s), using %q\n", err, r.Source, s.Default)
+			r.Value = s.Default
+			r.Source = "default"
+		}
+	}
+	return r
+}
+
+func (d layeredDefaults) str(key string) string {
+	return d.resolve(key).Value
+}
+
+func (d layeredDefaults) boolean(key string) bool {
+	v, _ := parseBool(d.resolve(key).Value)
+	return v
+}
+
+func (d layeredDefaults) integer(key string) int {
+	v, _ := strconv.Atoi(d.resolve(key).Value)
+	return v
+}
+
+func (d layeredDefaults) float(key string) float64 {
+	v, _ := strconv.ParseFloat(d.resolve(key).Value, 64)
+	return v
+}
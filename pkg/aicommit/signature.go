@@ -0,0 +1,78 @@
+package aicommit
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	goFuncSigRe = regexp.MustCompile(`^func\s+(?:\(\s*(?:[A-Za-z_][A-Za-z0-9_]*\s+)?\*?\s*([A-Za-z_][A-Za-z0-9_]*)(?:\[[^\]]*\])?\s*\)\s*)?([A-Z][A-Za-z0-9_]*)\s*(\(.*)$`)
+	jsFuncSigRe = regexp.MustCompile(`^export\s+(?:default\s+)?(?:async\s+)?function\s*\*?\s*([A-Za-z_$][A-Za-z0-9_$]*)\s*(\(.*)$`)
+)
+
+type signatureChange struct {
+	Name   string
+	Before string
+	After  string
+}
+
+func findSignatureChanges(diff string) []signatureChange {
+	var out []signatureChange
+	for _, fd := range parseDiffFiles(diff) {
+		before := map[string]string{}
+		for _, line := range fd.Removed {
+			if name, sig, ok := parseSignature(line); ok {
+				before[name] = sig
+			}
+		}
+		if len(before) == 0 {
+			continue
+		}
+		for _, line := range fd.Added {
+			name, sig, ok := parseSignature(line)
+			if !ok {
+				continue
+			}
+			old, ok := before[name]
+			if !ok || old == sig {
+				continue
+			}
+			out = append(out, signatureChange{Name: name, Before: old, After: sig})
+			delete(before, name)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+func parseSignature(line string) (string, string, bool) {
+	content := strings.TrimSpace(line)
+	if m := goFuncSigRe.FindStringSubmatch(content); len(m) > 3 {
+		name := m[2]
+		if m[1] != "" {
+			name = m[1] + "." + name
+		}
+		return name, normalizeSignature(m[3]), true
+	}
+	if m := jsFuncSigRe.FindStringSubmatch(content); len(m) > 2 {
+		return m[1], normalizeSignature(m[2]), true
+	}
+	return "", "", false
+}
+
+func normalizeSignature(sig string) string {
+	sig = strings.TrimSpace(sig)
+	sig = strings.TrimSuffix(sig, "{")
+	return strings.Join(strings.Fields(sig), " ")
+}
+
+func signatureNote(changes []signatureChange) string {
+	parts := make([]string, 0, len(changes))
+	for _, sc := range changes {
+		parts = append(parts, sc.Name+" "+sc.Before+" -> "+sc.After)
+	}
+	return "changed signatures: " + strings.Join(parts, "; ")
+}
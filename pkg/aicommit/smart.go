@@ -0,0 +1,65 @@
+package aicommit
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+type SmartCommit struct {
+	Key        string
+	Comment    string
+	Time       string
+	Transition string
+}
+
+func (s SmartCommit) enabled() bool {
+	return s.Comment != "" || s.Time != "" || s.Transition != ""
+}
+
+func (s SmartCommit) line() string {
+	parts := []string{s.Key}
+	if s.Comment != "" {
+		parts = append(parts, "#comment "+oneLine(s.Comment))
+	}
+	if s.Time != "" {
+		parts = append(parts, "#time "+s.Time)
+	}
+	if s.Transition != "" {
+		parts = append(parts, "#"+strings.ReplaceAll(strings.ToLower(strings.TrimPrefix(s.Transition, "#")), " ", "-"))
+	}
+	return strings.Join(parts, " ")
+}
+
+func resolveSmartKey(ctx context.Context, s SmartCommit, refs []string) (SmartCommit, error) {
+	if !s.enabled() {
+		return s, nil
+	}
+	if s.Key != "" {
+		s.Key = strings.ToUpper(strings.TrimSpace(s.Key))
+		return s, nil
+	}
+	for _, ref := range refs {
+		if jiraKeyPattern.MatchString(strings.TrimSpace(ref)) {
+			s.Key = strings.ToUpper(strings.TrimSpace(ref))
+			return s, nil
+		}
+	}
+	if s.Key = jiraKey(currentBranch(ctx)); s.Key == "" {
+		return s, errors.New("smart commit needs a Jira issue key: use -smart-key, -refs PROJ-12 or a branch like feature/PROJ-12-name")
+	}
+	return s, nil
+}
+
+func addSmartCommit(message string, s SmartCommit) string {
+	if !s.enabled() || s.Key == "" {
+		return message
+	}
+	line := s.line()
+	if strings.Contains(message, line) {
+		return message
+	}
+	p := parseCommitMessage(message)
+	p.Footer = append(p.Footer, line)
+	return p.render()
+}
@@ -0,0 +1,108 @@
+package aicommit
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/skrashevich/aicommit/pkg/gitinfo"
+)
+
+type packageProposal struct {
+	Package workspacePackage
+	Gen     *generation
+}
+
+func runSplitByPackage(ctx context.Context, args []string, cfg *config, out io.Writer) error {
+	if err := ensureGit(); err != nil {
+		return err
+	}
+	opts, err := parseFlags(ctx, cfg, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+	if opts, err = normalizeOptions(ctx, opts); err != nil {
+		return err
+	}
+	st, err := collectState(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if opts.Commit && st.Mode == ModeStaged {
+		if err := checkPartiallyStaged(ctx, st.Changes); err != nil {
+			return err
+		}
+	}
+
+	groups := groupByPackage(st.Root, st.Changes)
+	proposals := make([]packageProposal, 0, len(groups))
+	for _, g := range groups {
+		gen, err := generateFrom(ctx, opts, packageState(st, g))
+		if err != nil {
+			return fmt.Errorf("%s: %w", g.Name, err)
+		}
+		proposals = append(proposals, packageProposal{Package: g, Gen: gen})
+	}
+	for i, p := range proposals {
+		dir := p.Package.Dir
+		if dir == "" {
+			dir = "."
+		}
+		fmt.Fprintf(out, "## %d/%d %s (%s, %d files)\n%s\n\n", i+1, len(proposals), p.Package.Name, dir, len(p.Package.Changes), p.Gen.Message)
+	}
+	if !opts.Commit || opts.DryRun {
+		return nil
+	}
+	if !opts.AssumeYes {
+		ok, err := newPrompter(sessionOf(ctx).stdin, stderr(ctx)).confirm(fmt.Sprintf("Create %d commits", len(proposals)), true)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("aborted")
+		}
+	}
+	for _, p := range proposals {
+		if err := commitPackage(ctx, p, st.Mode); err != nil {
+			return fmt.Errorf("%s: %w", p.Package.Name, err)
+		}
+		recordHistory(ctx, opts, p.Gen, true)
+		notifyCommit(ctx, opts, p.Gen)
+		fmt.Fprintf(out, "committed %s: %s\n", p.Package.Name, firstLine(p.Gen.Message))
+	}
+	return nil
+}
+
+func packageState(st ChangeSet, g workspacePackage) ChangeSet {
+	var f pathFilter
+	for _, ch := range g.Changes {
+		f.Include = append(f.Include, ch.Path)
+	}
+	return ChangeSet{Root: st.Root, Mode: st.Mode, Changes: g.Changes, Diff: f.diff(st.Diff), Stats: f.stats(st.Stats)}
+}
+
+func checkPartiallyStaged(ctx context.Context, changes []Change) error {
+	_, unstaged, err := gitinfo.CollectChanges(ctx, gitBytes, false)
+	if err != nil {
+		return err
+	}
+	dirty := map[string]bool{}
+	for _, c := range unstaged {
+		dirty[c.Path] = true
+	}
+	for _, c := range changes {
+		if dirty[c.Path] {
+			return fmt.Errorf("%s is partially staged; stage it fully or use -mode all before committing per package", c.Path)
+		}
+	}
+	return nil
+}
+
+func commitPackage(ctx context.Context, p packageProposal, mode Mode) error {
+	return commitChanges(ctx, p.Gen.Message, mode, p.Package.Changes)
+}
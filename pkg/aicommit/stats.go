@@ -0,0 +1,160 @@
+package aicommit
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"unicode/utf8"
+)
+
+type statCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type commitStats struct {
+	Author        string      `json:"author,omitempty"`
+	Commits       int         `json:"commits"`
+	Conventional  int         `json:"conventional"`
+	Compliance    float64     `json:"compliance"`
+	AvgSubjectLen float64     `json:"avg_subject_length"`
+	LongSubjects  int         `json:"long_subjects"`
+	Scoped        int         `json:"scoped"`
+	Breaking      int         `json:"breaking"`
+	Types         []statCount `json:"types"`
+	Scopes        []statCount `json:"scopes"`
+}
+
+func runStats(ctx context.Context, args []string, cfg *config, out io.Writer) error {
+	d := layeredDefaults{cfg: cfg, s: sessionOf(ctx)}
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	author := fs.String("author", "", "count commits by this author (default: git config user.email)")
+	all := fs.Bool("all", false, "count commits by every author")
+	since := fs.String("since", "", "only commits newer than this date (git --since, e.g. 3.months)")
+	format := fs.String("format", "text", "text|json")
+	top := fs.Int("top", 5, "number of scopes to list")
+	maxSubject := fs.Int("max-subject", d.integer("max_subject"), "subjects longer than this are counted as long")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr(ctx), "Usage: aicommit stats [-author email|-all] [-since 3.months] [-format json] [range]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("invalid -format %q (text|json)", *format)
+	}
+	if err := ensureGit(); err != nil {
+		return err
+	}
+	if !*all && *author == "" {
+		*author, _ = gitOutput(ctx, "config", "user.email")
+	}
+	if *all {
+		*author = ""
+	}
+	revs := []string{"--no-merges"}
+	if *author != "" {
+		revs = append(revs, "--author="+*author)
+	}
+	if *since != "" {
+		revs = append(revs, "--since="+*since)
+	}
+	rng := "HEAD"
+	if fs.NArg() > 0 {
+		rng = fs.Arg(0)
+	}
+	commits, err := logCommits(ctx, append(revs, rng)...)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		return fmt.Errorf("no commits in %s", rng)
+	}
+	s := collectStats(commits, *maxSubject)
+	s.Author = *author
+	if len(s.Scopes) > *top {
+		s.Scopes = s.Scopes[:*top]
+	}
+	if *format == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	}
+	renderStats(out, s, *maxSubject)
+	return nil
+}
+
+func collectStats(commits []loggedCommit, maxSubject int) commitStats {
+	s := commitStats{Commits: len(commits)}
+	types, scopes := map[string]int{}, map[string]int{}
+	subjects := 0
+	for _, c := range commits {
+		n := utf8.RuneCountInString(c.Parsed.Header)
+		subjects += n
+		if maxSubject > 0 && n > maxSubject {
+			s.LongSubjects++
+		}
+		if c.Parsed.Type == "" {
+			types["(none)"]++
+			continue
+		}
+		s.Conventional++
+		types[c.Parsed.Type]++
+		if c.Parsed.Scope != "" {
+			s.Scoped++
+			scopes[c.Parsed.Scope]++
+		}
+		if len(breakingNotes(c)) > 0 {
+			s.Breaking++
+		}
+	}
+	s.Compliance = float64(s.Conventional) / float64(s.Commits)
+	s.AvgSubjectLen = float64(subjects) / float64(s.Commits)
+	s.Types, s.Scopes = sortedCounts(types), sortedCounts(scopes)
+	return s
+}
+
+func sortedCounts(m map[string]int) []statCount {
+	out := make([]statCount, 0, len(m))
+	for name, n := range m {
+		out = append(out, statCount{Name: name, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+func renderStats(out io.Writer, s commitStats, maxSubject int) {
+	who := s.Author
+	if who == "" {
+		who = "all authors"
+	}
+	fmt.Fprintf(out, "%d commits by %s\n", s.Commits, who)
+	fmt.Fprintf(out, "conventional: %d (%.0f%%)\n", s.Conventional, s.Compliance*100)
+	fmt.Fprintf(out, "subject length: %.1f avg", s.AvgSubjectLen)
+	if maxSubject > 0 {
+		fmt.Fprintf(out, ", %d over %d", s.LongSubjects, maxSubject)
+	}
+	fmt.Fprintln(out)
+	if s.Conventional > 0 {
+		fmt.Fprintf(out, "scoped: %d (%.0f%% of conventional), breaking: %d\n", s.Scoped, float64(s.Scoped)*100/float64(s.Conventional), s.Breaking)
+	}
+	fmt.Fprintln(out, "\ntypes:")
+	for _, t := range s.Types {
+		fmt.Fprintf(out, "  %-10s %4d  %3.0f%%\n", t.Name, t.Count, float64(t.Count)*100/float64(s.Commits))
+	}
+	if len(s.Scopes) > 0 {
+		fmt.Fprintln(out, "\nscopes:")
+		for _, sc := range s.Scopes {
+			fmt.Fprintf(out, "  %-10s %4d\n", sc.Name, sc.Count)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package aicommit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+)
+
+const (
+	rpcParseError     = -32700
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcGenerateFailed = -32000
+	rpcCancelled      = -32800
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcSession struct {
+	work    chan struct{}
+	mu      sync.Mutex
+	req     serveRequest
+	state   *ChangeSet
+	last    *generation
+	out     sync.Mutex
+	enc     *json.Encoder
+	pending sync.Mutex
+	cancels map[string]context.CancelFunc
+	wg      sync.WaitGroup
+	log     *session
+}
+
+func serveStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	s := &rpcSession{work: make(chan struct{}, 1), enc: json.NewEncoder(out), cancels: map[string]context.CancelFunc{}, log: sessionOf(ctx)}
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 64<<20)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var req rpcRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			s.reply(nil, nil, &rpcError{Code: rpcParseError, Message: err.Error()})
+			continue
+		}
+		if string(req.ID) == "null" {
+			req.ID = nil
+		}
+		s.dispatch(ctx, req)
+	}
+	s.wg.Wait()
+	return scanner.Err()
+}
+
+func (s *rpcSession) dispatch(ctx context.Context, req rpcRequest) {
+	switch req.Method {
+	case "generate", "regenerate":
+		var params serveRequest
+		if len(req.Params) > 0 {
+			dec := json.NewDecoder(bytes.NewReader(req.Params))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&params); err != nil {
+				s.reply(req.ID, nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()})
+				return
+			}
+		}
+		s.start(ctx, req.ID, func(ctx context.Context) (any, error) {
+			if req.Method == "regenerate" {
+				return s.regenerate(ctx, params)
+			}
+			return s.generate(ctx, params)
+		})
+	case "explain":
+		s.mu.Lock()
+		last := s.last
+		s.mu.Unlock()
+		if last == nil {
+			s.reply(req.ID, nil, &rpcError{Code: rpcInvalidParams, Message: "nothing generated yet"})
+			return
+		}
+		s.reply(req.ID, last.Explain, nil)
+	case "cancel":
+		var params struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil || len(params.ID) == 0 {
+			s.reply(req.ID, nil, &rpcError{Code: rpcInvalidParams, Message: "cancel needs {\"id\": <request id>}"})
+			return
+		}
+		s.pending.Lock()
+		cancel, ok := s.cancels[string(params.ID)]
+		s.pending.Unlock()
+		if ok {
+			cancel()
+		}
+		if req.ID != nil {
+			s.reply(req.ID, map[string]bool{"cancelled": ok}, nil)
+		}
+	default:
+		s.reply(req.ID, nil, &rpcError{Code: rpcMethodNotFound, Message: "unknown method " + req.Method})
+	}
+}
+
+func (s *rpcSession) start(ctx context.Context, id json.RawMessage, fn func(context.Context) (any, error)) {
+	ctx, cancel := requestContext(ctx)
+	if id != nil {
+		s.pending.Lock()
+		s.cancels[string(id)] = cancel
+		s.pending.Unlock()
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		result, err := fn(ctx)
+		if id != nil {
+			s.pending.Lock()
+			delete(s.cancels, string(id))
+			s.pending.Unlock()
+		}
+		cancelled := errors.Is(context.Cause(ctx), context.Canceled)
+		cancel()
+		switch {
+		case cancelled:
+			s.reply(id, nil, &rpcError{Code: rpcCancelled, Message: "request cancelled"})
+		case err != nil:
+			s.reply(id, nil, &rpcError{Code: rpcGenerateFailed, Message: err.Error()})
+		default:
+			s.reply(id, result, nil)
+		}
+	}()
+}
+
+func (s *rpcSession) lock(ctx context.Context) error {
+	select {
+	case s.work <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+func (s *rpcSession) unlock() {
+	<-s.work
+}
+
+func (s *rpcSession) generate(ctx context.Context, req serveRequest) (any, error) {
+	if req.Repo == "" && req.Diff == "" {
+		return nil, errors.New("repo or diff is required")
+	}
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.unlock()
+	ctx, err := inRepo(ctx, req.Repo)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := serveOptions(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	st, err := requestState(ctx, opts, req)
+	if err != nil {
+		return nil, err
+	}
+	gen, err := generateFrom(ctx, opts, st)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.req, s.state, s.last = req, &st, gen
+	s.mu.Unlock()
+	return generationResponse(gen), nil
+}
+
+func (s *rpcSession) regenerate(ctx context.Context, overrides serveRequest) (any, error) {
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.unlock()
+	s.mu.Lock()
+	req, state := s.req, s.state
+	s.mu.Unlock()
+	if state == nil {
+		return nil, errors.New("call generate first")
+	}
+	if overrides.Lang != "" {
+		req.Lang = overrides.Lang
+	}
+	if overrides.Format != "" {
+		req.Format = overrides.Format
+	}
+	ctx, err := inRepo(ctx, req.Repo)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := serveOptions(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	gen, err := generateFrom(ctx, opts, *state)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.last = gen
+	s.mu.Unlock()
+	return generationResponse(gen), nil
+}
+
+func (s *rpcSession) reply(id json.RawMessage, result any, rpcErr *rpcError) {
+	if id == nil && rpcErr == nil {
+		return
+	}
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	s.out.Lock()
+	defer s.out.Unlock()
+	if err := s.enc.Encode(rpcResponse{JSONRPC: "2.0", ID: id, Result: result, Error: rpcErr}); err != nil {
+		s.log.debugf("stdio: write response: %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package aicommit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+func TestStdioNotificationsDoNotShareCancels(t *testing.T) {
+	s := &rpcSession{work: make(chan struct{}, 1), enc: json.NewEncoder(io.Discard), cancels: map[string]context.CancelFunc{}, log: newSession(eofReader{}, io.Discard, io.Discard)}
+	release := make(chan struct{})
+	for range 2 {
+		s.start(context.Background(), nil, func(ctx context.Context) (any, error) {
+			<-release
+			return nil, ctx.Err()
+		})
+	}
+	s.pending.Lock()
+	n := len(s.cancels)
+	s.pending.Unlock()
+	close(release)
+	s.wg.Wait()
+	if n != 0 {
+		t.Errorf("notifications registered %d cancel funcs", n)
+	}
+}
@@ -0,0 +1,122 @@
+package aicommit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func isRemoteSource(src string) bool {
+	return strings.HasPrefix(src, "https://") || strings.HasPrefix(src, "http://")
+}
+
+func styleGuideSource(ctx context.Context, layers []configLayer) (string, time.Duration) {
+	src, ttl := "", "24h"
+	for _, layer := range layers {
+		if v, ok := layer.Values["style_guide.url"]; ok {
+			src = v
+		}
+		if v, ok := layer.Values["style_guide.ttl"]; ok {
+			ttl = v
+		}
+	}
+	if v, _ := getenv("AICOMMIT_STYLE_GUIDE"); v != "" {
+		src = v
+	}
+	if v, _ := getenv("AICOMMIT_STYLE_GUIDE_TTL"); v != "" {
+		ttl = v
+	}
+	d, err := time.ParseDuration(ttl)
+	if err != nil {
+		fmt.Fprintf(stderr(ctx), "warning: style_guide.ttl %q is not a duration, using 24h\n", ttl)
+		d = 24 * time.Hour
+	}
+	return strings.TrimSpace(src), d
+}
+
+func loadStyleGuide(ctx context.Context, src string, ttl time.Duration) (configLayer, error) {
+	data, err := readStyleGuide(ctx, src, ttl)
+	if err != nil {
+		return configLayer{}, err
+	}
+	layer, err := parseConfigLayer(src, string(data))
+	if err != nil {
+		return configLayer{}, err
+	}
+	for key, value := range layer.Values {
+		switch {
+		case key == "prompt":
+			delete(layer.Values, key)
+			layer.Values["style_guide.prompt"] = value
+		case strings.HasPrefix(key, "llm.") || strings.HasPrefix(key, "jira.") || strings.HasPrefix(key, "style_guide.") || key == "rules_file":
+			fmt.Fprintf(stderr(ctx), "warning: style guide %s: %s cannot be set by a shared style guide, ignored\n", src, key)
+			delete(layer.Values, key)
+		}
+	}
+	return layer, nil
+}
+
+func readStyleGuide(ctx context.Context, src string, ttl time.Duration) ([]byte, error) {
+	if !isRemoteSource(src) {
+		return os.ReadFile(sessionOf(ctx).path(src))
+	}
+	sum := sha256.Sum256([]byte(src))
+	cache := filepath.Join(userStateDir(), "style-guides", hex.EncodeToString(sum[:8])+".toml")
+	info, statErr := os.Stat(cache)
+	if statErr == nil && (sessionOf(ctx).offline || time.Since(info.ModTime()) < ttl) {
+		debugf(ctx, "style guide: %s from cache %s", src, cache)
+		return os.ReadFile(cache)
+	}
+	if sessionOf(ctx).offline {
+		return nil, errors.New("offline: no cached copy")
+	}
+	data, err := fetchStyleGuide(ctx, src)
+	if err != nil {
+		if statErr != nil {
+			return nil, err
+		}
+		fmt.Fprintf(stderr(ctx), "warning: style guide %s: %v; using the cached copy from %s\n", src, err, info.ModTime().Format(time.DateTime))
+		return os.ReadFile(cache)
+	}
+	if err := os.MkdirAll(filepath.Dir(cache), 0o755); err == nil {
+		if err := os.WriteFile(cache, data, 0o644); err != nil {
+			debugf(ctx, "style guide: cannot cache %s: %v", src, err)
+		}
+	}
+	return data, nil
+}
+
+func fetchStyleGuide(ctx context.Context, url string) ([]byte, error) {
+	if !strings.HasPrefix(url, "https://") {
+		return nil, errors.New("only https:// URLs are allowed")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "aicommit/"+version)
+	start := time.Now()
+	resp, err := httpClient(ctx).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.Request.URL.Scheme != "https" {
+		return nil, fmt.Errorf("redirected to non-https URL %s", resp.Request.URL.Redacted())
+	}
+	infof(ctx, "style guide: http %d in %s", resp.StatusCode, since(start))
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("http %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
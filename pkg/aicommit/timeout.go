@@ -0,0 +1,69 @@
+package aicommit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var errTimedOut = errors.New("timed out")
+
+func cutTimeoutFlag(args []string) ([]string, time.Duration, error) {
+	var timeout time.Duration
+	if raw, name := getenv("AICOMMIT_TIMEOUT"); raw != "" {
+		v, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%s: invalid duration %q", name, raw)
+		}
+		timeout = v
+	}
+	end := globalFlagsEnd(args)
+	out := make([]string, 0, len(args))
+	for i := 0; i < end; i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "timeout" {
+			out = append(out, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 >= end {
+				return nil, 0, fmt.Errorf("flag needs an argument: -timeout")
+			}
+			i++
+			value = args[i]
+		}
+		v, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid duration %q for -timeout", value)
+		}
+		timeout = v
+	}
+	return append(out, args[end:]...), timeout, nil
+}
+
+func startTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	sessionOf(parent).timeout = timeout
+	infof(parent, "timeout: %s", timeout)
+	return context.WithTimeoutCause(parent, timeout, errTimedOut)
+}
+
+func requestContext(parent context.Context) (context.Context, context.CancelFunc) {
+	timeout := sessionOf(parent).timeout
+	if timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeoutCause(parent, timeout, errTimedOut)
+}
+
+func timeoutError(ctx context.Context, err error) error {
+	if err == nil || !errors.Is(context.Cause(ctx), errTimedOut) {
+		return err
+	}
+	return &exitError{code: 124, err: fmt.Errorf("%v (after %s)", err, sessionOf(ctx).timeout)}
+}
@@ -0,0 +1,105 @@
+package aicommit
+
+import (
+	"regexp"
+	"strings"
+)
+
+var todoRe = regexp.MustCompile(`(?:^|//|#|/\*|--|;|\*)\s*(TODO|FIXME|HACK|XXX|BUG)\b(?:\([^)]*\))?:?\s*(.*)$`)
+
+const maxTodoText = 60
+
+type todoNote struct {
+	Kind string
+	Text string
+	Path string
+}
+
+func findResolvedTodos(diff string) []todoNote {
+	var out []todoNote
+	for _, fd := range parseDiffFiles(diff) {
+		kept := map[string]struct{}{}
+		for _, line := range fd.Added {
+			if kind, text, ok := parseTodo(line); ok {
+				kept[kind+":"+text] = struct{}{}
+			}
+		}
+		var resolved []todoNote
+		for _, line := range fd.Removed {
+			kind, text, ok := parseTodo(line)
+			if !ok {
+				continue
+			}
+			if _, ok := kept[kind+":"+text]; ok {
+				continue
+			}
+			resolved = append(resolved, todoNote{Kind: kind, Text: text, Path: fd.Path})
+		}
+		if len(resolved) == 0 || !hasCodeLines(fd) {
+			continue
+		}
+		out = append(out, resolved...)
+	}
+	return out
+}
+
+func parseTodo(line string) (string, string, bool) {
+	m := todoRe.FindStringSubmatch(strings.TrimSpace(line))
+	if len(m) < 3 {
+		return "", "", false
+	}
+	text := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(m[2]), "*/"))
+	if runes := []rune(text); len(runes) > maxTodoText {
+		text = strings.TrimSpace(string(runes[:maxTodoText])) + "…"
+	}
+	return m[1], text, true
+}
+
+func hasCodeLines(fd fileDiff) bool {
+	for _, lines := range [][]string{fd.Added, fd.Removed} {
+		for _, line := range lines {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" {
+				continue
+			}
+			if _, _, ok := parseTodo(trimmed); ok {
+				continue
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func todoType(todos []todoNote) string {
+	for _, t := range todos {
+		if t.Kind == "FIXME" || t.Kind == "BUG" || t.Kind == "XXX" {
+			return "fix"
+		}
+	}
+	for _, t := range todos {
+		if t.Kind == "HACK" {
+			return "refactor"
+		}
+	}
+	return "fix"
+}
+
+func buildTodoLines(todos []todoNote, maxItems int, lang string) []string {
+	var lines []string
+	for i, t := range todos {
+		if maxItems > 0 && i >= maxItems {
+			break
+		}
+		text := t.Text
+		if text == "" {
+			text = t.Path
+		}
+		if lang == "ru" {
+			lines = append(lines, "- закрыт "+t.Kind+": "+text)
+		} else {
+			lines = append(lines, "- resolve "+t.Kind+" about "+text)
+		}
+	}
+	return lines
+}
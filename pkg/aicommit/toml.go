@@ -0,0 +1,365 @@
+package aicommit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tomlEntry struct {
+	Table []string
+	Index int
+	Key   []string
+	Value any
+	Line  int
+}
+
+func parseTOML(data string) ([]tomlEntry, error) {
+	var entries []tomlEntry
+	var table []string
+	index := -1
+	arrayCounts := map[string]int{}
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(stripTOMLComment(lines[i]))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[[") {
+			if !strings.HasSuffix(line, "]]") {
+				return nil, fmt.Errorf("line %d: unterminated array table", lineNo)
+			}
+			keys, err := parseTOMLKey(strings.TrimSpace(line[2 : len(line)-2]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			name := strings.Join(keys, ".")
+			table = keys
+			index = arrayCounts[name]
+			arrayCounts[name]++
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: unterminated table header", lineNo)
+			}
+			keys, err := parseTOMLKey(strings.TrimSpace(line[1 : len(line)-1]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			table = keys
+			index = -1
+			continue
+		}
+		rawKey, rawValue, ok := cutTOMLAssignment(line)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		keys, err := parseTOMLKey(rawKey)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		for strings.HasPrefix(rawValue, "[") && !tomlArrayClosed(rawValue) && i+1 < len(lines) {
+			i++
+			rawValue += " " + strings.TrimSpace(stripTOMLComment(lines[i]))
+		}
+		if strings.HasPrefix(rawValue, `"""`) && !(len(rawValue) >= 6 && strings.HasSuffix(rawValue, `"""`)) {
+			var b strings.Builder
+			b.WriteString(strings.TrimPrefix(rawValue, `"""`))
+			closed := false
+			for i+1 < len(lines) {
+				i++
+				if idx := strings.Index(lines[i], `"""`); idx != -1 {
+					b.WriteString("\n" + lines[i][:idx])
+					closed = true
+					break
+				}
+				b.WriteString("\n" + lines[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("line %d: unterminated multi-line string", lineNo)
+			}
+			entries = append(entries, tomlEntry{Table: table, Index: index, Key: keys, Value: strings.TrimPrefix(b.String(), "\n"), Line: lineNo})
+			continue
+		}
+		value, err := parseTOMLValue(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		entries = append(entries, tomlEntry{Table: table, Index: index, Key: keys, Value: value, Line: lineNo})
+	}
+	return entries, nil
+}
+
+func stripTOMLComment(line string) string {
+	inString := byte(0)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case inString != 0:
+			if c == '\\' && inString == '"' {
+				i++
+				continue
+			}
+			if c == inString {
+				inString = 0
+			}
+		case c == '"' || c == '\'':
+			inString = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func cutTOMLAssignment(line string) (string, string, bool) {
+	inString := byte(0)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case inString != 0:
+			if c == inString {
+				inString = 0
+			}
+		case c == '"' || c == '\'':
+			inString = c
+		case c == '=':
+			return strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+func parseTOMLKey(raw string) ([]string, error) {
+	var keys []string
+	rest := strings.TrimSpace(raw)
+	for rest != "" {
+		var key string
+		switch rest[0] {
+		case '"', '\'':
+			end := strings.IndexByte(rest[1:], rest[0])
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated quoted key")
+			}
+			key = rest[1 : end+1]
+			rest = strings.TrimSpace(rest[end+2:])
+		default:
+			end := strings.IndexByte(rest, '.')
+			if end == -1 {
+				end = len(rest)
+			}
+			key = strings.TrimSpace(rest[:end])
+			rest = strings.TrimSpace(rest[end:])
+			if key == "" {
+				return nil, fmt.Errorf("empty key")
+			}
+		}
+		keys = append(keys, key)
+		if rest == "" {
+			break
+		}
+		if rest[0] != '.' {
+			return nil, fmt.Errorf("invalid key %q", raw)
+		}
+		rest = strings.TrimSpace(rest[1:])
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("empty key")
+	}
+	return keys, nil
+}
+
+func tomlArrayClosed(raw string) bool {
+	depth := 0
+	inString := byte(0)
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case inString != 0:
+			if c == '\\' && inString == '"' {
+				i++
+				continue
+			}
+			if c == inString {
+				inString = 0
+			}
+		case c == '"' || c == '\'':
+			inString = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+func parseTOMLValue(raw string) (any, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("missing value")
+	}
+	switch {
+	case raw[0] == '"':
+		if len(raw) < 2 || raw[len(raw)-1] != '"' {
+			return nil, fmt.Errorf("unterminated string")
+		}
+		value, err := strconv.Unquote(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s", raw)
+		}
+		return value, nil
+	case raw[0] == '\'':
+		if len(raw) < 2 || raw[len(raw)-1] != '\'' {
+			return nil, fmt.Errorf("unterminated string")
+		}
+		return raw[1 : len(raw)-1], nil
+	case raw[0] == '[':
+		return parseTOMLArray(raw)
+	case raw[0] == '{':
+		return parseTOMLInlineTable(raw)
+	case raw == "true":
+		return true, nil
+	case raw == "false":
+		return false, nil
+	}
+	clean := strings.ReplaceAll(raw, "_", "")
+	if n, err := strconv.ParseInt(clean, 10, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(clean, 64); err == nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("unsupported value %s", raw)
+}
+
+func parseTOMLArray(raw string) ([]any, error) {
+	if !strings.HasSuffix(raw, "]") {
+		return nil, fmt.Errorf("unterminated array")
+	}
+	var out []any
+	for _, item := range splitTOMLItems(raw[1 : len(raw)-1]) {
+		value, err := parseTOMLValue(item)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, value)
+	}
+	return out, nil
+}
+
+func parseTOMLInlineTable(raw string) (map[string]any, error) {
+	if !strings.HasSuffix(raw, "}") {
+		return nil, fmt.Errorf("unterminated inline table")
+	}
+	out := map[string]any{}
+	for _, item := range splitTOMLItems(raw[1 : len(raw)-1]) {
+		rawKey, rawValue, ok := cutTOMLAssignment(item)
+		if !ok {
+			return nil, fmt.Errorf("expected key = value in inline table")
+		}
+		keys, err := parseTOMLKey(rawKey)
+		if err != nil {
+			return nil, err
+		}
+		value, err := parseTOMLValue(rawValue)
+		if err != nil {
+			return nil, err
+		}
+		out[strings.Join(keys, ".")] = value
+	}
+	return out, nil
+}
+
+func splitTOMLItems(inner string) []string {
+	inner = strings.TrimSpace(inner)
+	var out []string
+	start := 0
+	inString := byte(0)
+	depth := 0
+	for i := 0; i <= len(inner); i++ {
+		if i < len(inner) {
+			c := inner[i]
+			switch {
+			case inString != 0:
+				if c == '\\' && inString == '"' {
+					i++
+				} else if c == inString {
+					inString = 0
+				}
+				continue
+			case c == '"' || c == '\'':
+				inString = c
+				continue
+			case c == '[' || c == '{':
+				depth++
+				continue
+			case c == ']' || c == '}':
+				depth--
+				continue
+			case c != ',' || depth > 0:
+				continue
+			}
+		}
+		if item := strings.TrimSpace(inner[start:i]); item != "" {
+			out = append(out, item)
+		}
+		start = i + 1
+	}
+	return out
+}
+
+func tomlTree(entries []tomlEntry) map[string]any {
+	root := map[string]any{}
+	descend := func(m map[string]any, key string) map[string]any {
+		child, ok := m[key].(map[string]any)
+		if !ok {
+			child = map[string]any{}
+			m[key] = child
+		}
+		return child
+	}
+	for _, e := range entries {
+		node := root
+		for i, name := range e.Table {
+			if i == len(e.Table)-1 && e.Index >= 0 {
+				list, _ := node[name].([]any)
+				for len(list) <= e.Index {
+					list = append(list, map[string]any{})
+				}
+				node[name] = list
+				node = list[e.Index].(map[string]any)
+				continue
+			}
+			node = descend(node, name)
+		}
+		for _, name := range e.Key[:len(e.Key)-1] {
+			node = descend(node, name)
+		}
+		node[e.Key[len(e.Key)-1]] = e.Value
+	}
+	return root
+}
+
+func tomlString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, tomlString(item))
+		}
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,37 @@
+package aicommit
+
+import "strings"
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiDim    = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+)
+
+func colorizeMessage(message string) string {
+	p := parseCommitMessage(message)
+	lines := strings.Split(strings.TrimRight(message, "\n"), "\n")
+	header := ansiBold + lines[0] + ansiReset
+	if p.Type != "" {
+		header = p.Prefix + ansiBold + ansiCyan + p.Type + ansiReset
+		if p.Scope != "" {
+			header += "(" + ansiYellow + p.Scope + ansiReset + ")"
+		}
+		if p.Breaking {
+			header += ansiBold + ansiRed + "!" + ansiReset
+		}
+		header += ": " + ansiBold + p.Subject + ansiReset
+	}
+	lines[0] = header
+	for i := len(lines) - len(p.Footer); i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "BREAKING") {
+			lines[i] = ansiRed + lines[i] + ansiReset
+		} else {
+			lines[i] = ansiDim + lines[i] + ansiReset
+		}
+	}
+	return strings.Join(lines, "\n")
+}
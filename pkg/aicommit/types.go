@@ -0,0 +1,141 @@
+package aicommit
+
+import (
+	"io"
+
+	"github.com/skrashevich/aicommit/pkg/gitinfo"
+
+	"github.com/skrashevich/aicommit/pkg/render"
+)
+
+type Mode = gitinfo.Mode
+
+type Format = render.Format
+
+type BodyMode = render.BodyMode
+
+const (
+	ModeAuto     = gitinfo.ModeAuto
+	ModeStaged   = gitinfo.ModeStaged
+	ModeUnstaged = gitinfo.ModeUnstaged
+	ModeAll      = gitinfo.ModeAll
+	ModeDiff     = gitinfo.ModeDiff
+)
+
+const (
+	FormatConventional = render.Conventional
+	FormatPlain        = render.Plain
+	FormatGitmoji      = render.Gitmoji
+)
+
+const (
+	BodyAuto    = render.BodyAuto
+	BodyNone    = render.BodyNone
+	BodyFiles   = render.BodyFiles
+	BodyStats   = render.BodyStats
+	BodySummary = render.BodySummary
+)
+
+type Options struct {
+	Mode              Mode
+	Format            Format
+	Lang              string
+	Type              string
+	Scope             string
+	Breaking          bool
+	Body              BodyMode
+	MaxItems          int
+	MaxSubject        int
+	MaxBodyLines      int
+	MaxBodyBytes      int
+	MaxDiffBytes      int
+	Emoji             bool
+	Explain           bool
+	ExplainFormat     string
+	Copy              bool
+	StrictSplit       bool
+	Commit            bool
+	Interactive       bool
+	Edit              bool
+	AssumeYes         bool
+	DryRun            bool
+	Output            string
+	Print0            bool
+	JSON              bool
+	Version           bool
+	Pretty            bool
+	Quiet             bool
+	SemanticRelease   bool
+	Preset            string
+	History           bool
+	NoUntracked       bool
+	UntrackedMaxBytes int
+	Include           []string
+	Exclude           []string
+	Sensitive         []string
+	BranchRefs        bool
+	LinkRefs          bool
+	ForgeHosts        []PathMapping
+	IssueTitles       bool
+	Issues            map[string]string
+	JiraURL           string
+	JiraUser          string
+	JiraToken         string
+	AzureBoards       string
+	Smart             SmartCommit
+	SubjectRefs       []string
+	ChangeID          bool
+	Mob               string
+	CoAuthors         []string
+	WebhookURL        string
+	WebhookFormat     string
+	UseCommitlint     bool
+	UseCommitizen     bool
+	Refs              []string
+	Closes            []string
+	ScopeMap          []PathMapping
+	TypeMap           []PathMapping
+	RulesFile         string
+	Rules             []Rule
+	LLMEnabled        bool
+	LLMProvider       string
+	LLMModel          string
+	LLMEndpoint       string
+	AllowInsecure     bool
+	LLMClientCert     string
+	LLMOIDC           OIDCConfig
+	LLMUserAgent      string
+	LLMRequestID      string
+	LLMClientKey      string
+	LLMKey            string
+	LLMTemperature    float64
+	LLMMaxTokens      int
+	LLMMaxDiff        int
+	LLMStrict         bool
+	LLMOnCancel       string
+	LLMParallel       int
+	LLMGzip           string
+	Secrets           string
+	AllowSecrets      bool
+	LLMSystem         string
+	LLMUser           string
+	StylePrompt       string
+	LLMReferer        string
+	LLMTitle          string
+	Dir               string
+	Stderr            io.Writer
+	preset            *commitPreset
+	commitlint        *commitlintConfig
+	commitizen        *commitizenConfig
+}
+
+type Change = gitinfo.Change
+
+type FileStat = gitinfo.FileStat
+
+type PathMapping struct {
+	Pattern string
+	Value   string
+}
+
+type ChangeSet = gitinfo.ChangeSet
@@ -0,0 +1,87 @@
+package aicommit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func undoStatePath(ctx context.Context) (string, error) {
+	path, err := gitOutput(ctx, "rev-parse", "--git-path", "aicommit/last-commit")
+	if err != nil {
+		return "", errors.New("not a git repository")
+	}
+	return filepath.Abs(path)
+}
+
+func rememberCommit(ctx context.Context) {
+	sha, err := gitOutput(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		return
+	}
+	path, err := undoStatePath(ctx)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0o755)
+	}
+	if err == nil {
+		err = os.WriteFile(path, []byte(sha+"\n"), 0o644)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr(ctx), "warning: commit not recorded for undo:", err)
+	}
+}
+
+func runUndo(ctx context.Context, out io.Writer) error {
+	if err := ensureGit(); err != nil {
+		return err
+	}
+	path, err := undoStatePath(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return errors.New("nothing to undo: no commit was created by aicommit in this repository")
+	}
+	if err != nil {
+		return err
+	}
+	sha := strings.TrimSpace(string(data))
+	head, err := gitOutput(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		return errors.New("repository has no commits")
+	}
+	if head != sha {
+		return fmt.Errorf("HEAD moved since aicommit created %s; refusing to undo", shortSHA(sha))
+	}
+	remotes, err := remoteRefsContaining(ctx, sha)
+	if err != nil {
+		return err
+	}
+	if remotes != "" {
+		return fmt.Errorf("%s was already pushed (%s); refusing to undo", shortSHA(sha), remotes)
+	}
+	subject, _ := gitOutput(ctx, "log", "-1", "--format=%s", sha)
+
+	args := []string{"reset", "--soft", "HEAD~1"}
+	if _, err := gitOutput(ctx, "rev-parse", "--verify", "-q", sha+"^"); err != nil {
+		args = []string{"update-ref", "-d", "HEAD"}
+	}
+	infof(ctx, "git %s", strings.Join(args, " "))
+	if output, err := gitCommand(ctx, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(output)))
+	}
+	if err := os.Remove(path); err != nil {
+		fmt.Fprintln(stderr(ctx), "warning:", err)
+	}
+	fmt.Fprintf(out, "undid %s %s (changes kept staged)\n", shortSHA(sha), subject)
+	return nil
+}
+
+func shortSHA(sha string) string {
+	return sha[:min(len(sha), 12)]
+}
@@ -0,0 +1,69 @@
+package aicommit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/skrashevich/aicommit/pkg/detect"
+)
+
+func untrackedDiff(ctx context.Context, root string, untracked []Change, maxFile, budget int) string {
+	files := append([]Change(nil), untracked...)
+	sort.SliceStable(files, func(i, j int) bool {
+		return diffPriority[detect.CategorizePath(files[i].Path)] < diffPriority[detect.CategorizePath(files[j].Path)]
+	})
+	var b strings.Builder
+	for i, ch := range files {
+		if budget > 0 && b.Len() >= budget {
+			infof(ctx, "untracked: diff budget reached, %d files shown by name only", len(files)-i)
+			break
+		}
+		section := untrackedSection(root, ch.Path, maxFile)
+		if section == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(section)
+	}
+	return b.String()
+}
+
+func untrackedSection(root, path string, maxFile int) string {
+	full := filepath.Join(root, filepath.FromSlash(path))
+	info, err := os.Lstat(full)
+	if err != nil || !info.Mode().IsRegular() {
+		return ""
+	}
+	header := fmt.Sprintf("diff --git a/%s b/%s\nnew file mode %o\n", path, path, 0o100000|info.Mode().Perm())
+	f, err := os.Open(full)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, int64(maxFile)+1))
+	if err != nil {
+		return ""
+	}
+	if bytes.IndexByte(data[:min(len(data), 8000)], 0) != -1 {
+		return header + fmt.Sprintf("Binary files /dev/null and b/%s differ", path)
+	}
+	if len(data) > maxFile {
+		size := strings.TrimPrefix(formatSizeDelta(info.Size()), "+")
+		return header + fmt.Sprintf("--- /dev/null\n+++ b/%s\n@@ new file of %s (%s), content omitted @@", path, size, http.DetectContentType(data))
+	}
+	text := strings.TrimSuffix(string(data), "\n")
+	if text == "" {
+		return strings.TrimSuffix(header, "\n")
+	}
+	lines := strings.Split(text, "\n")
+	return header + fmt.Sprintf("--- /dev/null\n+++ b/%s\n@@ -0,0 +1,%d @@\n+%s", path, len(lines), strings.Join(lines, "\n+"))
+}
@@ -0,0 +1,205 @@
+package aicommit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+type verifyFinding struct {
+	Commit  string `json:"commit"`
+	Subject string `json:"subject"`
+	Level   string `json:"level"`
+	Rule    string `json:"rule"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+type verifyReport struct {
+	Range    string          `json:"range"`
+	Commits  int             `json:"commits"`
+	Errors   int             `json:"errors"`
+	Warnings int             `json:"warnings"`
+	Findings []verifyFinding `json:"findings"`
+}
+
+func runVerify(ctx context.Context, args []string, cfg *config, out io.Writer) error {
+	d := layeredDefaults{cfg: cfg, s: sessionOf(ctx)}
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	format := fs.String("format", "text", "text|json|sarif")
+	output := fs.String("o", "", "write the report to this file instead of stdout")
+	infer := fs.Bool("infer", false, "warn when the commit type differs from what aicommit infers from the diff")
+	strict := fs.Bool("strict", false, "fail on warnings too")
+	msgFormat := fs.String("message-format", d.str("format"), "plain|conventional|gitmoji")
+	maxSubject := fs.Int("max-subject", d.integer("max_subject"), "max subject length")
+	presetName := fs.String("preset", d.str("preset"), "angular|conventionalcommits|atom|ember")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr(ctx), "Usage: aicommit verify [-format json|sarif] [-o report] [-infer] [-strict] <range>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "text" && *format != "json" && *format != "sarif" {
+		return fmt.Errorf("invalid -format %q (text|json|sarif)", *format)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("verify needs a revision range, e.g. origin/main..HEAD")
+	}
+	if err := ensureGit(); err != nil {
+		return err
+	}
+	preset, err := lookupPreset(*presetName)
+	if err != nil {
+		return err
+	}
+	rules, err := lintRules(ctx, Format(*msgFormat), *maxSubject, preset, d)
+	if err != nil {
+		return err
+	}
+	commits, err := logCommits(ctx, append([]string{"--no-merges"}, fs.Args()...)...)
+	if err != nil {
+		return err
+	}
+
+	var inferOpts Options
+	if *infer {
+		if inferOpts, err = parseFlags(ctx, cfg, nil); err == nil {
+			inferOpts, err = normalizeOptions(ctx, inferOpts)
+		}
+		if err != nil {
+			return err
+		}
+		inferOpts.LLMEnabled = false
+	}
+	report := verifyReport{Range: strings.Join(fs.Args(), " "), Commits: len(commits), Findings: []verifyFinding{}}
+	for _, c := range commits {
+		violations := rules.check(c.Message)
+		if *infer {
+			violations = append(violations, inferViolations(ctx, inferOpts, c)...)
+		}
+		for _, v := range violations {
+			report.Findings = append(report.Findings, verifyFinding{
+				Commit: c.SHA, Subject: c.Parsed.Header, Level: v.severity(),
+				Rule: v.Rule, Line: v.Line, Column: v.Column, Message: v.Message,
+			})
+			if v.Level >= 2 {
+				report.Errors++
+			} else {
+				report.Warnings++
+			}
+		}
+	}
+
+	w := out
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	case "sarif":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(sarifLog(report))
+	default:
+		for _, f := range report.Findings {
+			fmt.Fprintf(w, "%s:%d:%d: %s %s: %s\n", shortSHA(f.Commit), f.Line, f.Column, f.Level, f.Rule, f.Message)
+		}
+		fmt.Fprintf(w, "%d commits, %d errors, %d warnings\n", report.Commits, report.Errors, report.Warnings)
+	}
+	if err != nil {
+		return err
+	}
+	if report.Errors > 0 || (*strict && report.Warnings > 0) {
+		return fmt.Errorf("%d errors and %d warnings in %d commits", report.Errors, report.Warnings, report.Commits)
+	}
+	return nil
+}
+
+func inferViolations(ctx context.Context, opts Options, c loggedCommit) []lintViolation {
+	if c.Parsed.Type == "" {
+		return nil
+	}
+	diff, err := gitOutput(ctx, "show", "--format=", "-U0", "-M", c.SHA)
+	if err != nil {
+		return nil
+	}
+	st, err := diffState(ctx, opts, diff)
+	if err != nil {
+		return nil
+	}
+	gen, err := generateFrom(ctx, opts, st)
+	if err != nil {
+		return nil
+	}
+	var out []lintViolation
+	inferred := gen.Explain
+	if inferred.Type != "" && inferred.TypeConfidence >= 0.6 && releaseImpact(inferred.Type) != releaseImpact(c.Parsed.Type) {
+		out = append(out, lintViolation{Level: 1, Rule: "type-mismatch", Line: 1, Column: len([]rune(c.Parsed.Prefix)) + 1,
+			Message: fmt.Sprintf("type is %s but the diff looks like %s (confidence %.2f)", c.Parsed.Type, inferred.Type, inferred.TypeConfidence)})
+	}
+	if inferred.Breaking && inferred.BreakingConfidence >= 0.6 && len(breakingNotes(c)) == 0 {
+		out = append(out, lintViolation{Level: 1, Rule: "breaking-missing", Line: 1, Column: 1,
+			Message: "diff looks breaking (" + inferred.BreakingNote + ") but the message has no ! or BREAKING CHANGE footer"})
+	}
+	return out
+}
+
+func releaseImpact(commitType string) string {
+	switch commitType {
+	case "feat":
+		return "minor"
+	case "fix", "perf":
+		return "patch"
+	}
+	return "none"
+}
+
+func sarifLog(report verifyReport) map[string]any {
+	rules := []map[string]any{}
+	results := []map[string]any{}
+	seen := map[string]bool{}
+	for _, f := range report.Findings {
+		if !seen[f.Rule] {
+			seen[f.Rule] = true
+			rules = append(rules, map[string]any{"id": f.Rule})
+		}
+		results = append(results, map[string]any{
+			"ruleId":  f.Rule,
+			"level":   f.Level,
+			"message": map[string]string{"text": fmt.Sprintf("%s: %s (%s)", shortSHA(f.Commit), f.Message, f.Subject)},
+			"locations": []map[string]any{{
+				"logicalLocations": []map[string]string{{"name": f.Commit, "kind": "commit"}},
+			}},
+			"partialFingerprints": map[string]string{"commitRule": f.Commit + "/" + f.Rule},
+		})
+	}
+	return map[string]any{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []map[string]any{{
+			"tool": map[string]any{"driver": map[string]any{
+				"name":           "aicommit",
+				"version":        version,
+				"informationUri": "https://github.com/skrashevich/aicommit",
+				"rules":          rules,
+			}},
+			"results": results,
+		}},
+	}
+}
@@ -0,0 +1,108 @@
+package aicommit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type webhookPayload struct {
+	Event   string `json:"event"`
+	Repo    string `json:"repo"`
+	Branch  string `json:"branch,omitempty"`
+	SHA     string `json:"sha"`
+	URL     string `json:"url,omitempty"`
+	Subject string `json:"subject"`
+	Message string `json:"message"`
+	Author  string `json:"author,omitempty"`
+	Files   int    `json:"files"`
+	LLM     bool   `json:"llm"`
+}
+
+func notifyCommit(ctx context.Context, opts Options, gen *generation) {
+	if opts.WebhookURL == "" {
+		return
+	}
+	if err := postWebhook(ctx, opts, commitPayload(ctx, opts, gen)); err != nil {
+		fmt.Fprintln(stderr(ctx), "warning: webhook failed:", err)
+	}
+}
+
+func commitPayload(ctx context.Context, opts Options, gen *generation) webhookPayload {
+	sha, _ := gitOutput(ctx, "rev-parse", "HEAD")
+	author, _ := gitOutput(ctx, "log", "-1", "--format=%an <%ae>")
+	var link string
+	repo, err := gitOutput(ctx, "remote", "get-url", "origin")
+	if err != nil {
+		root, _ := gitOutput(ctx, "rev-parse", "--show-toplevel")
+		repo = filepath.Base(root)
+	} else if f, ok := parseForge(repo, opts.ForgeHosts); ok {
+		repo, link = f.Host+"/"+f.Project, f.commitURL(sha)
+	} else if host, project, ok := parseRemoteURL(repo); ok {
+		repo = host + "/" + project
+	}
+	return webhookPayload{
+		Event:   "commit",
+		Repo:    repo,
+		Branch:  currentBranch(ctx),
+		SHA:     sha,
+		URL:     link,
+		Subject: firstLine(gen.Message),
+		Message: gen.Message,
+		Author:  author,
+		Files:   len(gen.Changes),
+		LLM:     gen.Explain.LLM,
+	}
+}
+
+func webhookBody(format string, p webhookPayload) any {
+	if format != "slack" {
+		return p
+	}
+	where := p.Repo
+	if p.Branch != "" {
+		where += "@" + p.Branch
+	}
+	text := fmt.Sprintf("aicommit: `%s` %s in %s", shortSHA(p.SHA), p.Subject, where)
+	return map[string]any{
+		"text": text,
+		"blocks": []map[string]any{{
+			"type": "section",
+			"text": map[string]string{"type": "mrkdwn", "text": text + "\n```" + p.Message + "```"},
+		}},
+	}
+}
+
+func postWebhook(ctx context.Context, opts Options, p webhookPayload) error {
+	payload, err := json.Marshal(webhookBody(opts.WebhookFormat, p))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, opts.WebhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "aicommit/"+version)
+	start := time.Now()
+	resp, err := httpClient(ctx).Do(req)
+	if err != nil {
+		infof(ctx, "webhook: request failed after %s: %v", since(start), err)
+		return err
+	}
+	defer resp.Body.Close()
+	infof(ctx, "webhook: http %d in %s", resp.StatusCode, since(start))
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("webhook http %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,297 @@
+package aicommit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	goModuleRe  = regexp.MustCompile(`^module\s+"?([^"\s]+)"?`)
+	majorSuffix = regexp.MustCompile(`^v[0-9]+$`)
+)
+
+var manifestNames = []string{"go.mod", "package.json", "Cargo.toml"}
+
+func packageScope(changes []Change, root string) (string, bool) {
+	if root == "" || len(changes) == 0 {
+		return "", false
+	}
+	patterns := workspacePatterns(root)
+	cache := map[string]string{}
+	var scope string
+	for i, ch := range changes {
+		name := nearestPackage(root, path.Dir(ch.Path), patterns, cache)
+		if name == "" {
+			return "", false
+		}
+		if i == 0 {
+			scope = name
+			continue
+		}
+		if scope != name {
+			return "", false
+		}
+	}
+	return scope, true
+}
+
+func nearestPackage(root, dir string, patterns []string, cache map[string]string) string {
+	if dir == "." || dir == "/" || dir == "" {
+		return ""
+	}
+	if name, ok := cache[dir]; ok {
+		return name
+	}
+	name := ""
+	if inWorkspace(patterns, dir) {
+		for _, manifest := range manifestNames {
+			if n := manifestName(filepath.Join(root, filepath.FromSlash(dir), manifest)); n != "" {
+				name = n
+				break
+			}
+		}
+	}
+	if name == "" {
+		name = nearestPackage(root, path.Dir(dir), patterns, cache)
+	}
+	cache[dir] = name
+	return name
+}
+
+func workspacePatterns(root string) []string {
+	var patterns []string
+	if data, err := os.ReadFile(filepath.Join(root, "go.work")); err == nil {
+		inUse := false
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if i := strings.Index(line, "//"); i != -1 {
+				line = strings.TrimSpace(line[:i])
+			}
+			switch {
+			case line == "use (":
+				inUse = true
+			case inUse && line == ")":
+				inUse = false
+			case inUse && line != "":
+				patterns = append(patterns, path.Clean(strings.Trim(line, `"`)))
+			case strings.HasPrefix(line, "use "):
+				patterns = append(patterns, path.Clean(strings.Trim(strings.TrimSpace(line[4:]), `"`)))
+			}
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "pnpm-workspace.yaml")); err == nil {
+		patterns = append(patterns, pnpmPackages(string(data))...)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "package.json")); err == nil {
+		var pkg struct {
+			Workspaces json.RawMessage `json:"workspaces"`
+		}
+		if json.Unmarshal(data, &pkg) == nil && len(pkg.Workspaces) > 0 {
+			var list []string
+			var nested struct {
+				Packages []string `json:"packages"`
+			}
+			if json.Unmarshal(pkg.Workspaces, &list) != nil && json.Unmarshal(pkg.Workspaces, &nested) == nil {
+				list = nested.Packages
+			}
+			patterns = append(patterns, list...)
+		}
+	}
+	out := patterns[:0]
+	for _, p := range patterns {
+		p = strings.TrimPrefix(strings.TrimSpace(p), "./")
+		if p != "" && p != "." && !strings.HasPrefix(p, "!") {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func pnpmPackages(doc string) []string {
+	var out []string
+	inPackages := false
+	for _, line := range strings.Split(doc, "\n") {
+		if i := strings.Index(line, "#"); i != -1 {
+			line = line[:i]
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if line[0] != ' ' && line[0] != '\t' && line[0] != '-' {
+			inPackages = strings.TrimSpace(strings.TrimSuffix(trimmed, ":")) == "packages"
+			continue
+		}
+		if inPackages && strings.HasPrefix(trimmed, "-") {
+			out = append(out, strings.Trim(strings.TrimSpace(trimmed[1:]), `"'`))
+		}
+	}
+	return out
+}
+
+func inWorkspace(patterns []string, dir string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if dir == p || strings.ContainsAny(p, "*?[") && matchGlob(p, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+func manifestName(file string) string {
+	switch filepath.Base(file) {
+	case "go.mod":
+		return goModuleName(file)
+	case "package.json":
+		return npmPackageName(file)
+	case "Cargo.toml":
+		return cargoPackageName(file)
+	default:
+		return ""
+	}
+}
+
+func goModuleName(file string) string {
+	f, err := os.Open(file)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		m := goModuleRe.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if len(m) < 2 {
+			continue
+		}
+		parts := strings.Split(m[1], "/")
+		name := parts[len(parts)-1]
+		if majorSuffix.MatchString(name) && len(parts) > 1 {
+			name = parts[len(parts)-2]
+		}
+		return name
+	}
+	return ""
+}
+
+func npmPackageName(file string) string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+	var pkg struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return ""
+	}
+	name := pkg.Name
+	if idx := strings.LastIndex(name, "/"); idx != -1 {
+		name = name[idx+1:]
+	}
+	return name
+}
+
+func cargoPackageName(file string) string {
+	f, err := os.Open(file)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	inPackage := false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inPackage = line == "[package]"
+			continue
+		}
+		if !inPackage {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if ok && strings.TrimSpace(key) == "name" {
+			return strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	return ""
+}
+
+type workspacePackage struct {
+	Name    string
+	Dir     string
+	Changes []Change
+}
+
+func packageDir(root, dir string, patterns []string, cache map[string]string) string {
+	if dir == "." || dir == "/" || dir == "" {
+		return ""
+	}
+	if found, ok := cache[dir]; ok {
+		return found
+	}
+	found := ""
+	if inWorkspace(patterns, dir) {
+		for _, manifest := range append([]string{"project.json"}, manifestNames...) {
+			if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(dir), manifest)); err == nil {
+				found = dir
+				break
+			}
+		}
+	}
+	if found == "" {
+		found = packageDir(root, path.Dir(dir), patterns, cache)
+	}
+	cache[dir] = found
+	return found
+}
+
+func packageDisplayName(root, dir string) string {
+	if dir == "" {
+		return "root"
+	}
+	base := filepath.Join(root, filepath.FromSlash(dir))
+	if data, err := os.ReadFile(filepath.Join(base, "project.json")); err == nil {
+		var project struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(data, &project) == nil && project.Name != "" {
+			return project.Name
+		}
+	}
+	for _, manifest := range manifestNames {
+		if name := manifestName(filepath.Join(base, manifest)); name != "" {
+			return name
+		}
+	}
+	return path.Base(dir)
+}
+
+func groupByPackage(root string, changes []Change) []workspacePackage {
+	patterns := workspacePatterns(root)
+	cache := map[string]string{}
+	index := map[string]int{}
+	var groups []workspacePackage
+	for _, ch := range changes {
+		dir := packageDir(root, path.Dir(ch.Path), patterns, cache)
+		i, ok := index[dir]
+		if !ok {
+			i = len(groups)
+			index[dir] = i
+			groups = append(groups, workspacePackage{Name: packageDisplayName(root, dir), Dir: dir})
+		}
+		groups[i].Changes = append(groups[i].Changes, ch)
+	}
+	sort.SliceStable(groups, func(a, b int) bool {
+		return groups[a].Dir != "" && (groups[b].Dir == "" || groups[a].Dir < groups[b].Dir)
+	})
+	return groups
+}
@@ -0,0 +1,291 @@
+package aicommit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type yamlLine struct {
+	indent int
+	text   string
+	num    int
+}
+
+func parseYAML(data string) (any, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		text := stripYAMLComment(raw)
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || trimmed == "---" || trimmed == "..." {
+			continue
+		}
+		lines = append(lines, yamlLine{indent: len(text) - len(strings.TrimLeft(text, " ")), text: trimmed, num: i + 1})
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	value, next, err := parseYAMLBlock(lines, 0, lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if next < len(lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", lines[next].num)
+	}
+	return value, nil
+}
+
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return strings.TrimRight(line[:i], " \t")
+		}
+	}
+	return strings.TrimRight(line, " \t")
+}
+
+func isYAMLSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func parseYAMLBlock(lines []yamlLine, i, indent int) (any, int, error) {
+	if isYAMLSeqItem(lines[i].text) {
+		return parseYAMLSeq(lines, i, indent)
+	}
+	return parseYAMLMap(lines, i, indent)
+}
+
+func parseYAMLSeq(lines []yamlLine, i, indent int) (any, int, error) {
+	var out []any
+	for i < len(lines) && lines[i].indent == indent && isYAMLSeqItem(lines[i].text) {
+		item := strings.TrimSpace(strings.TrimPrefix(lines[i].text, "-"))
+		switch {
+		case item == "":
+			if i+1 < len(lines) && lines[i+1].indent > indent {
+				value, next, err := parseYAMLBlock(lines, i+1, lines[i+1].indent)
+				if err != nil {
+					return nil, 0, err
+				}
+				out = append(out, value)
+				i = next
+				continue
+			}
+			out = append(out, nil)
+			i++
+		case isYAMLSeqItem(item) || yamlKeyValue(item):
+			inner := indent + len(lines[i].text) - len(item)
+			rest := append([]yamlLine{{indent: inner, text: item, num: lines[i].num}}, lines[i+1:]...)
+			value, next, err := parseYAMLBlock(rest, 0, inner)
+			if err != nil {
+				return nil, 0, err
+			}
+			out = append(out, value)
+			i += next
+		default:
+			value, err := parseYAMLScalar(item)
+			if err != nil {
+				return nil, 0, fmt.Errorf("line %d: %w", lines[i].num, err)
+			}
+			out = append(out, value)
+			i++
+		}
+	}
+	return out, i, nil
+}
+
+func yamlKeyValue(text string) bool {
+	_, _, ok := cutYAMLKey(text)
+	return ok
+}
+
+func cutYAMLKey(text string) (string, string, bool) {
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		return "", "", false
+	}
+	if text[0] == '"' || text[0] == '\'' {
+		end := strings.IndexByte(text[1:], text[0])
+		if end == -1 {
+			return "", "", false
+		}
+		rest := text[end+2:]
+		if rest != ":" && !strings.HasPrefix(rest, ": ") {
+			return "", "", false
+		}
+		return text[1 : end+1], strings.TrimSpace(rest[1:]), true
+	}
+	idx := strings.Index(text, ": ")
+	if idx == -1 {
+		if strings.HasSuffix(text, ":") {
+			return strings.TrimSpace(text[:len(text)-1]), "", true
+		}
+		return "", "", false
+	}
+	return strings.TrimSpace(text[:idx]), strings.TrimSpace(text[idx+2:]), true
+}
+
+func parseYAMLMap(lines []yamlLine, i, indent int) (any, int, error) {
+	out := map[string]any{}
+	for i < len(lines) && lines[i].indent == indent {
+		key, raw, ok := cutYAMLKey(lines[i].text)
+		if !ok {
+			return nil, 0, fmt.Errorf("line %d: expected key: value", lines[i].num)
+		}
+		num := lines[i].num
+		i++
+		switch {
+		case raw == "|" || raw == ">" || raw == "|-" || raw == ">-":
+			var parts []string
+			for i < len(lines) && lines[i].indent > indent {
+				parts = append(parts, lines[i].text)
+				i++
+			}
+			sep := "\n"
+			if raw[0] == '>' {
+				sep = " "
+			}
+			out[key] = strings.Join(parts, sep)
+		case raw != "":
+			value, err := parseYAMLScalar(raw)
+			if err != nil {
+				return nil, 0, fmt.Errorf("line %d: %w", num, err)
+			}
+			out[key] = value
+		case i < len(lines) && (lines[i].indent > indent || (lines[i].indent == indent && isYAMLSeqItem(lines[i].text))):
+			value, next, err := parseYAMLBlock(lines, i, lines[i].indent)
+			if err != nil {
+				return nil, 0, err
+			}
+			out[key] = value
+			i = next
+		default:
+			out[key] = nil
+		}
+	}
+	return out, i, nil
+}
+
+func parseYAMLScalar(raw string) (any, error) {
+	if strings.HasPrefix(raw, "[") || strings.HasPrefix(raw, "{") {
+		value, rest, err := parseYAMLFlow(raw)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(rest) != "" {
+			return nil, fmt.Errorf("unexpected %q after flow value", rest)
+		}
+		return value, nil
+	}
+	return yamlPlain(raw)
+}
+
+func yamlPlain(raw string) (any, error) {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		return strconv.Unquote(raw)
+	case strings.HasPrefix(raw, "'"):
+		if len(raw) < 2 || !strings.HasSuffix(raw, "'") {
+			return nil, fmt.Errorf("unterminated string %s", raw)
+		}
+		return strings.ReplaceAll(raw[1:len(raw)-1], "''", "'"), nil
+	}
+	switch strings.ToLower(raw) {
+	case "true", "yes", "on":
+		return true, nil
+	case "false", "no", "off":
+		return false, nil
+	case "null", "~", "":
+		return nil, nil
+	}
+	if n, err := strconv.ParseFloat(raw, 64); err == nil {
+		return n, nil
+	}
+	return raw, nil
+}
+
+func parseYAMLFlow(s string) (any, string, error) {
+	s = strings.TrimLeft(s, " ")
+	if s == "" {
+		return nil, "", fmt.Errorf("unexpected end of flow value")
+	}
+	switch s[0] {
+	case '[':
+		var out []any
+		s = strings.TrimLeft(s[1:], " ")
+		for !strings.HasPrefix(s, "]") {
+			value, rest, err := parseYAMLFlow(s)
+			if err != nil {
+				return nil, "", err
+			}
+			out = append(out, value)
+			s = strings.TrimLeft(rest, " ")
+			if strings.HasPrefix(s, ",") {
+				s = strings.TrimLeft(s[1:], " ")
+			} else if !strings.HasPrefix(s, "]") {
+				return nil, "", fmt.Errorf("expected , or ] in flow sequence")
+			}
+		}
+		return out, s[1:], nil
+	case '{':
+		out := map[string]any{}
+		s = strings.TrimLeft(s[1:], " ")
+		for !strings.HasPrefix(s, "}") {
+			idx := strings.Index(s, ":")
+			if idx == -1 {
+				return nil, "", fmt.Errorf("expected key: value in flow mapping")
+			}
+			key, err := yamlPlain(s[:idx])
+			if err != nil {
+				return nil, "", err
+			}
+			value, rest, err := parseYAMLFlow(s[idx+1:])
+			if err != nil {
+				return nil, "", err
+			}
+			out[fmt.Sprint(key)] = value
+			s = strings.TrimLeft(rest, " ")
+			if strings.HasPrefix(s, ",") {
+				s = strings.TrimLeft(s[1:], " ")
+			} else if !strings.HasPrefix(s, "}") {
+				return nil, "", fmt.Errorf("expected , or } in flow mapping")
+			}
+		}
+		return out, s[1:], nil
+	case '"', '\'':
+		end := 1
+		for end < len(s) {
+			if s[end] == '\\' && s[0] == '"' {
+				end += 2
+				continue
+			}
+			if s[end] == s[0] {
+				if s[0] == '\'' && end+1 < len(s) && s[end+1] == '\'' {
+					end += 2
+					continue
+				}
+				break
+			}
+			end++
+		}
+		if end >= len(s) {
+			return nil, "", fmt.Errorf("unterminated string")
+		}
+		value, err := yamlPlain(s[:end+1])
+		return value, s[end+1:], err
+	}
+	end := strings.IndexAny(s, ",]}")
+	if end == -1 {
+		end = len(s)
+	}
+	value, err := yamlPlain(s[:end])
+	return value, s[end:], err
+}
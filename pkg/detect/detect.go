@@ -0,0 +1,346 @@
+package detect
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	Docs   = "docs"
+	Test   = "test"
+	CI     = "ci"
+	Build  = "build"
+	Chore  = "chore"
+	I18n   = "i18n"
+	Config = "config"
+	Infra  = "infra"
+	Assets = "assets"
+	Code   = "code"
+)
+
+var (
+	goExportedRe   = regexp.MustCompile(`^(func\s+(?:\([^)]+\)\s+)?|type\s+|var\s+|const\s+)([A-Z][A-Za-z0-9_]*)`)
+	jsExportedRe   = regexp.MustCompile(`^export\s+(?:default\s+)?(?:function|class|const|let|var|interface|type)\s+([A-Z][A-Za-z0-9_]*)`)
+	rustExportedRe = regexp.MustCompile(`^(?:pub\s+)?(?:fn|struct|enum|trait)\s+([A-Z][A-Za-z0-9_]*)`)
+)
+
+var infraDirs = map[string]bool{
+	"k8s":            true,
+	"kubernetes":     true,
+	"kube":           true,
+	"manifests":      true,
+	"helm":           true,
+	"charts":         true,
+	"terraform":      true,
+	"ansible":        true,
+	"playbooks":      true,
+	"roles":          true,
+	"kustomize":      true,
+	"infra":          true,
+	"infrastructure": true,
+}
+
+var assetKinds = map[string]string{
+	".png":   "image",
+	".jpg":   "image",
+	".jpeg":  "image",
+	".gif":   "image",
+	".webp":  "image",
+	".avif":  "image",
+	".bmp":   "image",
+	".ico":   "image",
+	".icns":  "image",
+	".tif":   "image",
+	".tiff":  "image",
+	".psd":   "image",
+	".woff":  "font",
+	".woff2": "font",
+	".ttf":   "font",
+	".otf":   "font",
+	".eot":   "font",
+	".mp3":   "audio",
+	".wav":   "audio",
+	".ogg":   "audio",
+	".flac":  "audio",
+	".mp4":   "video",
+	".webm":  "video",
+	".mov":   "video",
+	".pdf":   "document",
+}
+
+func CategorizePath(path string) string {
+	lower := strings.ToLower(path)
+	base := strings.ToLower(filepath.Base(path))
+	ext := strings.ToLower(filepath.Ext(path))
+
+	if lower == "readme" || strings.HasPrefix(lower, "readme.") || strings.HasPrefix(lower, "changelog") || strings.HasPrefix(lower, "license") || strings.HasPrefix(lower, "contributing") {
+		return Docs
+	}
+	if strings.HasPrefix(lower, "docs/") || ext == ".md" || ext == ".rst" || ext == ".adoc" {
+		return Docs
+	}
+	if IsI18nPath(path) {
+		return I18n
+	}
+	if strings.Contains(lower, "/test/") || strings.Contains(lower, "/tests/") || strings.HasSuffix(base, "_test.go") || strings.Contains(base, ".spec.") || strings.Contains(base, ".test.") {
+		return Test
+	}
+	if strings.HasPrefix(lower, ".github/workflows/") || strings.HasPrefix(lower, ".github/actions/") || strings.HasPrefix(lower, ".circleci/") || strings.HasPrefix(lower, ".gitlab-ci") || base == "jenkinsfile" || base == "azure-pipelines.yml" || base == "appveyor.yml" {
+		return CI
+	}
+	if strings.HasPrefix(lower, ".buildkite/") || strings.HasPrefix(lower, ".teamcity/") || strings.HasPrefix(lower, ".woodpecker/") || strings.HasPrefix(lower, ".tekton/") || strings.HasPrefix(lower, "tekton/") || strings.Contains(lower, "/.tekton/") {
+		return CI
+	}
+	if base == ".drone.yml" || base == ".drone.yaml" || base == ".woodpecker.yml" || base == ".woodpecker.yaml" || base == ".cirrus.yml" || base == ".cirrus.star" || base == "bitbucket-pipelines.yml" || base == "buildkite.yml" || base == "buildkite.yaml" {
+		return CI
+	}
+	if IsInfraPath(path) {
+		return Infra
+	}
+	if base == "makefile" || base == "go.mod" || base == "go.sum" || base == "package.json" || base == "package-lock.json" || base == "pnpm-lock.yaml" || base == "yarn.lock" || base == "cargo.toml" || base == "cargo.lock" || base == "pom.xml" || base == "build.gradle" || base == "build.gradle.kts" || base == "settings.gradle" || base == "settings.gradle.kts" || base == "gradle.properties" || base == "cmakelists.txt" {
+		return Build
+	}
+	if strings.HasPrefix(lower, "build/") || strings.HasPrefix(lower, "docker/") || strings.HasPrefix(lower, "vendor/") || strings.HasPrefix(lower, "third_party/") {
+		return Build
+	}
+	if strings.HasPrefix(lower, "scripts/") || strings.HasPrefix(lower, "tools/") || strings.HasPrefix(lower, ".vscode/") {
+		return Chore
+	}
+	if base == ".gitignore" || base == ".gitattributes" || base == ".editorconfig" || strings.HasPrefix(base, ".prettierrc") || strings.HasPrefix(base, ".eslintrc") || base == "tsconfig.json" || base == "eslint.config.js" || base == ".pre-commit-config.yaml" || base == "ruff.toml" {
+		return Chore
+	}
+	if IsAssetPath(path) {
+		return Assets
+	}
+	if IsConfigPath(path) {
+		return Config
+	}
+	if strings.HasPrefix(lower, "config/") {
+		return Chore
+	}
+	return Code
+}
+
+func IsI18nPath(path string) bool {
+	lower := "/" + strings.ToLower(path)
+	if strings.Contains(lower, "/locales/") || strings.Contains(lower, "/locale/") || strings.Contains(lower, "/i18n/") || strings.Contains(lower, "/l10n/") {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".po" || ext == ".pot" || ext == ".arb"
+}
+
+func IsInfraPath(path string) bool {
+	lower := strings.ToLower(path)
+	base := strings.ToLower(filepath.Base(path))
+	ext := strings.ToLower(filepath.Ext(path))
+	if base == "dockerfile" || strings.HasPrefix(base, "dockerfile.") || ext == ".dockerfile" || base == ".dockerignore" {
+		return true
+	}
+	if strings.HasPrefix(base, "docker-compose") || strings.HasPrefix(base, "compose.") {
+		return true
+	}
+	if ext == ".tf" || ext == ".tfvars" || (ext == ".hcl" && strings.Contains(base, "terraform")) {
+		return true
+	}
+	if base == "chart.yaml" || base == "kustomization.yaml" || base == "kustomization.yml" || base == "helmfile.yaml" || base == "ansible.cfg" {
+		return true
+	}
+	dirs := strings.Split(lower, "/")
+	dirs = dirs[:len(dirs)-1]
+	if len(dirs) > 0 && IsInfraDir(dirs[0]) {
+		return true
+	}
+	if ext != ".yaml" && ext != ".yml" && ext != ".tpl" {
+		return false
+	}
+	for _, part := range dirs {
+		if IsInfraDir(part) {
+			return true
+		}
+	}
+	return false
+}
+
+func IsAssetPath(path string) bool {
+	_, ok := assetKinds[strings.ToLower(filepath.Ext(path))]
+	return ok
+}
+
+func AssetKind(path string) string {
+	if kind, ok := assetKinds[strings.ToLower(filepath.Ext(path))]; ok {
+		return kind
+	}
+	return "binary"
+}
+
+func IsConfigPath(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".toml", ".ini", ".json", ".cfg", ".conf", ".properties":
+		return true
+	default:
+		return false
+	}
+}
+
+func TopLevel(path string) string {
+	parts := strings.Split(path, "/")
+	if len(parts) == 0 {
+		return ""
+	}
+	if len(parts) == 1 {
+		return ""
+	}
+	return parts[0]
+}
+
+func ScopeFromPath(path string) string {
+	if top := TopLevel(path); top != "" {
+		return top
+	}
+	base := filepath.Base(path)
+	if base == "" || base == "." {
+		return ""
+	}
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+func PrimaryArea(path string) string {
+	parts := strings.Split(path, "/")
+	if len(parts) == 0 {
+		return ""
+	}
+	if len(parts) == 1 {
+		return strings.TrimSuffix(parts[0], filepath.Ext(parts[0]))
+	}
+	if parts[0] == "cmd" || parts[0] == "pkg" || parts[0] == "internal" || parts[0] == "src" || parts[0] == "lib" || parts[0] == "app" {
+		if len(parts) >= 2 {
+			return parts[0] + "/" + parts[1]
+		}
+	}
+	return parts[0]
+}
+
+func SanitizeScope(scope string) string {
+	scope = strings.TrimSpace(scope)
+	scope = strings.ToLower(scope)
+	scope = strings.ReplaceAll(scope, " ", "-")
+	var b strings.Builder
+	for _, r := range scope {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' || r == '/' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func DiffHasKeyword(diff string, keywords []string) bool {
+	if diff == "" {
+		return false
+	}
+	lowerKeywords := make([]string, len(keywords))
+	for i, kw := range keywords {
+		lowerKeywords[i] = strings.ToLower(kw)
+	}
+	lines := strings.Split(diff, "\n")
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		if IsDiffHeader(line) {
+			continue
+		}
+		if line[0] != '+' && line[0] != '-' {
+			continue
+		}
+		content := strings.ToLower(strings.TrimSpace(line[1:]))
+		for _, kw := range lowerKeywords {
+			if strings.Contains(content, kw) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func IsDiffHeader(line string) bool {
+	return strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") || strings.HasPrefix(line, "@@") || strings.HasPrefix(line, "diff ") || strings.HasPrefix(line, "index ")
+}
+
+func IsCommentLine(content string) bool {
+	if strings.HasPrefix(content, "#") {
+		for _, directive := range []string{"#[", "#!", "#include", "#define", "#if", "#endif", "#else", "#elif", "#undef", "#pragma", "#import"} {
+			if strings.HasPrefix(content, directive) {
+				return false
+			}
+		}
+		return true
+	}
+	if content == "*" || strings.HasPrefix(content, "* ") || strings.HasPrefix(content, "*/") {
+		return true
+	}
+	for _, prefix := range []string{"//", "/*", "--", ";", `"""`, "'''", "<!--", "-->"} {
+		if strings.HasPrefix(content, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func FindExportedNames(diff string, prefix byte) []string {
+	if diff == "" {
+		return nil
+	}
+	set := map[string]struct{}{}
+	lines := strings.Split(diff, "\n")
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		if line[0] != prefix {
+			continue
+		}
+		if IsDiffHeader(line) {
+			continue
+		}
+		content := strings.TrimSpace(line[1:])
+		if m := goExportedRe.FindStringSubmatch(content); len(m) > 2 {
+			set[m[2]] = struct{}{}
+			continue
+		}
+		if m := jsExportedRe.FindStringSubmatch(content); len(m) > 1 {
+			set[m[1]] = struct{}{}
+			continue
+		}
+		if m := rustExportedRe.FindStringSubmatch(content); len(m) > 1 {
+			set[m[1]] = struct{}{}
+			continue
+		}
+	}
+	var out []string
+	for name := range set {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func RemovedExportedNames(diff string) []string {
+	added := map[string]struct{}{}
+	for _, name := range FindExportedNames(diff, '+') {
+		added[name] = struct{}{}
+	}
+	var out []string
+	for _, name := range FindExportedNames(diff, '-') {
+		if _, ok := added[name]; !ok {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+func IsInfraDir(name string) bool {
+	return infraDirs[name]
+}
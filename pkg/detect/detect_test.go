@@ -0,0 +1,26 @@
+package detect
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func BenchmarkFindExportedNames(b *testing.B) {
+	var sb strings.Builder
+	for i := range 200000 {
+		switch i % 4 {
+		case 0:
+			fmt.Fprintf(&sb, "+func Handle%d(ctx context.Context, req *Request) (*Response, error) {\n", i)
+		case 1:
+			fmt.Fprintf(&sb, "-type Legacy%d struct{}\n", i)
+		default:
+			fmt.Fprintf(&sb, "+\tvalue := compute(%d)\n", i)
+		}
+	}
+	diff := sb.String()
+	b.ReportAllocs()
+	for b.Loop() {
+		FindExportedNames(diff, '+')
+	}
+}
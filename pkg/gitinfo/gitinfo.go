@@ -0,0 +1,313 @@
+package gitinfo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Mode string
+
+const (
+	ModeAuto     Mode = "auto"
+	ModeStaged   Mode = "staged"
+	ModeUnstaged Mode = "unstaged"
+	ModeAll      Mode = "all"
+	ModeDiff     Mode = "diff"
+)
+
+type Change struct {
+	Path       string
+	OldPath    string
+	Status     string
+	Similarity int
+	Source     Mode
+}
+
+type FileStat struct {
+	Path    string
+	Added   int
+	Deleted int
+	Binary  bool
+}
+
+type ChangeSet struct {
+	Root      string
+	Mode      Mode
+	Changes   []Change
+	Untracked []Change
+	Diff      string
+	Stats     []FileStat
+}
+
+type Runner func(ctx context.Context, args ...string) ([]byte, error)
+
+func Command(dir string) Runner {
+	return func(ctx context.Context, args ...string) ([]byte, error) {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = dir
+		cmd.WaitDelay = time.Second
+		return cmd.Output()
+	}
+}
+
+func CollectChanges(ctx context.Context, run Runner, includeUntracked bool) ([]Change, []Change, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var stagedRaw, unstagedRaw, untrackedRaw []byte
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	spawn := func(dst *[]byte, args ...string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out, err := run(ctx, args...)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			*dst = out
+		}()
+	}
+	spawn(&stagedRaw, "diff", "--cached", "--name-status", "-z")
+	spawn(&unstagedRaw, "diff", "--name-status", "-z")
+	if includeUntracked {
+		spawn(&untrackedRaw, "ls-files", "--others", "--exclude-standard", "-z")
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, nil, firstErr
+	}
+	staged := ParseNameStatus(stagedRaw, ModeStaged)
+	unstaged := append(ParseNameStatus(unstagedRaw, ModeUnstaged), ParseUntracked(untrackedRaw)...)
+	return staged, unstaged, nil
+}
+
+func CollectNumstat(ctx context.Context, run Runner, mode Mode) ([]FileStat, error) {
+	numstat := func(args ...string) ([]FileStat, error) {
+		out, err := run(ctx, append([]string{"diff"}, append(args, "--numstat")...)...)
+		if err != nil {
+			return nil, err
+		}
+		return ParseNumstat(strings.TrimRight(string(out), "\n")), nil
+	}
+	switch mode {
+	case ModeStaged:
+		return numstat("--cached")
+	case ModeUnstaged:
+		return numstat()
+	case ModeAll:
+		var unstaged, staged []FileStat
+		var unstagedErr, stagedErr error
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			unstaged, unstagedErr = numstat()
+		}()
+		go func() {
+			defer wg.Done()
+			staged, stagedErr = numstat("--cached")
+		}()
+		wg.Wait()
+		if err := errors.Join(unstagedErr, stagedErr); err != nil {
+			return nil, err
+		}
+		return MergeNumstat(unstaged, staged), nil
+	default:
+		return nil, nil
+	}
+}
+
+func ParseNameStatus(data []byte, source Mode) []Change {
+	if len(data) == 0 {
+		return nil
+	}
+	fields := bytes.Split(data, []byte{0})
+	var out []Change
+	for i := 0; i < len(fields); {
+		entry := string(fields[i])
+		if entry == "" {
+			i++
+			continue
+		}
+
+		if strings.Contains(entry, "\t") {
+			parts := strings.SplitN(entry, "\t", 2)
+			if len(parts) < 2 {
+				i++
+				continue
+			}
+			status := parts[0]
+			statusChar := status
+			if len(status) > 0 {
+				statusChar = status[:1]
+			}
+			if statusChar == "R" || statusChar == "C" {
+				oldPath := parts[1]
+				if i+1 >= len(fields) {
+					break
+				}
+				newPath := string(fields[i+1])
+				out = append(out, Change{Path: newPath, OldPath: oldPath, Status: statusChar, Similarity: SimilarityScore(status), Source: source})
+				i += 2
+				continue
+			}
+			path := parts[1]
+			out = append(out, Change{Path: path, Status: statusChar, Source: source})
+			i++
+			continue
+		}
+
+		status := entry
+		statusChar := status
+		if len(status) > 0 {
+			statusChar = status[:1]
+		}
+		if statusChar == "R" || statusChar == "C" {
+			if i+2 >= len(fields) {
+				break
+			}
+			oldPath := string(fields[i+1])
+			newPath := string(fields[i+2])
+			if oldPath != "" && newPath != "" {
+				out = append(out, Change{Path: newPath, OldPath: oldPath, Status: statusChar, Similarity: SimilarityScore(status), Source: source})
+			}
+			i += 3
+			continue
+		}
+		if i+1 >= len(fields) {
+			break
+		}
+		path := string(fields[i+1])
+		if path != "" {
+			out = append(out, Change{Path: path, Status: statusChar, Source: source})
+		}
+		i += 2
+	}
+	return out
+}
+
+func SimilarityScore(status string) int {
+	if len(status) < 2 {
+		return 0
+	}
+	score, err := strconv.Atoi(status[1:])
+	if err != nil {
+		return 0
+	}
+	return score
+}
+
+func ParseUntracked(data []byte) []Change {
+	if len(data) == 0 {
+		return nil
+	}
+	fields := bytes.Split(data, []byte{0})
+	var out []Change
+	for _, f := range fields {
+		path := strings.TrimSpace(string(f))
+		if path == "" {
+			continue
+		}
+		out = append(out, Change{Path: path, Status: "U", Source: ModeUnstaged})
+	}
+	return out
+}
+
+func SelectChanges(mode Mode, staged, unstaged []Change) (Mode, []Change) {
+	switch mode {
+	case ModeStaged:
+		return ModeStaged, staged
+	case ModeUnstaged:
+		return ModeUnstaged, unstaged
+	case ModeAll:
+		return ModeAll, MergeChanges(staged, unstaged)
+	default:
+		if len(staged) > 0 {
+			return ModeStaged, staged
+		}
+		return ModeUnstaged, unstaged
+	}
+}
+
+func MergeChanges(staged, unstaged []Change) []Change {
+	byPath := map[string]Change{}
+	for _, ch := range staged {
+		byPath[ch.Path] = ch
+	}
+	for _, ch := range unstaged {
+		if existing, ok := byPath[ch.Path]; ok {
+			existing.Source = ModeAll
+			byPath[ch.Path] = existing
+			continue
+		}
+		ch.Source = ModeAll
+		byPath[ch.Path] = ch
+	}
+	out := make([]Change, 0, len(byPath))
+	for _, ch := range byPath {
+		out = append(out, ch)
+	}
+	return out
+}
+
+func MergeNumstat(lists ...[]FileStat) []FileStat {
+	var combined []FileStat
+	byPath := map[string]int{}
+	for _, stats := range lists {
+		for _, st := range stats {
+			i, ok := byPath[st.Path]
+			if !ok {
+				byPath[st.Path] = len(combined)
+				combined = append(combined, st)
+				continue
+			}
+			combined[i].Added += st.Added
+			combined[i].Deleted += st.Deleted
+			combined[i].Binary = combined[i].Binary || st.Binary
+		}
+	}
+	return combined
+}
+
+func ParseNumstat(raw string) []FileStat {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	var out []FileStat
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		stat := FileStat{Path: parts[2]}
+		if parts[0] == "-" && parts[1] == "-" {
+			stat.Binary = true
+			out = append(out, stat)
+			continue
+		}
+		added, err1 := strconv.Atoi(parts[0])
+		deleted, err2 := strconv.Atoi(parts[1])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		stat.Added = added
+		stat.Deleted = deleted
+		out = append(out, stat)
+	}
+	return out
+}
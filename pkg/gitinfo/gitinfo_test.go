@@ -0,0 +1,32 @@
+package gitinfo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func syntheticNameStatus(files int) []byte {
+	var b strings.Builder
+	for i := range files {
+		switch i % 10 {
+		case 0:
+			fmt.Fprintf(&b, "R087\x00pkg/old%d/file.go\x00pkg/new%d/file.go\x00", i, i)
+		case 1:
+			fmt.Fprintf(&b, "A\x00internal/mod%d/handler.go\x00", i)
+		case 2:
+			fmt.Fprintf(&b, "D\x00docs/page%d.md\x00", i)
+		default:
+			fmt.Fprintf(&b, "M\x00src/module%d/service_%d.go\x00", i/100, i)
+		}
+	}
+	return []byte(b.String())
+}
+
+func BenchmarkParseNameStatus(b *testing.B) {
+	raw := syntheticNameStatus(5000)
+	b.ReportAllocs()
+	for b.Loop() {
+		ParseNameStatus(raw, ModeStaged)
+	}
+}
@@ -0,0 +1,299 @@
+package llm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	ProviderOpenAI     = "openai"
+	ProviderOpenRouter = "openrouter"
+)
+
+const gzipMinBytes = 16 << 10
+
+type Request struct {
+	Provider    string
+	Endpoint    string
+	APIKey      string
+	Model       string
+	System      string
+	User        string
+	Temperature *float64
+	MaxTokens   int
+	Gzip        string
+	RequestID   string
+	UserAgent   string
+	Referer     string
+	Title       string
+	Timeout     time.Duration
+	Client      *http.Client
+	Logf        func(format string, args ...any)
+	Debugf      func(format string, args ...any)
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature *float64      `json:"temperature,omitempty"`
+	MaxTokens   *int          `json:"max_completion_tokens,omitempty"`
+}
+
+type chatChoice struct {
+	Message chatMessage `json:"message"`
+	Text    string      `json:"text"`
+}
+
+type chatResponse struct {
+	Choices []chatChoice `json:"choices"`
+}
+
+func Complete(ctx context.Context, r Request) (string, error) {
+	logf, debugf := r.Logf, r.Debugf
+	if logf == nil {
+		logf = func(string, ...any) {}
+	}
+	if debugf == nil {
+		debugf = func(string, ...any) {}
+	}
+	provider := strings.ToLower(strings.TrimSpace(r.Provider))
+	if provider == "" {
+		provider = ProviderOpenAI
+	}
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	requestID := r.RequestID
+	if requestID == "" {
+		requestID = NewRequestID()
+	}
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	endpoint := Endpoint(provider, r.Endpoint)
+
+	var maxTokens *int
+	if r.MaxTokens > 0 {
+		value := r.MaxTokens
+		maxTokens = &value
+	}
+	body, err := json.Marshal(chatRequest{
+		Model:       r.Model,
+		Messages:    []chatMessage{{Role: "system", Content: r.System}, {Role: "user", Content: r.User}},
+		Temperature: r.Temperature,
+		MaxTokens:   maxTokens,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	send := func(compress bool) (*http.Response, error) {
+		payload := body
+		if compress {
+			var buf bytes.Buffer
+			zw := gzip.NewWriter(&buf)
+			if _, err := zw.Write(body); err != nil {
+				return nil, err
+			}
+			if err := zw.Close(); err != nil {
+				return nil, err
+			}
+			payload = buf.Bytes()
+			debugf("llm: gzip %d -> %d bytes", len(body), len(payload))
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+r.APIKey)
+		if r.UserAgent != "" {
+			req.Header.Set("User-Agent", r.UserAgent)
+		}
+		req.Header.Set("X-Request-Id", requestID)
+		if compress {
+			req.Header.Set("Content-Encoding", "gzip")
+		}
+		if provider == ProviderOpenRouter {
+			if r.Referer != "" {
+				req.Header.Set("HTTP-Referer", r.Referer)
+			}
+			if r.Title != "" {
+				req.Header.Set("X-Title", r.Title)
+			}
+		}
+		start := time.Now()
+		resp, err := client.Do(req)
+		if err != nil {
+			logf("llm: request %s failed after %s: %v", requestID, since(start), err)
+			return nil, err
+		}
+		logf("llm: request %s http %d in %s", requestID, resp.StatusCode, since(start))
+		return resp, nil
+	}
+
+	compress := UseGzip(r.Gzip, provider, r.Endpoint, len(body))
+	resp, err := send(compress)
+	if err != nil {
+		return "", err
+	}
+	if compress && r.Gzip != "on" && resp.StatusCode == http.StatusUnsupportedMediaType {
+		resp.Body.Close()
+		logf("llm: endpoint rejected gzip, retrying uncompressed")
+		if resp, err = send(false); err != nil {
+			return "", err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		payload, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("llm http %d (request id %s): %s", resp.StatusCode, requestID, strings.TrimSpace(string(payload)))
+	}
+
+	var response chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", err
+	}
+	if len(response.Choices) == 0 {
+		return "", errors.New("llm response has no choices")
+	}
+
+	content := strings.TrimSpace(response.Choices[0].Message.Content)
+	if content == "" {
+		content = strings.TrimSpace(response.Choices[0].Text)
+	}
+	return content, nil
+}
+
+func since(start time.Time) string {
+	return time.Since(start).Round(time.Millisecond).String()
+}
+
+func UseGzip(mode, provider, endpoint string, size int) bool {
+	switch mode {
+	case "on":
+		return true
+	case "off":
+		return false
+	}
+	if size < gzipMinBytes {
+		return false
+	}
+	return strings.TrimSpace(endpoint) == "" && (provider == ProviderOpenAI || provider == ProviderOpenRouter)
+}
+
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+func NewRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	h := hex.EncodeToString(b[:])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+func Endpoint(provider string, override string) string {
+	if strings.TrimSpace(override) != "" {
+		return override
+	}
+	switch provider {
+	case ProviderOpenRouter:
+		return "https://openrouter.ai/api/v1/chat/completions"
+	default:
+		return "https://api.openai.com/v1/chat/completions"
+	}
+}
+
+func CheckEndpoint(endpoint string, allowInsecure bool) error {
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		return nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid llm endpoint: %s", endpoint)
+	}
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		if allowInsecure || IsLoopbackHost(u.Hostname()) {
+			return nil
+		}
+		return fmt.Errorf("refusing plain-HTTP llm endpoint %s: the API key would be sent in cleartext (use https or -allow-insecure-endpoint)", u.Host)
+	default:
+		return fmt.Errorf("unsupported llm endpoint scheme: %s", u.Scheme)
+	}
+}
+
+func IsLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func TruncateDiff(diff string, maxBytes int) (string, bool) {
+	if maxBytes <= 0 || len(diff) <= maxBytes {
+		return diff, false
+	}
+	return diff[:maxBytes], true
+}
+
+func CleanMessage(input string) string {
+	s := strings.TrimSpace(input)
+	if s == "" {
+		return ""
+	}
+	if strings.HasPrefix(s, "```") {
+		s = strings.TrimPrefix(s, "```")
+		s = strings.TrimSpace(s)
+		if idx := strings.Index(s, "\n"); idx != -1 {
+			first := strings.TrimSpace(s[:idx])
+			if len(first) > 0 && len(first) <= 12 && !strings.Contains(first, " ") {
+				s = strings.TrimSpace(s[idx+1:])
+			}
+		}
+		if end := strings.LastIndex(s, "```"); end != -1 {
+			s = strings.TrimSpace(s[:end])
+		}
+	}
+
+	lower := strings.ToLower(strings.TrimSpace(s))
+	if strings.HasPrefix(lower, "commit message:") {
+		s = strings.TrimSpace(s[len("commit message:"):])
+	}
+	if strings.HasPrefix(lower, "message:") {
+		s = strings.TrimSpace(s[len("message:"):])
+	}
+
+	s = strings.Trim(s, "\"`")
+	return strings.TrimSpace(s)
+}
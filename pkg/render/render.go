@@ -1,110 +1,63 @@
-package main
+package render
 
 import (
 	"fmt"
-	"io"
-	"os"
+	"regexp"
 	"sort"
 	"strings"
 	"unicode/utf8"
+
+	"github.com/skrashevich/aicommit/pkg/gitinfo"
 )
 
-func validFormat(format Format) bool {
-	switch format {
-	case FormatConventional, FormatPlain, FormatGitmoji:
-		return true
-	default:
-		return false
-	}
-}
+var trailerPattern = regexp.MustCompile(`^(BREAKING CHANGE|BREAKING-CHANGE|[\w-]+)(: | #)`)
 
-func validBody(body BodyMode) bool {
-	switch body {
-	case BodyAuto, BodyNone, BodyFiles, BodyStats, BodySummary:
-		return true
-	default:
-		return false
-	}
-}
+type Format string
 
-func validMode(mode Mode) bool {
-	switch mode {
-	case ModeAuto, ModeStaged, ModeUnstaged, ModeAll:
-		return true
-	default:
-		return false
-	}
-}
+type BodyMode string
 
-func detectLang() string {
-	for _, key := range []string{"LC_ALL", "LANG"} {
-		val := strings.ToLower(strings.TrimSpace(os.Getenv(key)))
-		if val == "" {
-			continue
-		}
-		if strings.HasPrefix(val, "ru") || strings.Contains(val, "_ru") {
-			return "ru"
-		}
-		return "en"
-	}
-	return "en"
-}
+const (
+	Conventional Format = "conventional"
+	Plain        Format = "plain"
+	Gitmoji      Format = "gitmoji"
+)
 
-func buildSubject(commitType, scope string, changes []Change, opts Options) string {
-	verb, defaultTarget := verbForType(commitType, opts.Lang)
-	target := inferTarget(changes, scope)
-	if target == "" {
-		target = defaultTarget
-	}
-	if target == "" {
-		if opts.Lang == "ru" {
-			target = "изменения"
-		} else {
-			target = "changes"
-		}
+const (
+	BodyAuto    BodyMode = "auto"
+	BodyNone    BodyMode = "none"
+	BodyFiles   BodyMode = "files"
+	BodyStats   BodyMode = "stats"
+	BodySummary BodyMode = "summary"
+)
+
+func Subject(subject string, format Format, max int) string {
+	if format == Conventional || format == Gitmoji {
+		subject = LowerFirst(subject)
 	}
-	subject := strings.TrimSpace(verb + " " + target)
-	return subject
+	return TrimSubject(subject, max)
 }
 
-func inferTarget(changes []Change, scope string) string {
-	if len(changes) == 1 {
-		return primaryArea(changes[0].Path)
-	}
-	if scope != "" {
-		return scope
-	}
-	counts := map[string]int{}
-	for _, ch := range changes {
-		area := primaryArea(ch.Path)
-		if area != "" {
-			counts[area]++
-		}
-	}
-	if len(counts) == 0 {
-		return ""
-	}
-	best := ""
-	bestCount := 0
-	tie := false
-	for area, count := range counts {
-		if count > bestCount {
-			best = area
-			bestCount = count
-			tie = false
-			continue
+func Header(commitType, scope, subject string, format Format, emoji, breaking bool, maxSubject int) string {
+	prefix := ""
+	if format == Conventional || format == Gitmoji {
+		prefix = strings.ToLower(commitType)
+		if scope != "" {
+			prefix += "(" + scope + ")"
 		}
-		if count == bestCount {
-			tie = true
+		if breaking {
+			prefix += "!"
 		}
+		prefix += ": "
 	}
-	if tie {
-		return ""
+	if emoji || format == Gitmoji {
+		if code := EmojiCode(commitType); code != "" {
+			prefix = code + " " + prefix
+		}
 	}
-	return best
+	return prefix + Subject(subject, format, maxSubject)
 }
 
-func verbForType(commitType, lang string) (string, string) {
+func Verb(commitType, lang string) (string, string) {
 	ct := strings.ToLower(commitType)
 	if lang == "ru" {
 		switch ct {
@@ -126,6 +79,8 @@ func verbForType(commitType, lang string) (string, string) {
 			return "Обнови", "сборку"
 		case "ci":
 			return "Обнови", "CI"
+		case "infra":
+			return "Обнови", "инфраструктуру"
 		case "chore":
 			return "Обнови", "инструменты"
 		default:
@@ -152,6 +107,8 @@ func verbForType(commitType, lang string) (string, string) {
 		return "Update", "build"
 	case "ci":
 		return "Update", "CI"
+	case "infra":
+		return "Update", "infrastructure"
 	case "chore":
 		return "Update", "tooling"
 	default:
@@ -159,38 +116,73 @@ func verbForType(commitType, lang string) (string, string) {
 	}
 }
 
-func formatMessage(commitType, scope, subject, body string, opts Options, breaking bool) string {
-	prefix := ""
-	subj := subject
-	if opts.Format == FormatConventional || opts.Format == FormatGitmoji {
-		subj = lowerFirst(subj)
+func SplitFooter(lines []string) ([]string, []string) {
+	start := len(lines)
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) == "" {
+			break
+		}
+		if !trailerPattern.MatchString(lines[i]) && !strings.HasPrefix(lines[i], " ") {
+			start = len(lines)
+			break
+		}
+		start = i
+	}
+	if start < len(lines) && trailerPattern.MatchString(lines[start]) {
+		return lines[:start], lines[start:]
 	}
-	subj = trimSubject(subj, opts.MaxSubject)
+	return lines, nil
+}
 
-	if opts.Format == FormatConventional || opts.Format == FormatGitmoji {
-		prefix = strings.ToLower(commitType)
-		if scope != "" {
-			prefix += "(" + scope + ")"
+func LimitBody(message string, maxLines, maxBytes int, lang string) string {
+	subject, body, ok := strings.Cut(message, "\n")
+	body = strings.Trim(body, "\n")
+	if !ok || body == "" || (maxLines <= 0 && maxBytes <= 0) {
+		return message
+	}
+	lines, footer := SplitFooter(strings.Split(body, "\n"))
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	total := len(lines)
+	marker := func(n int) string {
+		if lang == "ru" {
+			return fmt.Sprintf("… и ещё %d строк", n)
 		}
-		if breaking {
-			prefix += "!"
+		return fmt.Sprintf("… and %d more lines", n)
+	}
+	fits := func(kept []string) bool {
+		if maxLines > 0 && len(kept) > maxLines {
+			return false
 		}
-		prefix += ": "
+		return maxBytes <= 0 || len(strings.Join(kept, "\n")) <= maxBytes
 	}
-	if opts.Emoji || opts.Format == FormatGitmoji {
-		if code := emojiCode(commitType); code != "" {
-			prefix = code + " " + prefix
+	if fits(lines) {
+		return message
+	}
+	join := func(kept []string) string {
+		out := subject
+		if len(kept) > 0 {
+			out += "\n\n" + strings.Join(kept, "\n")
+		}
+		if len(footer) > 0 {
+			out += "\n\n" + strings.Join(footer, "\n")
 		}
+		return out
 	}
-
-	msg := prefix + subj
-	if body != "" {
-		msg += "\n\n" + body
+	for keep := len(lines) - 1; keep >= 0; keep-- {
+		kept := append(append([]string{}, lines[:keep]...), marker(total-keep))
+		for len(kept) > 1 && strings.TrimSpace(kept[len(kept)-2]) == "" {
+			kept = append(kept[:len(kept)-2], kept[len(kept)-1])
+		}
+		if fits(kept) {
+			return join(kept)
+		}
 	}
-	return msg
+	return join(nil)
 }
 
-func emojiCode(commitType string) string {
+func EmojiCode(commitType string) string {
 	switch strings.ToLower(commitType) {
 	case "feat":
 		return ":sparkles:"
@@ -210,6 +202,8 @@ func emojiCode(commitType string) string {
 		return ":package:"
 	case "ci":
 		return ":construction_worker:"
+	case "infra":
+		return ":rocket:"
 	case "chore":
 		return ":wrench:"
 	default:
@@ -217,7 +211,7 @@ func emojiCode(commitType string) string {
 	}
 }
 
-func lowerFirst(s string) string {
+func LowerFirst(s string) string {
 	if s == "" {
 		return s
 	}
@@ -228,7 +222,7 @@ func lowerFirst(s string) string {
 	return strings.ToLower(string(r)) + s[size:]
 }
 
-func trimSubject(s string, max int) string {
+func TrimSubject(s string, max int) string {
 	if max <= 0 {
 		return s
 	}
@@ -250,56 +244,8 @@ func trimSubject(s string, max int) string {
 	return strings.TrimSpace(string(runes[:cut]))
 }
 
-func buildBody(changes []Change, mode Mode, opts Options, breaking bool, breakingNote string) string {
-	bodyMode := opts.Body
-	if bodyMode == BodyAuto {
-		if len(changes) == 0 {
-			bodyMode = BodyNone
-		} else if len(changes) <= opts.MaxItems {
-			bodyMode = BodyFiles
-		} else {
-			bodyMode = BodySummary
-		}
-	}
-
-	var content []string
-	switch bodyMode {
-	case BodyFiles:
-		content = buildFileLines(changes, opts.MaxItems, opts.Lang)
-	case BodyStats:
-		stats, _ := collectNumstat(mode)
-		if len(stats) == 0 {
-			content = []string{summaryLine(changes, opts.Lang)}
-		} else {
-			content = buildStatLines(stats, opts.MaxItems, opts.Lang)
-		}
-	case BodySummary:
-		content = []string{summaryLine(changes, opts.Lang)}
-	}
-
-	var footers []string
-	if breaking {
-		footers = append(footers, breakingFooter(breakingNote, opts.Lang))
-	}
-	if len(opts.Refs) > 0 {
-		footers = append(footers, fmt.Sprintf("Refs: %s", strings.Join(opts.Refs, ", ")))
-	}
-	if len(opts.Closes) > 0 {
-		footers = append(footers, fmt.Sprintf("Closes: %s", strings.Join(opts.Closes, ", ")))
-	}
-
-	lines := content
-	if len(footers) > 0 {
-		if len(content) > 0 {
-			lines = append(lines, "")
-		}
-		lines = append(lines, footers...)
-	}
-	return strings.Join(lines, "\n")
-}
-
-func buildFileLines(changes []Change, maxItems int, lang string) []string {
-	sorted := append([]Change{}, changes...)
+func FileLines(changes []gitinfo.Change, maxItems int, lang string) []string {
+	sorted := append([]gitinfo.Change{}, changes...)
 	sort.Slice(sorted, func(i, j int) bool {
 		return sorted[i].Path < sorted[j].Path
 	})
@@ -314,7 +260,7 @@ func buildFileLines(changes []Change, maxItems int, lang string) []string {
 		if ch.Status == "R" && ch.OldPath != "" {
 			path = ch.OldPath + " -> " + ch.Path
 		}
-		lines = append(lines, fmt.Sprintf("- %s %s", statusLabel(ch.Status, lang), path))
+		lines = append(lines, fmt.Sprintf("- %s %s", StatusLabel(ch.Status, lang), path))
 	}
 	if limit < len(sorted) {
 		remaining := len(sorted) - limit
@@ -327,7 +273,7 @@ func buildFileLines(changes []Change, maxItems int, lang string) []string {
 	return lines
 }
 
-func buildStatLines(stats []FileStat, maxItems int, lang string) []string {
+func StatLines(stats []gitinfo.FileStat, maxItems int, lang string) []string {
 	sort.Slice(stats, func(i, j int) bool {
 		return stats[i].Path < stats[j].Path
 	})
@@ -355,7 +301,7 @@ func buildStatLines(stats []FileStat, maxItems int, lang string) []string {
 	return lines
 }
 
-func summaryLine(changes []Change, lang string) string {
+func SummaryLine(changes []gitinfo.Change, lang string) string {
 	counts := map[string]int{}
 	for _, ch := range changes {
 		counts[ch.Status]++
@@ -370,7 +316,7 @@ func summaryLine(changes []Change, lang string) string {
 	return fmt.Sprintf("Files changed: %d (added %d, removed %d, modified %d)", total, added, deleted, modified)
 }
 
-func statusLabel(status string, lang string) string {
+func StatusLabel(status string, lang string) string {
 	if lang == "ru" {
 		switch status {
 		case "A":
@@ -407,7 +353,7 @@ func statusLabel(status string, lang string) string {
 	}
 }
 
-func breakingFooter(note string, lang string) string {
+func BreakingFooter(note string, lang string) string {
 	if note == "" {
 		if lang == "ru" {
 			note = "несовместимые изменения API"
@@ -418,18 +364,22 @@ func breakingFooter(note string, lang string) string {
 	return "BREAKING CHANGE: " + note
 }
 
-func printExplain(w io.Writer, opts Options, mode Mode, commitType, scope string, breaking bool, llmUsed bool, reasons []string, changes []Change) {
-	fmt.Fprintf(w, "mode: %s (%d files)\n", mode, len(changes))
-	fmt.Fprintf(w, "type: %s\n", commitType)
-	if len(reasons) > 0 {
-		fmt.Fprintf(w, "reasons: %s\n", strings.Join(reasons, "; "))
-	}
-	if scope != "" {
-		fmt.Fprintf(w, "scope: %s\n", scope)
-	}
-	fmt.Fprintf(w, "breaking: %v\n", breaking)
-	fmt.Fprintf(w, "llm: %v\n", llmUsed)
-	fmt.Fprintf(w, "format: %s\n", opts.Format)
-	fmt.Fprintf(w, "body: %s\n", opts.Body)
-	fmt.Fprintf(w, "lang: %s\n", opts.Lang)
+const (
+	SourceHeuristic = "heuristic"
+	SourceLLM       = "llm"
+)
+
+type Message struct {
+	Text     string   `json:"message"`
+	Subject  string   `json:"subject"`
+	Body     string   `json:"body,omitempty"`
+	Type     string   `json:"type,omitempty"`
+	Scope    string   `json:"scope,omitempty"`
+	Footers  []string `json:"footers,omitempty"`
+	Breaking bool     `json:"breaking"`
+	Source   string   `json:"source"`
+}
+
+func (m Message) String() string {
+	return m.Text
 }